@@ -0,0 +1,68 @@
+package reqctl
+
+import (
+	"context"
+	"net/http"
+)
+
+// Future is the pending outcome of a request executed in the background
+type Future struct {
+	done   chan struct{}
+	res    Result
+	cancel context.CancelFunc
+}
+
+// DoAsync executes the request in the background like DoResult, returning at once. The outcome is collected
+// from the future, whose response body must be closed like any other.
+func (c Controller) DoAsync() *Future {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &Future{done: make(chan struct{}), cancel: cancel}
+	go func() {
+		defer close(f.done)
+		defer cancel()
+		f.res = runBound(ctx, c)
+	}()
+	return f
+}
+
+// DoAsync executes the request in the background as per the policy, refer Controller.DoAsync
+func (p Policy) DoAsync(ctx context.Context, req *http.Request) *Future {
+	return p.Request(ctx, req).DoAsync()
+}
+
+// DoAsync executes the request in the background as per the client policy, refer Controller.DoAsync
+func (c *Client) DoAsync(ctx context.Context, req *http.Request) *Future {
+	return c.policy.DoAsync(ctx, req)
+}
+
+// Done returns a channel closed once the outcome of the request is available
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the outcome of the request is available, or until ctx is done, returning its error then.
+// The request keeps running when ctx is done, refer Future.Cancel to abort it.
+func (f *Future) Wait(ctx context.Context) (Result, error) {
+	select {
+	case <-f.done:
+		return f.res, nil
+	case <-ctx.Done():
+		return Result{}, ctx.Err()
+	}
+}
+
+// Result returns the outcome of the request, reporting whether it is available yet
+func (f *Future) Result() (Result, bool) {
+	select {
+	case <-f.done:
+		return f.res, true
+	default:
+		return Result{}, false
+	}
+}
+
+// Cancel aborts the request if still in flight, its outcome failing with context.Canceled.
+// The body of an obtained response stays readable.
+func (f *Future) Cancel() {
+	f.cancel()
+}
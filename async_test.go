@@ -0,0 +1,66 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestDoAsync(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_, _ = w.Write([]byte("done"))
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	future := reqctl.Request(context.Background(), req).DoAsync()
+	if _, ok := future.Result(); ok {
+		t.Fatalf("Expected the result to be pending")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := future.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the wait to stop with its context, got %v", err)
+	}
+
+	close(release)
+	res, err := future.Wait(context.Background())
+	if err != nil || res.Err != nil {
+		t.Fatalf("Expected the result, got %v, %v", err, res.Err)
+	}
+	body, _ := io.ReadAll(res.Response.Body)
+	res.Response.Body.Close()
+	if string(body) != "done" {
+		t.Errorf("Expected the body to be readable, got %q", body)
+	}
+	select {
+	case <-future.Done():
+	default:
+		t.Errorf("Expected the future to be done")
+	}
+}
+
+func TestDoAsyncCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	future := reqctl.NewPolicy().DoAsync(context.Background(), req)
+	future.Cancel()
+
+	res, _ := future.Wait(context.Background())
+	if !errors.Is(res.Err, context.Canceled) {
+		t.Errorf("Expected the cancelled request to fail, got %v", res.Err)
+	}
+}
@@ -0,0 +1,116 @@
+package reqctl
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Attempt describes a single execution of a logical request
+type Attempt struct {
+	// CorrelationID is shared by every attempt ( retried or parallel ) of the same logical request
+	CorrelationID string
+	// Seq is the 1 based sequence number of the attempt within the logical request
+	Seq int
+	// Reason is why the previous attempt was retried, empty for attempts which are not retries
+	Reason RetryReason
+	// Hedged reports whether the attempt belongs to a parallel call fired after the first one
+	Hedged bool
+	// Sampled reports whether verbose observability data is recorded for the logical request
+	Sampled bool
+}
+
+// Retried reports whether the attempt retries a previous attempt
+func (a Attempt) Retried() bool {
+	return a.Reason != ReasonNone
+}
+
+// attemptCtxKey is the context key under which the current attempt is stored
+type attemptCtxKey struct{}
+
+// AttemptFromContext returns the attempt metadata attached to the request context by the controller
+func AttemptFromContext(ctx context.Context) (Attempt, bool) {
+	attempt, ok := ctx.Value(attemptCtxKey{}).(Attempt)
+	return attempt, ok
+}
+
+// CorrelationIDFromContext returns the correlation ID of the logical request, or empty if absent
+func CorrelationIDFromContext(ctx context.Context) string {
+	attempt, _ := AttemptFromContext(ctx)
+	return attempt.CorrelationID
+}
+
+// withAttempt attaches the attempt metadata to the context
+func withAttempt(ctx context.Context, attempt Attempt) context.Context {
+	return context.WithValue(ctx, attemptCtxKey{}, attempt)
+}
+
+// execution holds the state shared by all attempts of a single logical request
+type execution struct {
+	correlationID string
+	sampled       bool
+	addrs         []string
+	endpoints     []Endpoint
+	resolvedHost  string
+	start         time.Time
+	seq           int32
+
+	mu      sync.Mutex
+	records []AttemptRecord
+}
+
+// newExecution creates the state for a new logical request
+func newExecution(sampled bool, start time.Time) *execution {
+	return &execution{
+		correlationID: newUUID(),
+		sampled:       sampled,
+		start:         start,
+	}
+}
+
+// nextAttempt reserves the next attempt sequence number, safe for use across parallel calls
+func (e *execution) nextAttempt(reason RetryReason, hedged bool) Attempt {
+	return Attempt{
+		CorrelationID: e.correlationID,
+		Seq:           int(atomic.AddInt32(&e.seq, 1)),
+		Reason:        reason,
+		Hedged:        hedged,
+		Sampled:       e.sampled,
+	}
+}
+
+// record registers a completed attempt, safe for use across parallel calls
+func (e *execution) record(rec AttemptRecord) {
+	e.mu.Lock()
+	e.records = append(e.records, rec)
+	e.mu.Unlock()
+}
+
+// attemptRecords returns the registered attempts in the order of their sequence number
+func (e *execution) attemptRecords() []AttemptRecord {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	records := make([]AttemptRecord, len(e.records))
+	copy(records, e.records)
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Seq < records[j].Seq
+	})
+	return records
+}
+
+// newUUID generates a random version 4 UUID
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("reqctl: unable to generate uuid: %v", err))
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
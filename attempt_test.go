@@ -0,0 +1,148 @@
+package reqctl_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestCorrelationHeaders(t *testing.T) {
+	var mu sync.Mutex
+	var ids, seqs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ids = append(ids, r.Header.Get("X-Correlation-ID"))
+		seqs = append(seqs, r.Header.Get("X-Attempt"))
+		mu.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	checker := func(resp *http.Response, err error) bool {
+		return err != nil || resp.StatusCode >= 500
+	}
+
+	_, err = reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(time.Millisecond, 2, checker).
+		SetCorrelationHeaders("X-Correlation-ID", "X-Attempt").
+		Do()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+
+	if len(ids) != 3 {
+		t.Errorf("Expected 3 attempts, got %d", len(ids))
+		return
+	}
+
+	for i := range ids {
+		if ids[i] == "" || ids[i] != ids[0] {
+			t.Errorf("Expected same correlation ID on every attempt, got %v", ids)
+		}
+	}
+
+	if seqs[0] != "1" || seqs[1] != "2" || seqs[2] != "3" {
+		t.Errorf("Expected attempt sequence 1, 2, 3, got %v", seqs)
+	}
+}
+
+func TestAttemptFromContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	var attempt reqctl.Attempt
+	var found bool
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			attempt, found = reqctl.AttemptFromContext(r.Context())
+			return http.DefaultTransport.RoundTrip(r)
+		}),
+	}
+
+	ctlr := reqctl.Request(context.Background(), request)
+	if _, err = ctlr.DoWithClient(client); err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+
+	if !found || attempt.CorrelationID == "" || attempt.Seq != 1 {
+		t.Errorf("Expected first attempt with correlation ID, got %+v", attempt)
+	}
+}
+
+func TestAttemptKinds(t *testing.T) {
+	var mu sync.Mutex
+	var attempts []reqctl.Attempt
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			attempt, _ := reqctl.AttemptFromContext(r.Context())
+			mu.Lock()
+			attempts = append(attempts, attempt)
+			mu.Unlock()
+
+			if !attempt.Hedged && !attempt.Retried() {
+				time.Sleep(50 * time.Millisecond)
+				return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+			}
+			return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	resp, err := reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(0, 1, reqctl.RetryOnStatus(503)).
+		SetParallelCallWithDelay(10 * time.Millisecond).
+		SetClient(client).
+		Do()
+	if err != nil {
+		t.Fatalf("Obtained error: %v", err)
+	}
+	resp.Body.Close()
+
+	// Wait for the losing call to complete
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	kinds := map[string]int{}
+	for _, attempt := range attempts {
+		switch {
+		case attempt.Hedged:
+			kinds["hedged"]++
+		case attempt.Retried():
+			kinds["retried"]++
+		default:
+			kinds["first"]++
+		}
+	}
+	if kinds["first"] != 1 || kinds["hedged"] != 1 {
+		t.Errorf("Expected a first & a hedged attempt, got %v", kinds)
+	}
+}
+
+// roundTripFunc adapts a function into an http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
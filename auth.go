@@ -0,0 +1,141 @@
+package reqctl
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AuthToken is an auth artifact, eg: OAuth token, signed URL or CSRF token
+type AuthToken struct {
+	Value string
+	// Expiry after which the token is fetched again, zero never expires
+	Expiry time.Time
+}
+
+// valid reports whether the token can still be used
+func (t *AuthToken) valid() bool {
+	return t != nil && (t.Expiry.IsZero() || time.Now().Before(t.Expiry))
+}
+
+// Authenticator caches an auth artifact across attempts & controllers sharing it, so that retries reuse it
+// instead of repeating the auth handshake. The artifact is invalidated when an attempt receives 401 or 403.
+type Authenticator struct {
+	fetch func(ctx context.Context) (AuthToken, error)
+	apply func(req *http.Request, token AuthToken)
+
+	mu       sync.Mutex
+	token    *AuthToken
+	fetching *tokenFetch
+}
+
+// tokenFetch is a fetch of the artifact in flight, shared by the callers waiting for it
+type tokenFetch struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	token   AuthToken
+	err     error
+}
+
+// NewAuthenticator creates an authenticator, which obtains the artifact via fetch & applies it to every attempt
+func NewAuthenticator(fetch func(ctx context.Context) (AuthToken, error), apply func(req *http.Request, token AuthToken)) *Authenticator {
+	return &Authenticator{
+		fetch: fetch,
+		apply: apply,
+	}
+}
+
+// BearerAuthenticator creates an authenticator setting the fetched token as the bearer Authorization header
+func BearerAuthenticator(fetch func(ctx context.Context) (AuthToken, error)) *Authenticator {
+	return NewAuthenticator(fetch, func(req *http.Request, token AuthToken) {
+		req.Header.Set("Authorization", "Bearer "+token.Value)
+	})
+}
+
+// Token returns the cached artifact, fetching it if absent or expired. Concurrent callers share a single fetch,
+// each waiting for it until its own context is done. The fetch is detached from the cancellation of the caller
+// starting it, & cancelled once every caller waiting for it gave up.
+func (a *Authenticator) Token(ctx context.Context) (AuthToken, error) {
+	a.mu.Lock()
+	if a.token.valid() {
+		token := *a.token
+		a.mu.Unlock()
+		return token, nil
+	}
+
+	f := a.fetching
+	if f == nil {
+		fCtx, cancel := context.WithCancel(detachedContext{ctx})
+		f = &tokenFetch{done: make(chan struct{}), cancel: cancel}
+		a.fetching = f
+		go a.run(fCtx, f)
+	}
+	f.waiters++
+	a.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.token, f.err
+	case <-ctx.Done():
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if f.waiters--; f.waiters == 0 && a.fetching == f {
+		// The callers arriving afterwards start a fetch of their own
+		a.fetching = nil
+		f.cancel()
+	}
+	return AuthToken{}, ctx.Err()
+}
+
+// run fetches the artifact, caching it on success & publishing the outcome to the waiting callers
+func (a *Authenticator) run(ctx context.Context, f *tokenFetch) {
+	token, err := a.fetch(ctx)
+	f.cancel()
+
+	a.mu.Lock()
+	if a.fetching == f {
+		a.fetching = nil
+	}
+	if err == nil {
+		a.token = &token
+	}
+	f.token, f.err = token, err
+	a.mu.Unlock()
+	close(f.done)
+}
+
+// Invalidate drops the cached artifact if it is still the given one, so that the next attempt fetches a new one
+func (a *Authenticator) Invalidate(token AuthToken) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != nil && a.token.Value == token.Value {
+		a.token = nil
+	}
+}
+
+// authenticate applies the artifact to the attempt, returning a function invalidating it on rejection
+func (a *Authenticator) authenticate(req *http.Request) (func(*http.Response), error) {
+	token, err := a.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	a.apply(req, token)
+	return func(resp *http.Response) {
+		if resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+			a.Invalidate(token)
+		}
+	}, nil
+}
+
+// SetAuthenticator applies the cached auth artifact to every attempt. Include 401 in the retry checker
+// to retry rejected attempts with a freshly fetched artifact.
+func (c Controller) SetAuthenticator(auth *Authenticator) Controller {
+	c.config.auth = auth
+	return c
+}
@@ -0,0 +1,110 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestAuthenticator(t *testing.T) {
+	valid := "token-1"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	fetches := 0
+	auth := reqctl.BearerAuthenticator(func(ctx context.Context) (reqctl.AuthToken, error) {
+		fetches++
+		return reqctl.AuthToken{Value: fmt.Sprintf("token-%d", fetches)}, nil
+	})
+
+	checker := func(resp *http.Response, err error) bool {
+		return err != nil || resp.StatusCode == http.StatusUnauthorized
+	}
+
+	policy := reqctl.NewPolicy().
+		WithSimpleRetryWithChecker(time.Millisecond, 1, checker).
+		WithAuthenticator(auth)
+
+	do := func() int {
+		request, _ := http.NewRequest("GET", server.URL, nil)
+		resp, err := policy.Do(context.Background(), request)
+		if err != nil {
+			t.Errorf("Obtained error: %v", err)
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// The token is fetched once & reused across requests
+	if do() != 200 || do() != 200 || fetches != 1 {
+		t.Errorf("Expected the token to be fetched once, got %d fetches", fetches)
+	}
+
+	// The rejected token is invalidated, & the retry fetches a new one
+	valid = "token-2"
+	if status := do(); status != 200 || fetches != 2 {
+		t.Errorf("Expected retry with a fresh token, got status %d after %d fetches", status, fetches)
+	}
+}
+
+func TestAuthenticatorConcurrentFetch(t *testing.T) {
+	release := make(chan struct{})
+	var fetches int32
+	auth := reqctl.BearerAuthenticator(func(ctx context.Context) (reqctl.AuthToken, error) {
+		atomic.AddInt32(&fetches, 1)
+		select {
+		case <-release:
+			return reqctl.AuthToken{Value: "token"}, nil
+		case <-ctx.Done():
+			return reqctl.AuthToken{}, ctx.Err()
+		}
+	})
+
+	// The caller starting the fetch gives up, the fetch still serves the other caller
+	first, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := auth.Token(first)
+		firstErr <- err
+	}()
+	for atomic.LoadInt32(&fetches) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	second := make(chan reqctl.AuthToken, 1)
+	go func() {
+		token, _ := auth.Token(context.Background())
+		second <- token
+	}()
+
+	// A caller whose context is done stops waiting without waiting for the fetch
+	short, cancelShort := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelShort()
+	if _, err := auth.Token(short); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded while the fetch is in flight, got %v", err)
+	}
+
+	cancel()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled for the caller giving up, got %v", err)
+	}
+
+	close(release)
+	if token := <-second; token.Value != "token" || atomic.LoadInt32(&fetches) != 1 {
+		t.Errorf("Expected the single fetch to serve the waiting caller, got %q after %d fetches", token.Value, fetches)
+	}
+}
@@ -0,0 +1,99 @@
+package reqctl
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Jitter defines how the backoff waits are randomized, so that clients retrying a failing upstream
+// do not synchronize into thundering herds.
+type Jitter string
+
+const (
+	// NoJitter waits exactly the computed backoff ( default )
+	NoJitter = Jitter("none")
+	// FullJitter waits a random duration between 0 & the computed backoff
+	FullJitter = Jitter("full")
+	// EqualJitter waits half the computed backoff plus a random duration up to the other half
+	EqualJitter = Jitter("equal")
+	// DecorrelatedJitter waits a random duration between the base interval & thrice the previous wait
+	DecorrelatedJitter = Jitter("decorrelated")
+)
+
+// backoff computes the wait before the retry i ( 0 based ), given the previous wait
+func (c *Controller) backoff(i int, prev time.Duration) time.Duration {
+	retryCfg := c.config.retryCfg
+
+	var wait time.Duration
+	if retryCfg.RetryType == simpleRetry {
+		wait = retryCfg.RetryInterval
+	} else if retryCfg.RetryType == exponentialRetry {
+		// Any interval doubled 63 times is beyond the range of a duration
+		if i > 63 {
+			i = 63
+		}
+		wait = c.clampBackoff(float64(retryCfg.RetryInterval) * math.Exp2(float64(i)))
+	}
+
+	switch c.config.jitter {
+	case FullJitter:
+		wait = randDuration(0, wait)
+	case EqualJitter:
+		wait = wait/2 + randDuration(0, wait-wait/2)
+	case DecorrelatedJitter:
+		if prev < retryCfg.RetryInterval {
+			prev = retryCfg.RetryInterval
+		}
+		// Both bounds are capped, as the max backoff may be below the base interval
+		wait = randDuration(c.clampBackoff(float64(retryCfg.RetryInterval)), c.clampBackoff(3*float64(prev)))
+	}
+
+	return wait
+}
+
+// clampBackoff converts the computed wait into a duration bounded by the max backoff, avoiding overflows
+func (c *Controller) clampBackoff(wait float64) time.Duration {
+	if max := c.config.maxBackoff; max > 0 && wait > float64(max) {
+		return max
+	}
+	if wait >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(wait)
+}
+
+// SetMaxBackoff caps the computed backoff wait, which otherwise grows unbounded with exponential retry
+func (c Controller) SetMaxBackoff(max time.Duration) Controller {
+	c.config.maxBackoff = max
+	return c
+}
+
+// randDuration returns a random duration within [min, max]
+func randDuration(min, max time.Duration) time.Duration {
+	if max <= min {
+		return min
+	}
+	// The span of the whole range of durations has no room for the upper bound itself
+	span := int64(max - min)
+	if span == math.MaxInt64 {
+		return min + time.Duration(rand.Int63())
+	}
+	return min + time.Duration(rand.Int63n(span+1))
+}
+
+// SetJitter randomizes the backoff waits of the configured retry strategy
+func (c Controller) SetJitter(jitter Jitter) Controller {
+	c.config.jitter = jitter
+	return c
+}
+
+// SetSimpleRetryWithJitter configures simple retry with default checker & randomized waits
+func (c Controller) SetSimpleRetryWithJitter(interval time.Duration, times int, jitter Jitter) Controller {
+	return c.SetSimpleRetry(interval, times).SetJitter(jitter)
+}
+
+// SetExponentialRetryWithJitter configures exponential retry with default checker & randomized waits
+func (c Controller) SetExponentialRetryWithJitter(interval time.Duration, times int, jitter Jitter) Controller {
+	return c.SetExponentialRetry(interval, times).SetJitter(jitter)
+}
@@ -0,0 +1,153 @@
+package reqctl_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+// attemptGaps executes the controller against a failing transport, returning the waits between attempts
+func attemptGaps(t *testing.T, ctlr reqctl.Controller) []time.Duration {
+	var times []time.Time
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			times = append(times, time.Now())
+			return nil, errors.New("connection refused")
+		}),
+	}
+
+	if _, err := ctlr.SetClient(client).Do(); err == nil {
+		t.Errorf("Request should have failed")
+	}
+
+	gaps := make([]time.Duration, 0, len(times))
+	for i := 1; i < len(times); i++ {
+		gaps = append(gaps, times[i].Sub(times[i-1]))
+	}
+	return gaps
+}
+
+func TestEqualJitter(t *testing.T) {
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	gaps := attemptGaps(t, reqctl.Request(request.Context(), request).
+		SetExponentialRetryWithJitter(10*time.Millisecond, 3, reqctl.EqualJitter))
+
+	if len(gaps) != 3 {
+		t.Errorf("Expected 3 retries, got %d", len(gaps))
+		return
+	}
+
+	// Equal jitter waits at least half of the exponential backoff
+	for i, gap := range gaps {
+		if min := 5 * time.Millisecond << i; gap < min {
+			t.Errorf("Expected wait %d to be at least %v, got %v", i, min, gap)
+		}
+	}
+}
+
+func TestFullJitter(t *testing.T) {
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	gaps := attemptGaps(t, reqctl.Request(request.Context(), request).
+		SetSimpleRetryWithJitter(20*time.Millisecond, 8, reqctl.FullJitter))
+
+	// Full jitter spreads the waits below the interval, hence they cannot all be the full interval
+	short := 0
+	for _, gap := range gaps {
+		if gap < 15*time.Millisecond {
+			short++
+		}
+	}
+
+	if len(gaps) != 8 || short == 0 {
+		t.Errorf("Expected randomized waits for 8 retries, got %v", gaps)
+	}
+}
+
+func TestMaxBackoff(t *testing.T) {
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	start := time.Now()
+	gaps := attemptGaps(t, reqctl.Request(request.Context(), request).
+		SetExponentialRetry(10*time.Millisecond, 5).
+		SetMaxBackoff(20*time.Millisecond))
+
+	// Uncapped the waits would total 310ms
+	if len(gaps) != 5 || time.Since(start) > 200*time.Millisecond {
+		t.Errorf("Expected 5 capped waits, got %v", gaps)
+	}
+
+	for i, gap := range gaps[1:] {
+		if gap < 20*time.Millisecond {
+			t.Errorf("Expected wait %d to be capped at 20ms, got %v", i+1, gap)
+		}
+	}
+}
+
+func TestMaxElapsedTime(t *testing.T) {
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	start := time.Now()
+	gaps := attemptGaps(t, reqctl.Request(request.Context(), request).
+		SetSimpleRetry(20*time.Millisecond, 100).
+		SetMaxElapsedTime(70*time.Millisecond))
+
+	if len(gaps) < 2 || len(gaps) > 3 || time.Since(start) > 100*time.Millisecond {
+		t.Errorf("Expected at most 3 retries within the 70ms budget, got %v", gaps)
+	}
+}
+
+func TestUncappedBackoff(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		}),
+	}
+
+	// Doubled past the range of a duration, the waits saturate instead of overflowing
+	for _, jitter := range []reqctl.Jitter{reqctl.NoJitter, reqctl.FullJitter, reqctl.EqualJitter, reqctl.DecorrelatedJitter} {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		request, _ := http.NewRequest("GET", "http://localhost", nil)
+		reqctl.Request(request.Context(), request).
+			SetExponentialRetryWithJitter(time.Second, 70, jitter).
+			SetClock(clock).
+			SetClient(client).
+			DoResult()
+
+		if len(clock.slept) != 70 {
+			t.Errorf("Expected 70 waits with %s jitter, got %d", jitter, len(clock.slept))
+		}
+		for i, wait := range clock.slept {
+			if wait < 0 {
+				t.Errorf("Expected wait %d with %s jitter to be positive, got %v", i, jitter, wait)
+			}
+		}
+	}
+}
+
+func TestDecorrelatedJitterMaxBackoff(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		}),
+	}
+
+	// The max backoff below the base interval caps the waits as well
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	reqctl.Request(request.Context(), request).
+		SetSimpleRetryWithJitter(time.Second, 5, reqctl.DecorrelatedJitter).
+		SetMaxBackoff(100 * time.Millisecond).
+		SetClock(clock).
+		SetClient(client).
+		DoResult()
+
+	if len(clock.slept) != 5 {
+		t.Fatalf("Expected 5 waits, got %d", len(clock.slept))
+	}
+	for i, wait := range clock.slept {
+		if wait > 100*time.Millisecond {
+			t.Errorf("Expected wait %d to be capped at 100ms, got %v", i, wait)
+		}
+	}
+}
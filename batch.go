@@ -0,0 +1,116 @@
+package reqctl
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// runBound executes the controller, cancelling it along with ctx only while in flight so that the body of its
+// response stays readable
+func runBound(ctx context.Context, c Controller) Result {
+	ctx0 := c.ctx
+	bCtx, cancel := context.WithCancel(ctx0)
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancel()
+		case <-stop:
+		}
+	}()
+
+	c.ctx = bCtx
+	res := c.run(c.client())
+	close(stop)
+
+	if res.Err != nil {
+		cancel()
+	} else {
+		res.Response = withCancel(res.Response, cancel)
+	}
+	if res.ctrl != nil {
+		// Re-runs of the result, eg: on body failures, are no longer bound to ctx
+		unbound := *res.ctrl
+		unbound.ctx = ctx0
+		res.ctrl = &unbound
+	}
+	return res
+}
+
+// DoAll executes the controllers with at most maxConcurrent at once, unbounded if not positive, returning their
+// results in the order of the controllers. Each controller follows its own policy, the controllers in flight being
+// cancelled along with ctx & the ones not started failing with its error. Refer BatchErr to aggregate the errors.
+func DoAll(ctx context.Context, ctrls []*Controller, maxConcurrent int) []Result {
+	results := make([]Result, len(ctrls))
+	workers := maxConcurrent
+	if workers <= 0 || workers > len(ctrls) {
+		workers = len(ctrls)
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if err := ctx.Err(); err != nil {
+					results[i] = Result{Err: err}
+				} else {
+					results[i] = runBound(ctx, *ctrls[i])
+				}
+			}
+		}()
+	}
+
+	for i := range ctrls {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// BatchFailure is a failed request of a batch
+type BatchFailure struct {
+	// Index of the controller in the batch
+	Index int
+	Err   error
+}
+
+// BatchError aggregates the errors of the failed requests of a batch
+type BatchError struct {
+	// Failures are ordered by their index
+	Failures []BatchFailure
+}
+
+// Error summarizes the failures, detailing the first one
+func (e *BatchError) Error() string {
+	first := e.Failures[0]
+	return fmt.Sprintf("reqctl: %d requests failed, request %d: %v", len(e.Failures), first.Index, first.Err)
+}
+
+// Unwrap returns the errors of the failures
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		errs = append(errs, f.Err)
+	}
+	return errs
+}
+
+// BatchErr returns a *BatchError aggregating the errors of the results, nil if every request succeeded
+func BatchErr(results []Result) error {
+	var failures []BatchFailure
+	for i, res := range results {
+		if res.Err != nil {
+			failures = append(failures, BatchFailure{Index: i, Err: res.Err})
+		}
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+	return &BatchError{Failures: failures}
+}
@@ -0,0 +1,85 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestDoAll(t *testing.T) {
+	var inFlight, peak int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&inFlight, 1)
+		defer atomic.AddInt64(&inFlight, -1)
+		for {
+			p := atomic.LoadInt64(&peak)
+			if n <= p || atomic.CompareAndSwapInt64(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	ctrls := make([]*reqctl.Controller, 0, 8)
+	for i := 0; i < 8; i++ {
+		path := "/" + strconv.Itoa(i)
+		if i == 5 {
+			path = "/fail"
+		}
+		req, _ := http.NewRequest("GET", server.URL+path, nil)
+		c := reqctl.Request(context.Background(), req).
+			SetSimpleRetryWithChecker(time.Millisecond, 1, reqctl.RetryOnStatus(503)).
+			OnExhausted(reqctl.ReturnError)
+		ctrls = append(ctrls, &c)
+	}
+
+	results := reqctl.DoAll(context.Background(), ctrls, 3)
+	for i, res := range results {
+		if i == 5 {
+			if !errors.Is(res.Err, reqctl.ErrRetriesExhausted) || res.AttemptCount != 2 {
+				t.Errorf("Expected the failing request to be retried, got %d attempts, %v", res.AttemptCount, res.Err)
+			}
+			continue
+		}
+		if body, err := res.String(); err != nil || body != "/"+strconv.Itoa(i) {
+			t.Errorf("Expected result %d in order, got %q, %v", i, body, err)
+		}
+	}
+	if peak > 3 {
+		t.Errorf("Expected at most 3 requests in flight, got %d", peak)
+	}
+
+	var batchErr *reqctl.BatchError
+	if err := reqctl.BatchErr(results); !errors.As(err, &batchErr) || len(batchErr.Failures) != 1 ||
+		batchErr.Failures[0].Index != 5 {
+		t.Errorf("Expected the failure of request 5 to be reported, got %v", err)
+	}
+}
+
+func TestDoAllCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, _ := http.NewRequest("GET", "http://localhost", nil)
+	results := reqctl.DoAll(ctx, []*reqctl.Controller{reqctl.Request(context.Background(), req)}, 0)
+	if len(results) != 1 || !errors.Is(results[0].Err, context.Canceled) {
+		t.Errorf("Expected the request not to start, got %v", results)
+	}
+	if reqctl.BatchErr(nil) != nil {
+		t.Errorf("Expected no error for an empty batch")
+	}
+}
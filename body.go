@@ -0,0 +1,49 @@
+package reqctl
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// cancelBody releases the attempt context once the response body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the underlying body & releases the attempt context
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// maxDrainBytes bounds the bytes read from a discarded body so that its connection is reused,
+// larger bodies are closed right away as reading them costs more than a new connection
+const maxDrainBytes = 64 << 10
+
+// closeBody drains & closes the body of a discarded response, returning its connection to the pool
+func closeBody(resp *http.Response) {
+	if resp != nil && resp.Body != nil {
+		_, _ = io.CopyN(io.Discard, resp.Body, maxDrainBytes)
+		_ = resp.Body.Close()
+	}
+}
+
+// withCancel ties the release of the context to the closing of the response body
+func withCancel(resp *http.Response, cancel context.CancelFunc) *http.Response {
+	if resp == nil || resp.Body == nil {
+		cancel()
+		return resp
+	}
+
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp
+}
+
+// teeBody copies the body to a writer as it is read
+type teeBody struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,59 @@
+package reqctl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// SetBodyRetry re-runs the request up to times when reading or decoding the body of its response fails,
+// eg: the connection is reset mid body or the JSON is invalid. It applies to the bodies consumed via DoJSON &
+// the Result helpers, each run following the retry configuration & waiting its backoff after a failed body.
+// Rejected statuses & bodies exceeding the size limit are not re-run, nor are requests without a replayable body.
+func (c Controller) SetBodyRetry(times int) Controller {
+	c.config.bodyRetries = times
+	return c
+}
+
+// isBodyFailure reports whether the error of consuming a body may be fixed by re-running the request
+func isBodyFailure(err error) bool {
+	var statusErr *StatusError
+	return !errors.As(err, &statusErr) && !errors.Is(err, ErrResponseTooLarge) &&
+		!errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// consume reads the response of the result, re-running the request on body failures as configured.
+// A body failure calling for a renegotiation, refer SetRenegotiation, re-runs the renegotiated request as per the
+// retry strategy instead. It returns the response whose body was read last.
+func (r Result) consume(read func(*http.Response) error) (*http.Response, error) {
+	var wait time.Duration
+	for runs, retries, renegotiations := 0, 0, 0; ; runs++ {
+		if err := r.settled(); err != nil {
+			return r.Response, err
+		}
+
+		err := read(r.Response)
+		c := r.ctrl
+		if err == nil || c == nil || !isReplayable(c.req) {
+			return r.Response, err
+		}
+
+		next, renegotiated := c.renegotiate(r.Response, err)
+		switch {
+		case renegotiated && renegotiations < c.config.retryCfg.MaxCount:
+			renegotiations++
+		case retries < c.config.bodyRetries && isBodyFailure(err):
+			next = c
+			retries++
+		default:
+			return r.Response, err
+		}
+
+		wait = c.backoff(runs, wait)
+		if err := c.clock().Sleep(c.ctx, wait); err != nil {
+			return r.Response, err
+		}
+		r = next.run(r.client)
+	}
+}
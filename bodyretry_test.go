@@ -0,0 +1,72 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestBodyRetry(t *testing.T) {
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt64(&calls, 1) {
+		case 1:
+			// The connection is closed before the announced body is complete
+			w.Header().Set("Content-Length", "100")
+			_, _ = w.Write([]byte(`{"id":`))
+		case 2:
+			_, _ = w.Write([]byte(`{"id": invalid`))
+		default:
+			_, _ = w.Write([]byte(`{"id": 7}`))
+		}
+	}))
+	defer server.Close()
+
+	type item struct {
+		ID int `json:"id"`
+	}
+	request := func(retries int) *reqctl.Controller {
+		atomic.StoreInt64(&calls, 0)
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		c := reqctl.Request(context.Background(), req).SetBodyRetry(retries)
+		return &c
+	}
+
+	got, resp, err := reqctl.DoJSON[item](request(2))
+	if err != nil || got.ID != 7 || resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the 3rd run to decode, got %+v, %v", got, err)
+	}
+	if n := atomic.LoadInt64(&calls); n != 3 {
+		t.Errorf("Expected 3 runs, got %d", n)
+	}
+
+	if _, _, err := reqctl.DoJSON[item](request(1)); err == nil {
+		t.Errorf("Expected the invalid body of the last run to fail")
+	}
+
+	var v item
+	if err := request(2).DoResult().DecodeJSON(&v); err != nil || v.ID != 7 {
+		t.Errorf("Expected the result helper to re-run, got %+v, %v", v, err)
+	}
+}
+
+func TestBodyRetrySkipsStatusErrors(t *testing.T) {
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	var statusErr *reqctl.StatusError
+	err := reqctl.Request(context.Background(), req).SetBodyRetry(3).DoResult().DecodeJSON(&struct{}{})
+	if !errors.As(err, &statusErr) || atomic.LoadInt64(&calls) != 1 {
+		t.Errorf("Expected a single run failing with the status, got %d runs, %v", calls, err)
+	}
+}
@@ -0,0 +1,122 @@
+package reqctl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending the request, when the circuit breaker is open
+var ErrCircuitOpen = errors.New("reqctl: circuit breaker is open")
+
+// BreakerState defines the state of a circuit breaker
+type BreakerState string
+
+const (
+	// BreakerClosed lets the requests through
+	BreakerClosed = BreakerState("closed")
+	// BreakerOpen rejects the requests until the cooldown elapses
+	BreakerOpen = BreakerState("open")
+)
+
+// CircuitBreaker stops sending requests to an upstream once too many attempts fail within a window.
+// Its state lives in a StateStore, so breakers with the same name share state across controllers & processes.
+// Store errors never block requests, the breaker fails open.
+type CircuitBreaker struct {
+	name      string
+	threshold int64
+	window    time.Duration
+	cooldown  time.Duration
+	store     StateStore
+}
+
+// NewCircuitBreaker creates a breaker which opens for cooldown, once threshold attempts fail within window.
+// The breaker uses the process wide in-memory store, use WithStore to share it across processes.
+func NewCircuitBreaker(name string, threshold int, window, cooldown time.Duration) *CircuitBreaker {
+	b := &CircuitBreaker{
+		name:      name,
+		threshold: int64(threshold),
+		window:    window,
+		cooldown:  cooldown,
+		store:     defaultStore,
+	}
+	registerBreaker(b)
+	return b
+}
+
+// WithStore returns a copy of the breaker backed by the given store
+func (b *CircuitBreaker) WithStore(store StateStore) *CircuitBreaker {
+	res := *b
+	res.store = store
+	registerBreaker(&res)
+	return &res
+}
+
+// Name returns the name of the breaker
+func (b *CircuitBreaker) Name() string {
+	return b.name
+}
+
+// State returns the current state of the breaker
+func (b *CircuitBreaker) State(ctx context.Context) BreakerState {
+	if open, err := b.store.Get(ctx, b.key("open")); err == nil && open > 0 {
+		return BreakerOpen
+	}
+	return BreakerClosed
+}
+
+// Allow returns ErrCircuitOpen if the breaker is open
+func (b *CircuitBreaker) Allow(ctx context.Context) error {
+	if b.State(ctx) == BreakerOpen {
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// Record registers the outcome of an attempt, opening the breaker when failures reach the threshold.
+// Failures are counted per window from the first one, successes in between not resetting the count.
+func (b *CircuitBreaker) Record(ctx context.Context, failed bool) {
+	if !failed {
+		return
+	}
+
+	failures, err := b.store.Add(ctx, b.key("failures"), 1, b.window)
+	if err != nil || failures < b.threshold {
+		return
+	}
+
+	_ = b.store.Set(ctx, b.key("open"), 1, b.cooldown)
+	_ = b.store.Set(ctx, b.key("failures"), 0, b.window)
+}
+
+// key returns the store key of the breaker counter
+func (b *CircuitBreaker) key(name string) string {
+	return "reqctl:breaker:" + b.name + ":" + name
+}
+
+// SetCircuitBreaker guards every attempt with the breaker. Attempts for which the retry checker asks a retry
+// are recorded as failures, and no further attempts are made once the breaker is open. Attempts rejected before
+// being sent, or cancelled by the caller or by the winner of the parallel calls, are not recorded.
+func (c Controller) SetCircuitBreaker(breaker *CircuitBreaker) Controller {
+	c.config.breaker = breaker
+	return c
+}
+
+// recordOutcome registers the attempt outcome with the circuit breaker, if configured
+func (c *Controller) recordOutcome(ctx context.Context, failed bool) {
+	if c.config.breaker == nil {
+		return
+	}
+
+	c.config.breaker.Record(ctx, failed)
+}
+
+// failed reports whether the attempt outcome is a failure, as classified by the retry checker
+func (c *Controller) failed(resp *http.Response, err error) bool {
+	checker := c.config.retryCfg.RetryCheckFunc
+	if checker == nil {
+		checker = DefaultRetryChecker
+	}
+	return checker(resp, err)
+}
@@ -0,0 +1,94 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	calls := 0
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			return nil, errors.New("connection refused")
+		}),
+	}
+
+	request, err := http.NewRequest("GET", "http://localhost", nil)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	store := reqctl.NewMemoryStore()
+	breaker := reqctl.NewCircuitBreaker("upstream", 2, time.Minute, time.Minute).WithStore(store)
+
+	_, err = reqctl.Request(context.Background(), request).
+		SetSimpleRetry(time.Millisecond, 5).
+		SetCircuitBreaker(breaker).
+		SetClient(client).
+		Do()
+	if !errors.Is(err, reqctl.ErrCircuitOpen) {
+		t.Errorf("Expected reqctl.ErrCircuitOpen, got %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("Expected 2 attempts before the breaker opened, got %d", calls)
+	}
+
+	// Breakers sharing a store & name share the state
+	shared := reqctl.NewCircuitBreaker("upstream", 2, time.Minute, time.Minute).WithStore(store)
+	if state := shared.State(context.Background()); state != reqctl.BreakerOpen {
+		t.Errorf("Expected shared breaker to be %q, got %q", reqctl.BreakerOpen, state)
+	}
+}
+
+func TestCircuitBreakerHedged(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			select {
+			case <-r.Context().Done():
+				return nil, r.Context().Err()
+			case <-time.After(20 * time.Millisecond):
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	breaker := reqctl.NewCircuitBreaker("hedged", 2, time.Minute, time.Minute).WithStore(reqctl.NewMemoryStore())
+	policy := reqctl.NewPolicy().
+		WithParallelCallSchedule(0, 0, 2*time.Millisecond, 4*time.Millisecond).
+		WithCircuitBreaker(breaker).
+		WithClient(client)
+
+	// The losing parallel calls are cancelled by the winner, which says nothing of the upstream
+	for i := 0; i < 4; i++ {
+		request, _ := http.NewRequest("GET", "http://localhost", nil)
+		resp, err := policy.Do(context.Background(), request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if state := breaker.State(context.Background()); state != reqctl.BreakerClosed {
+		t.Errorf("Expected breaker to stay %q, got %q", reqctl.BreakerClosed, state)
+	}
+}
+
+func TestCircuitBreakerIntermittent(t *testing.T) {
+	breaker := reqctl.NewCircuitBreaker("intermittent", 5, time.Minute, time.Minute).WithStore(reqctl.NewMemoryStore())
+
+	// Successes in between do not reset the failures counted within the window
+	ctx := context.Background()
+	for i := 0; i < 9; i++ {
+		breaker.Record(ctx, i%2 == 0)
+	}
+	if state := breaker.State(ctx); state != reqctl.BreakerOpen {
+		t.Errorf("Expected the breaker to open after 5 failures within the window, got %q", state)
+	}
+}
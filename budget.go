@@ -0,0 +1,103 @@
+package reqctl
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// ErrRetryBudgetExhausted is returned instead of retrying, when the retries are beyond the shared retry budget
+var ErrRetryBudgetExhausted = errors.New("reqctl: retry budget exhausted")
+
+// RetryBudget caps the retries to a ratio of the requests sent within a window, eg: a ratio of 0.2 allows
+// retries of at most 20% of the requests, so that an upstream outage does not multiply the traffic by the
+// retry count. A minimum of retries per window is always allowed, so that low traffic can still retry.
+// Its counters live in a StateStore, so budgets with the same name are shared across controllers & processes.
+// Store errors never block retries, the budget fails open.
+type RetryBudget struct {
+	name       string
+	ratio      float64
+	minRetries int64
+	window     time.Duration
+	store      StateStore
+}
+
+// NewRetryBudget creates a budget allowing minRetries plus ratio times the requests as retries within window.
+// The budget uses the process wide in-memory store, use WithStore to share it across processes.
+func NewRetryBudget(name string, ratio float64, minRetries int, window time.Duration) *RetryBudget {
+	if window <= 0 {
+		window = 10 * time.Second
+	}
+	return &RetryBudget{
+		name:       name,
+		ratio:      ratio,
+		minRetries: int64(minRetries),
+		window:     window,
+		store:      defaultStore,
+	}
+}
+
+// WithStore returns a copy of the budget backed by the given store
+func (b *RetryBudget) WithStore(store StateStore) *RetryBudget {
+	res := *b
+	res.store = store
+	return &res
+}
+
+// Name returns the name of the budget
+func (b *RetryBudget) Name() string {
+	return b.name
+}
+
+// Deposit registers a request, growing the retries allowed within the window
+func (b *RetryBudget) Deposit(ctx context.Context) {
+	_, _ = b.store.Add(ctx, b.key("requests"), 1, b.window)
+}
+
+// Withdraw registers a retry, returning ErrRetryBudgetExhausted without registering it if the budget is spent
+func (b *RetryBudget) Withdraw(ctx context.Context) error {
+	retries, err := b.store.Add(ctx, b.key("retries"), 1, b.window)
+	if err != nil {
+		return nil
+	}
+
+	requests, err := b.store.Get(ctx, b.key("requests"))
+	if err != nil {
+		return nil
+	}
+
+	if float64(retries) > float64(b.minRetries)+b.ratio*float64(requests) {
+		_, _ = b.store.Add(ctx, b.key("retries"), -1, b.window)
+		return ErrRetryBudgetExhausted
+	}
+	return nil
+}
+
+// key returns the store key of the budget counter for the current window
+func (b *RetryBudget) key(name string) string {
+	window := time.Now().UnixNano() / int64(b.window)
+	return "reqctl:budget:" + b.name + ":" + name + ":" + strconv.FormatInt(window, 10)
+}
+
+// SetRetryBudget draws the retries of the request from the shared budget. Every logical request deposits into
+// the budget, and the request fails with ErrRetryBudgetExhausted once a retry is beyond the budget.
+func (c Controller) SetRetryBudget(budget *RetryBudget) Controller {
+	c.config.retryBudget = budget
+	return c
+}
+
+// depositRetryBudget registers the logical request with the retry budget, if configured
+func (c *Controller) depositRetryBudget() {
+	if c.config.retryBudget != nil {
+		c.config.retryBudget.Deposit(c.ctx)
+	}
+}
+
+// withdrawRetryBudget registers a retry with the retry budget, if configured
+func (c *Controller) withdrawRetryBudget() error {
+	if c.config.retryBudget == nil {
+		return nil
+	}
+	return c.config.retryBudget.Withdraw(c.ctx)
+}
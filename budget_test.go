@@ -0,0 +1,44 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestRetryBudget(t *testing.T) {
+	calls := 0
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	// Retries of at most half the requests, along with 2 retries per window
+	budget := reqctl.NewRetryBudget("budget-test", 0.5, 2, time.Minute)
+	policy := reqctl.NewPolicy().
+		WithSimpleRetryWithChecker(0, 3, reqctl.RetryOnStatus(503)).
+		WithRetryBudget(budget)
+
+	exhausted := 0
+	for i := 0; i < 4; i++ {
+		request, _ := http.NewRequest("GET", "http://localhost", nil)
+		_, err := policy.Request(context.Background(), request).SetClient(client).Do()
+		if errors.Is(err, reqctl.ErrRetryBudgetExhausted) {
+			exhausted++
+		}
+	}
+
+	// 4 requests allow 2 + 0.5 * 4 = 4 retries
+	if calls != 8 {
+		t.Errorf("Expected 4 requests & 4 retries, got %d calls", calls)
+	}
+	if exhausted != 4 {
+		t.Errorf("Expected every request to exhaust the budget, got %d", exhausted)
+	}
+}
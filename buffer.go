@@ -0,0 +1,113 @@
+package reqctl
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// bodyBufferPool recycles the buffers holding request bodies
+var bodyBufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// bufferedBody is a request body held in a pooled buffer. It is recycled once the logical request
+// & every attempt reading it are done, as attempts of parallel calls may still be sending it.
+type bufferedBody struct {
+	buf      *bytes.Buffer
+	budget   *MemoryBudget
+	reserved int64
+	refs     int32
+}
+
+// open returns a new reader over the body, which must be closed
+func (b *bufferedBody) open() io.ReadCloser {
+	atomic.AddInt32(&b.refs, 1)
+	return &bufferReader{Reader: bytes.NewReader(b.buf.Bytes()), body: b}
+}
+
+// release drops a reference to the body, recycling the buffer with the last one
+func (b *bufferedBody) release() {
+	if atomic.AddInt32(&b.refs, -1) != 0 {
+		return
+	}
+
+	b.budget.Release(b.reserved)
+	b.buf.Reset()
+	bodyBufferPool.Put(b.buf)
+}
+
+// bufferReader reads a buffered body, releasing it on close
+type bufferReader struct {
+	*bytes.Reader
+	body *bufferedBody
+	once sync.Once
+}
+
+// Close releases the buffered body
+func (r *bufferReader) Close() error {
+	r.once.Do(r.body.release)
+	return nil
+}
+
+// SetBufferRequestBody buffers request bodies without GetBody of up to maxBytes in memory, so that they can be
+// replayed by retries & parallel calls. Buffers are pooled & accounted in the memory budget, if any.
+// Bodies beyond maxBytes or the budget are handled as per the budget fallback: they are either streamed in
+// a single attempt, without retries or parallel calls, or the request fails with ErrMemoryBudgetExceeded.
+func (c Controller) SetBufferRequestBody(maxBytes int64) Controller {
+	c.config.bufferBody = maxBytes
+	return c
+}
+
+// bufferBody returns the controller with the request body buffered, and the function releasing the buffer
+func (c *Controller) bufferBody() (*Controller, func(), error) {
+	if c.config.bufferBody <= 0 || isReplayable(c.req) {
+		return c, func() {}, nil
+	}
+
+	buf := bodyBufferPool.Get().(*bytes.Buffer)
+	_, err := buf.ReadFrom(io.LimitReader(c.req.Body, c.config.bufferBody+1))
+	if err != nil {
+		c.req.Body.Close()
+		buf.Reset()
+		bodyBufferPool.Put(buf)
+		return nil, nil, err
+	}
+
+	n := int64(buf.Len())
+	budget := c.config.memBudget
+	if n > c.config.bufferBody || !budget.Reserve(n) {
+		if budget.Fallback() == FallbackShed {
+			c.req.Body.Close()
+			buf.Reset()
+			bodyBufferPool.Put(buf)
+			return nil, nil, ErrMemoryBudgetExceeded
+		}
+
+		// The buffer is handed over to the streamed body, hence it is not recycled
+		next := *c
+		next.config.retryCfg = &retryConfig{RetryType: noRetry}
+		next.config.asyncCfg = nil
+		next.req = c.req.Clone(c.ctx)
+		next.req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(buf, c.req.Body), c.req.Body}
+		return &next, func() {}, nil
+	}
+	c.req.Body.Close()
+
+	body := &bufferedBody{buf: buf, budget: budget, reserved: n, refs: 1}
+	next := *c
+	next.req = c.req.Clone(c.ctx)
+	next.req.Body = http.NoBody
+	next.req.ContentLength = n
+	next.req.GetBody = func() (io.ReadCloser, error) {
+		return body.open(), nil
+	}
+	return &next, body.release, nil
+}
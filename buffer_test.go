@@ -0,0 +1,81 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestBufferRequestBody(t *testing.T) {
+	var bodies []string
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(r.Body)
+			r.Body.Close()
+			bodies = append(bodies, string(body))
+			return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	budget := reqctl.NewMemoryBudget(1024, reqctl.FallbackShed)
+	do := func(payload string) error {
+		bodies = nil
+		request, _ := http.NewRequest("PUT", "http://localhost", io.MultiReader(strings.NewReader(payload)))
+		resp, err := reqctl.Request(context.Background(), request).
+			SetSimpleRetryWithChecker(time.Millisecond, 2, reqctl.RetryOnStatus(503)).
+			SetBufferRequestBody(16).
+			SetMemoryBudget(budget).
+			SetClient(client).
+			Do()
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	if err := do("payload"); err != nil {
+		t.Errorf("Obtained error: %v", err)
+	}
+	if len(bodies) != 3 || bodies[0] != "payload" || bodies[2] != "payload" {
+		t.Errorf("Expected the buffered body on every attempt, got %q", bodies)
+	}
+	if used := budget.InUse(); used != 0 {
+		t.Errorf("Expected the budget to be released, got %d bytes in use", used)
+	}
+
+	if err := do(strings.Repeat("x", 17)); !errors.Is(err, reqctl.ErrMemoryBudgetExceeded) {
+		t.Errorf("Expected reqctl.ErrMemoryBudgetExceeded for bodies beyond the limit, got %v", err)
+	}
+}
+
+func TestBufferRequestBodyStreamFallback(t *testing.T) {
+	var bodies []string
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	payload := strings.Repeat("x", 32)
+	request, _ := http.NewRequest("PUT", "http://localhost", io.MultiReader(strings.NewReader(payload)))
+	_, err := reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(time.Millisecond, 2, reqctl.RetryOnStatus(503)).
+		SetBufferRequestBody(16).
+		SetClient(client).
+		Do()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+	}
+
+	if len(bodies) != 1 || bodies[0] != payload {
+		t.Errorf("Expected the whole body streamed in a single attempt, got %q", bodies)
+	}
+}
@@ -0,0 +1,201 @@
+package reqctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+// Builder builds a request fluently & executes it as per a policy, sparing the construction of the *http.Request.
+// Bodies are buffered or regenerated per attempt, so that the built requests are always safe to retry & hedge.
+// Errors are deferred until the request is built.
+type Builder struct {
+	ctx           context.Context
+	method        string
+	url           string
+	header        http.Header
+	query         url.Values
+	body          func() (io.ReadCloser, error)
+	contentLength int64
+	policy        Policy
+	err           error
+}
+
+// NewBuilder starts building a request of the method to the URL, executed as per the default policy
+func NewBuilder(ctx context.Context, method, rawURL string) *Builder {
+	return &Builder{
+		ctx:    ctx,
+		method: method,
+		url:    rawURL,
+		header: http.Header{},
+		query:  url.Values{},
+		policy: NewPolicy(),
+	}
+}
+
+// Get starts building a GET to the URL
+func Get(ctx context.Context, url string) *Builder {
+	return NewBuilder(ctx, http.MethodGet, url)
+}
+
+// Post starts building a POST to the URL
+func Post(ctx context.Context, url string) *Builder {
+	return NewBuilder(ctx, http.MethodPost, url)
+}
+
+// Put starts building a PUT to the URL
+func Put(ctx context.Context, url string) *Builder {
+	return NewBuilder(ctx, http.MethodPut, url)
+}
+
+// Patch starts building a PATCH to the URL
+func Patch(ctx context.Context, url string) *Builder {
+	return NewBuilder(ctx, http.MethodPatch, url)
+}
+
+// Delete starts building a DELETE to the URL
+func Delete(ctx context.Context, url string) *Builder {
+	return NewBuilder(ctx, http.MethodDelete, url)
+}
+
+// Header adds the value to the header of the request
+func (b *Builder) Header(key, value string) *Builder {
+	b.header.Add(key, value)
+	return b
+}
+
+// Query adds the value to the query parameter of the URL, alongside the parameters already in the URL
+func (b *Builder) Query(key, value string) *Builder {
+	b.query.Add(key, value)
+	return b
+}
+
+// WithBody sets the body of the request & its content type, if not empty.
+// The body is read once & buffered, so that every attempt sends it in full.
+func (b *Builder) WithBody(contentType string, body io.Reader) *Builder {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return b.fail(err)
+	}
+	return b.withBytes(contentType, data)
+}
+
+// WithJSONBody sets the JSON encoding of the value as the body of the request, with its content type
+func (b *Builder) WithJSONBody(v any) *Builder {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return b.fail(fmt.Errorf("reqctl: encoding request body: %w", err))
+	}
+	return b.withBytes("application/json", data)
+}
+
+// WithFormBody sets the URL encoding of the values as the body of the request, with its content type
+func (b *Builder) WithFormBody(values url.Values) *Builder {
+	return b.withBytes("application/x-www-form-urlencoded", []byte(values.Encode()))
+}
+
+// WithMultipart sets the multipart form written by fn as the body of the request, with its content type & boundary.
+// The form is written once & buffered, fn must not retain the writer.
+func (b *Builder) WithMultipart(fn func(*multipart.Writer) error) *Builder {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if err := fn(w); err != nil {
+		return b.fail(fmt.Errorf("reqctl: writing multipart body: %w", err))
+	}
+	if err := w.Close(); err != nil {
+		return b.fail(fmt.Errorf("reqctl: writing multipart body: %w", err))
+	}
+	return b.withBytes(w.FormDataContentType(), buf.Bytes())
+}
+
+// fail records the error, unless an earlier one was recorded
+func (b *Builder) fail(err error) *Builder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
+
+// withBytes sets the buffered body of the request & its content type, if not empty
+func (b *Builder) withBytes(contentType string, data []byte) *Builder {
+	if contentType != "" {
+		b.header.Set("Content-Type", contentType)
+	}
+	b.contentLength = int64(len(data))
+	b.body = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	return b
+}
+
+// Policy sets the policy as per which the request is executed
+func (b *Builder) Policy(policy Policy) *Builder {
+	b.policy = policy
+	return b
+}
+
+// Configure applies the controller setters to the policy of the request,
+// eg: b.Configure(func(c Controller) Controller { return c.SetSimpleRetry(time.Second, 3) })
+func (b *Builder) Configure(configure func(Controller) Controller) *Builder {
+	b.policy.template = configure(b.policy.template)
+	return b
+}
+
+// Build returns the built request, or the first error encountered while building it
+func (b *Builder) Build() (*http.Request, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	u, err := url.Parse(b.url)
+	if err != nil {
+		return nil, err
+	}
+	if len(b.query) > 0 {
+		query := u.Query()
+		for key, values := range b.query {
+			query[key] = append(query[key], values...)
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(b.ctx, b.method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range b.header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+
+	if b.body != nil {
+		if req.Body, err = b.body(); err != nil {
+			return nil, err
+		}
+		req.GetBody = b.body
+		req.ContentLength = b.contentLength
+	}
+	return req, nil
+}
+
+// Controller returns the controller of the built request, configured as per the policy
+func (b *Builder) Controller() (*Controller, error) {
+	req, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return b.policy.Request(b.ctx, req), nil
+}
+
+// Do builds & executes the request as per the policy
+func (b *Builder) Do() (*http.Response, error) {
+	c, err := b.Controller()
+	if err != nil {
+		return nil, err
+	}
+	return c.Do()
+}
@@ -0,0 +1,205 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestBuilder(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		attempt := len(bodies)
+		mu.Unlock()
+
+		if r.URL.Query().Get("a") != "1" || r.URL.Query().Get("b") != "2" || r.Header.Get("X-Test") != "yes" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get("Content-Type") != "text/plain" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		if attempt < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resp, err := reqctl.Put(context.Background(), server.URL+"/items?a=1").
+		Header("X-Test", "yes").
+		Query("b", "2").
+		WithBody("text/plain", strings.NewReader("payload")).
+		Configure(func(c reqctl.Controller) reqctl.Controller {
+			return c.SetSimpleRetryWithChecker(time.Millisecond, 3, reqctl.RetryOnStatus(503))
+		}).
+		Do()
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the request to succeed on the 3rd attempt, got %v, %v", resp, err)
+	}
+	resp.Body.Close()
+
+	if len(bodies) != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", len(bodies))
+	}
+	for i, body := range bodies {
+		if body != "payload" {
+			t.Errorf("Expected attempt %d to send the full body, got %q", i+1, body)
+		}
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	if _, err := reqctl.Get(context.Background(), "://bad").Header("X", "y").Do(); err == nil {
+		t.Errorf("Expected an invalid URL to fail the request")
+	}
+
+	req, err := reqctl.Get(context.Background(), "http://example.com/?a=1").Query("a", "2").Build()
+	if err != nil || req.URL.RawQuery != "a=1&a=2" || req.Body != nil {
+		t.Errorf("Expected the query parameters to be merged without a body, got %v, %v", req, err)
+	}
+
+	// The first error is kept over the later ones
+	errFirst, errSecond := errors.New("first"), errors.New("second")
+	_, err = reqctl.Post(context.Background(), "http://example.com").
+		WithMultipart(func(*multipart.Writer) error { return errFirst }).
+		WithMultipart(func(*multipart.Writer) error { return errSecond }).
+		Build()
+	if !errors.Is(err, errFirst) {
+		t.Errorf("Expected the first error, got %v", err)
+	}
+}
+
+func TestBuilderJSONBody(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		bodies = append(bodies, string(body))
+
+		if r.Header.Get("Content-Type") != "application/json" || len(bodies) < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// The Idempotency-Key header allows the POST to be retried
+	resp, err := reqctl.Post(context.Background(), server.URL).
+		Header("Idempotency-Key", "key-1").
+		WithJSONBody(map[string]int{"id": 7}).
+		Configure(func(c reqctl.Controller) reqctl.Controller {
+			return c.SetSimpleRetryWithChecker(time.Millisecond, 1, reqctl.RetryOnStatus(503))
+		}).
+		Do()
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the retried request to succeed, got %v, %v", resp, err)
+	}
+	resp.Body.Close()
+	if len(bodies) != 2 || bodies[0] != `{"id":7}` || bodies[1] != `{"id":7}` {
+		t.Errorf("Expected both attempts to send the JSON body, got %q", bodies)
+	}
+
+	if _, err := reqctl.Post(context.Background(), server.URL).WithJSONBody(make(chan int)).Do(); err == nil {
+		t.Errorf("Expected an unencodable body to fail the request")
+	}
+}
+
+func TestBuilderFormBodies(t *testing.T) {
+	var mu sync.Mutex
+	var forms []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil && err != http.ErrNotMultipart {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		form := r.PostFormValue("name")
+		if r.MultipartForm != nil {
+			if files := r.MultipartForm.File["file"]; len(files) == 1 {
+				f, _ := files[0].Open()
+				content, _ := io.ReadAll(f)
+				f.Close()
+				form += "+" + string(content)
+			}
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		forms = append(forms, form)
+		if len(forms)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	retry := func(c reqctl.Controller) reqctl.Controller {
+		return c.SetSimpleRetryWithChecker(time.Millisecond, 1, reqctl.RetryOnStatus(503))
+	}
+
+	resp, err := reqctl.Put(context.Background(), server.URL).
+		WithFormBody(url.Values{"name": {"widget"}}).
+		Configure(retry).
+		Do()
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the form to be retried, got %v, %v", resp, err)
+	}
+	resp.Body.Close()
+
+	resp, err = reqctl.Put(context.Background(), server.URL).
+		WithMultipart(func(w *multipart.Writer) error {
+			if err := w.WriteField("name", "report"); err != nil {
+				return err
+			}
+			part, err := w.CreateFormFile("file", "report.txt")
+			if err != nil {
+				return err
+			}
+			_, err = part.Write([]byte("contents"))
+			return err
+		}).
+		Configure(retry).
+		Do()
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the multipart form to be retried, got %v, %v", resp, err)
+	}
+	resp.Body.Close()
+
+	expected := []string{"widget", "widget", "report+contents", "report+contents"}
+	if len(forms) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, forms)
+	}
+	for i := range expected {
+		if forms[i] != expected[i] {
+			t.Errorf("Expected attempt %d to send %q, got %q", i+1, expected[i], forms[i])
+		}
+	}
+
+	failure := errors.New("no file")
+	_, err = reqctl.Put(context.Background(), server.URL).
+		WithMultipart(func(*multipart.Writer) error { return failure }).
+		Do()
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected the multipart error, got %v", err)
+	}
+}
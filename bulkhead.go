@@ -0,0 +1,262 @@
+package reqctl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrBulkheadFull is returned without sending the attempt, when the bulkhead has no slot & no room in its queue,
+// or when the attempt waited for a slot beyond the queue timeout
+var ErrBulkheadFull = errors.New("reqctl: bulkhead is full")
+
+// Bulkhead limits the attempts concurrently in flight across the controllers sharing it, an attempt holding its
+// slot until its response body is closed. Attempts beyond the limit wait in a bounded queue for a free slot.
+type Bulkhead struct {
+	maxConcurrent int
+	maxQueue      int
+	queueTimeout  time.Duration
+
+	mu        sync.Mutex
+	perHost   bool
+	aimd      *AIMD
+	limits    map[string]float64
+	active    map[string]int
+	waiting   map[string]int
+	available map[string]*sync.Cond
+}
+
+// NewBulkhead creates a bulkhead allowing maxConcurrent attempts in flight, with at most maxQueue attempts waiting
+// up to queueTimeout for a slot. A zero queue timeout waits until the attempt context is done.
+func NewBulkhead(maxConcurrent, maxQueue int, queueTimeout time.Duration) *Bulkhead {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	return &Bulkhead{
+		maxConcurrent: maxConcurrent,
+		maxQueue:      maxQueue,
+		queueTimeout:  queueTimeout,
+		limits:        map[string]float64{},
+		active:        map[string]int{},
+		waiting:       map[string]int{},
+		available:     map[string]*sync.Cond{},
+	}
+}
+
+// SetPerHost applies the limits to every host separately, rather than to all the attempts together
+func (b *Bulkhead) SetPerHost(perHost bool) *Bulkhead {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.perHost = perHost
+	return b
+}
+
+// Acquire obtains a slot for an attempt to the host, returning the function releasing it
+func (b *Bulkhead) Acquire(ctx context.Context, host string) (release func(), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := b.key(host)
+	if b.active[key] >= b.limit(key) {
+		if b.waiting[key] >= b.maxQueue {
+			return nil, ErrBulkheadFull
+		}
+		if err := b.wait(ctx, key); err != nil {
+			return nil, err
+		}
+	}
+
+	b.active[key]++
+	var once sync.Once
+	return func() {
+		once.Do(func() { b.release(key) })
+	}, nil
+}
+
+// wait blocks until a slot of the key is free, the queue timeout elapses or the context is done. It is called with
+// the lock held.
+func (b *Bulkhead) wait(ctx context.Context, key string) error {
+	cond := b.available[key]
+	if cond == nil {
+		cond = sync.NewCond(&b.mu)
+		b.available[key] = cond
+	}
+
+	// The waiters are woken up to observe the expiry of the timeout or the context
+	deadline := make(chan struct{})
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		var timeout <-chan time.Time
+		if b.queueTimeout > 0 {
+			timer := time.NewTimer(b.queueTimeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
+		select {
+		case <-timeout:
+		case <-ctx.Done():
+		case <-stop:
+			return
+		}
+
+		b.mu.Lock()
+		close(deadline)
+		cond.Broadcast()
+		b.mu.Unlock()
+	}()
+
+	b.waiting[key]++
+	defer func() {
+		if b.waiting[key]--; b.waiting[key] == 0 && b.active[key] == 0 {
+			delete(b.active, key)
+			delete(b.waiting, key)
+			delete(b.available, key)
+		}
+	}()
+
+	for b.active[key] >= b.limit(key) {
+		select {
+		case <-deadline:
+			// A slot freed meanwhile is passed on to the next waiter
+			cond.Signal()
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return ErrBulkheadFull
+		default:
+		}
+		cond.Wait()
+	}
+	return nil
+}
+
+// release frees the slot of the key, waking up a waiting attempt
+func (b *Bulkhead) release(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.active[key]--
+	if cond := b.available[key]; cond != nil {
+		cond.Signal()
+	}
+	if b.active[key] == 0 && b.waiting[key] == 0 {
+		delete(b.active, key)
+		delete(b.waiting, key)
+		delete(b.available, key)
+	}
+}
+
+// AIMD adapts the concurrency limit of a bulkhead to the upstream capacity, growing it additively while attempts
+// succeed & shrinking it multiplicatively when attempts time out or are throttled ( 429 & 503 ).
+type AIMD struct {
+	// MinLimit & MaxLimit bound the limit, which starts from the bulkhead limit
+	MinLimit, MaxLimit int
+	// Backoff multiplies the limit on overload, 0.9 if not within (0, 1)
+	Backoff float64
+}
+
+// overloaded reports whether the attempt outcome signals an overloaded upstream
+var overloaded = Any(RetryOnTimeout(), RetryOnStatus(http.StatusTooManyRequests, http.StatusServiceUnavailable))
+
+// SetAdaptive adapts the concurrency limit of the bulkhead as per the outcome of the attempts
+func (b *Bulkhead) SetAdaptive(aimd AIMD) *Bulkhead {
+	if aimd.MinLimit < 1 {
+		aimd.MinLimit = 1
+	}
+	if aimd.MaxLimit < aimd.MinLimit {
+		aimd.MaxLimit = aimd.MinLimit
+	}
+	if aimd.Backoff <= 0 || aimd.Backoff >= 1 {
+		aimd.Backoff = 0.9
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.aimd = &aimd
+	return b
+}
+
+// Limit returns the current concurrency limit for the host
+func (b *Bulkhead) Limit(host string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.limit(b.key(host))
+}
+
+// Record registers the outcome of an attempt to the host, adjusting the adaptive limit if configured
+func (b *Bulkhead) Record(host string, resp *http.Response, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.aimd == nil {
+		return
+	}
+
+	key := b.key(host)
+	limit, ok := b.limits[key]
+	if !ok {
+		limit = float64(b.maxConcurrent)
+	}
+
+	if overloaded(resp, err) {
+		limit *= b.aimd.Backoff
+	} else {
+		limit += 1 / limit
+	}
+
+	if limit < float64(b.aimd.MinLimit) {
+		limit = float64(b.aimd.MinLimit)
+	} else if limit > float64(b.aimd.MaxLimit) {
+		limit = float64(b.aimd.MaxLimit)
+	}
+
+	b.limits[key] = limit
+	if cond := b.available[key]; cond != nil && b.active[key] < b.limit(key) {
+		cond.Signal()
+	}
+}
+
+// limit returns the concurrency limit of the key, it is called with the lock held
+func (b *Bulkhead) limit(key string) int {
+	if b.aimd == nil {
+		return b.maxConcurrent
+	}
+	if limit, ok := b.limits[key]; ok {
+		return int(limit)
+	}
+	return b.maxConcurrent
+}
+
+// usage returns the attempts in flight & waiting across the keys
+func (b *Bulkhead) usage() (active, waiting int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, n := range b.active {
+		active += int64(n)
+	}
+	for _, n := range b.waiting {
+		waiting += int64(n)
+	}
+	return active, waiting
+}
+
+// key returns the key of the slots used by the host
+func (b *Bulkhead) key(host string) string {
+	if b.perHost {
+		return host
+	}
+	return ""
+}
+
+// SetBulkhead makes every attempt of the request, including retries & parallel calls, hold a slot of the shared
+// bulkhead until its response body is closed. Attempts rejected by the bulkhead are not retried, and the outcome
+// of the attempts sent adjusts the limit of adaptive bulkheads.
+func (c Controller) SetBulkhead(bulkhead *Bulkhead) Controller {
+	c.config.bulkhead = bulkhead
+	return c
+}
@@ -0,0 +1,111 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestBulkhead(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+	do := func(bulkhead *reqctl.Bulkhead, host string) (*http.Response, error) {
+		request, _ := http.NewRequest("GET", "http://"+host, nil)
+		return reqctl.Request(context.Background(), request).
+			SetBulkhead(bulkhead).
+			SetClient(client).
+			Do()
+	}
+
+	bulkhead := reqctl.NewBulkhead(1, 0, 0)
+	held, err := do(bulkhead, "a")
+	if err != nil {
+		t.Fatalf("Expected the first request to obtain the slot, got %v", err)
+	}
+	if _, err := do(bulkhead, "a"); !errors.Is(err, reqctl.ErrBulkheadFull) {
+		t.Errorf("Expected the bulkhead to be full while the body is open, got %v", err)
+	}
+
+	held.Body.Close()
+	resp, err := do(bulkhead, "a")
+	if err != nil {
+		t.Errorf("Expected the slot to be released once the body is closed, got %v", err)
+	} else {
+		resp.Body.Close()
+	}
+
+	// Waiting attempts obtain the released slot, or time out
+	bulkhead = reqctl.NewBulkhead(1, 1, 200*time.Millisecond)
+	held, _ = do(bulkhead, "a")
+	time.AfterFunc(50*time.Millisecond, func() { held.Body.Close() })
+	if resp, err := do(bulkhead, "a"); err != nil {
+		t.Errorf("Expected the queued request to obtain the released slot, got %v", err)
+	} else {
+		defer resp.Body.Close()
+	}
+
+	if _, err := do(bulkhead, "a"); !errors.Is(err, reqctl.ErrBulkheadFull) {
+		t.Errorf("Expected the queued request to time out, got %v", err)
+	}
+
+	// Hosts do not share slots with per host limits
+	bulkhead = reqctl.NewBulkhead(1, 0, 0).SetPerHost(true)
+	held, _ = do(bulkhead, "a")
+	defer held.Body.Close()
+	if resp, err := do(bulkhead, "b"); err != nil {
+		t.Errorf("Expected a slot for another host, got %v", err)
+	} else {
+		resp.Body.Close()
+	}
+}
+
+func TestAdaptiveBulkhead(t *testing.T) {
+	status := 503
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: status, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	bulkhead := reqctl.NewBulkhead(8, 0, 0).SetAdaptive(reqctl.AIMD{MinLimit: 2, MaxLimit: 10, Backoff: 0.5})
+	do := func(times int) {
+		for i := 0; i < times; i++ {
+			request, _ := http.NewRequest("GET", "http://localhost", nil)
+			resp, err := reqctl.Request(context.Background(), request).
+				SetBulkhead(bulkhead).
+				SetClient(client).
+				Do()
+			if err == nil {
+				resp.Body.Close()
+			}
+		}
+	}
+
+	do(1)
+	if limit := bulkhead.Limit("localhost"); limit != 4 {
+		t.Errorf("Expected the limit to halve on overload, got %d", limit)
+	}
+
+	do(3)
+	if limit := bulkhead.Limit("localhost"); limit != 2 {
+		t.Errorf("Expected the limit to stay above the minimum, got %d", limit)
+	}
+
+	status = 200
+	do(20)
+	if limit := bulkhead.Limit("localhost"); limit <= 2 {
+		t.Errorf("Expected the limit to grow on success, got %d", limit)
+	}
+
+	do(200)
+	if limit := bulkhead.Limit("localhost"); limit != 10 {
+		t.Errorf("Expected the limit to stay below the maximum, got %d", limit)
+	}
+}
@@ -0,0 +1,381 @@
+package reqctl
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheEntry is a response stored by a cache
+type CacheEntry struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// Vary holds the values of the request headers listed by the Vary header of the response
+	Vary     http.Header
+	StoredAt time.Time
+}
+
+// CacheStore persists the entries of a cache. The in-memory store is the default, shared stores can be plugged in
+// by implementing this interface, eg: over Redis or memcached.
+type CacheStore interface {
+	// Get returns the entry under key, reporting whether it exists
+	Get(ctx context.Context, key string) (CacheEntry, bool, error)
+	// Set stores the entry under key, replacing any previous one
+	Set(ctx context.Context, key string, entry CacheEntry) error
+	// Delete removes the entry under key
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryCacheStore is a CacheStore local to the process, evicting the least recently used entries
+type MemoryCacheStore struct {
+	lru *lru[CacheEntry]
+}
+
+// NewMemoryCacheStore creates an empty in-memory store holding up to maxEntries, unbounded if not positive
+func NewMemoryCacheStore(maxEntries int) *MemoryCacheStore {
+	return &MemoryCacheStore{
+		lru: newLRU[CacheEntry](maxEntries),
+	}
+}
+
+// Get returns the entry under key
+func (m *MemoryCacheStore) Get(_ context.Context, key string) (CacheEntry, bool, error) {
+	entry, ok := m.lru.get(key)
+	return entry, ok, nil
+}
+
+// Set stores the entry under key
+func (m *MemoryCacheStore) Set(_ context.Context, key string, entry CacheEntry) error {
+	m.lru.put(key, entry)
+	return nil
+}
+
+// Delete removes the entry under key
+func (m *MemoryCacheStore) Delete(_ context.Context, key string) error {
+	m.lru.remove(key)
+	return nil
+}
+
+// defaultCacheEntries bounds the in-memory store of a cache created without a store
+const defaultCacheEntries = 1024
+
+// cacheableStatus are the status codes whose responses may be stored, given an explicit freshness
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusNotFound:             true,
+	http.StatusGone:                 true,
+}
+
+// Cache is a private HTTP cache as per RFC 7234, serving the GET requests from the responses stored while fresh
+// before hitting the network. Freshness follows the Cache-Control max-age & Expires headers, responses without
+// an explicit freshness are not stored. Vary is honored for a single variant per URL. It is safe to share.
+// As it may be shared by several users, responses to requests with credentials ( Authorization or Cookie headers )
+// are stored only if marked public, s-maxage or must-revalidate as per RFC 9111 §3.5, keyed by their credentials.
+type Cache struct {
+	store                CacheStore
+	maxBodyBytes         int64
+	staleWhileRevalidate time.Duration
+	staleIfError         time.Duration
+
+	mu         sync.Mutex
+	refreshing map[string]bool
+}
+
+// NewCache creates a cache over the store, an in-memory store of 1024 entries if nil.
+// Bodies larger than 1MB are not stored, unless configured otherwise.
+func NewCache(store CacheStore) *Cache {
+	if store == nil {
+		store = NewMemoryCacheStore(defaultCacheEntries)
+	}
+	return &Cache{
+		store:        store,
+		maxBodyBytes: defaultCachedBodyBytes,
+		refreshing:   map[string]bool{},
+	}
+}
+
+// SetMaxBodyBytes bounds the bodies stored by the cache
+func (c *Cache) SetMaxBodyBytes(n int64) *Cache {
+	c.maxBodyBytes = n
+	return c
+}
+
+// credentialHeaders are the request headers identifying its user
+var credentialHeaders = []string{"Authorization", "Cookie"}
+
+// cacheKey is the primary key of the request, including the digest of its credentials if any
+func cacheKey(req *http.Request) string {
+	key := req.Method + " " + req.URL.String()
+	if !hasCredentials(req) {
+		return key
+	}
+
+	digest := sha256.New()
+	for _, name := range credentialHeaders {
+		for _, value := range req.Header.Values(name) {
+			io.WriteString(digest, name+": "+value+"\n")
+		}
+	}
+	return key + " " + hex.EncodeToString(digest.Sum(nil))
+}
+
+// hasCredentials reports whether the request carries credentials
+func hasCredentials(req *http.Request) bool {
+	for _, name := range credentialHeaders {
+		if req.Header.Get(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup returns the stored entry matching the request, store errors being deemed misses
+func (c *Cache) lookup(req *http.Request) (CacheEntry, bool) {
+	if req.Method != http.MethodGet {
+		return CacheEntry{}, false
+	}
+
+	entry, ok, err := c.store.Get(req.Context(), cacheKey(req))
+	if err != nil || !ok {
+		return CacheEntry{}, false
+	}
+	for name, values := range entry.Vary {
+		if strings.Join(req.Header.Values(name), ",") != strings.Join(values, ",") {
+			return CacheEntry{}, false
+		}
+	}
+	return entry, true
+}
+
+// age returns the current age of the entry, as per its Age header & the time elapsed since it was stored
+func (e CacheEntry) age(now time.Time) time.Duration {
+	age := now.Sub(e.StoredAt)
+	if seconds, err := strconv.ParseInt(e.Header.Get("Age"), 10, 64); err == nil && seconds > 0 {
+		age += time.Duration(seconds) * time.Second
+	}
+	if age < 0 {
+		return 0
+	}
+	return age
+}
+
+// fresh reports whether the entry may be served without revalidation
+func (e CacheEntry) fresh(now time.Time) bool {
+	lifetime, ok := freshnessLifetime(e.Header)
+	return ok && e.age(now) < lifetime
+}
+
+// response rebuilds the response of the entry for the request, with its current Age
+func (e CacheEntry) response(req *http.Request, now time.Time) *http.Response {
+	header := e.Header.Clone()
+	header.Set("Age", strconv.FormatInt(int64(e.age(now)/time.Second), 10))
+	return &http.Response{
+		Status:        strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// cacheControl parses the directives of a Cache-Control header, by their lowercase name
+func cacheControl(header http.Header) map[string]string {
+	directives := map[string]string{}
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, arg := strings.TrimSpace(directive), ""
+			if i := strings.IndexByte(name, '='); i >= 0 {
+				name, arg = name[:i], strings.Trim(name[i+1:], `"`)
+			}
+			if name != "" {
+				directives[strings.ToLower(name)] = arg
+			}
+		}
+	}
+	return directives
+}
+
+// freshnessLifetime returns the explicit freshness lifetime of the response, as per max-age or else Expires
+func freshnessLifetime(header http.Header) (time.Duration, bool) {
+	directives := cacheControl(header)
+	if _, ok := directives["no-cache"]; ok {
+		return 0, true
+	}
+	if arg, ok := directives["max-age"]; ok {
+		seconds, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || seconds < 0 {
+			return 0, true
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	expires := header.Get("Expires")
+	if expires == "" {
+		return 0, false
+	}
+	expiresAt, err := http.ParseTime(expires)
+	if err != nil {
+		// Invalid dates, eg: 0, represent a time in the past
+		return 0, true
+	}
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		return 0, false
+	}
+	return expiresAt.Sub(date), true
+}
+
+// storable reports whether the response to the request may be stored
+func storable(req *http.Request, resp *http.Response) bool {
+	if req.Method != http.MethodGet || !cacheableStatus[resp.StatusCode] || resp.Header.Get("Vary") == "*" {
+		return false
+	}
+	if _, ok := cacheControl(req.Header)["no-store"]; ok {
+		return false
+	}
+	directives := cacheControl(resp.Header)
+	if _, ok := directives["no-store"]; ok {
+		return false
+	}
+	if hasCredentials(req) {
+		_, public := directives["public"]
+		_, sMaxAge := directives["s-maxage"]
+		_, mustRevalidate := directives["must-revalidate"]
+		if !public && !sMaxAge && !mustRevalidate {
+			return false
+		}
+	}
+	_, ok := freshnessLifetime(resp.Header)
+	return ok
+}
+
+// put stores the response to the request if allowed, restoring its body. It fails only if the body cannot be
+// read or buffered within the budget, store errors being ignored.
+func (c *Cache) put(req *http.Request, resp *http.Response, now time.Time, budget *MemoryBudget) error {
+	if !storable(req, resp) {
+		return nil
+	}
+
+	body, complete, err := bufferResponse(resp, c.maxBodyBytes, budget)
+	if err != nil || !complete {
+		return err
+	}
+
+	entry := CacheEntry{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+		StoredAt:   now,
+	}
+	if resp.Uncompressed {
+		// The digests describe the encoded body, which is stored decoded
+		for _, name := range []string{"Content-Digest", "Digest", "Content-MD5"} {
+			entry.Header.Del(name)
+		}
+	}
+	for _, value := range resp.Header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				if entry.Vary == nil {
+					entry.Vary = http.Header{}
+				}
+				entry.Vary[http.CanonicalHeaderKey(name)] = req.Header.Values(name)
+			}
+		}
+	}
+
+	_ = c.store.Set(req.Context(), cacheKey(req), entry)
+	return nil
+}
+
+// invalidate removes the entry of the URL once an unsafe request to it succeeds
+func (c *Cache) invalidate(req *http.Request, resp *http.Response) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return
+	}
+	if resp.StatusCode < 400 {
+		get := req.Clone(req.Context())
+		get.Method = http.MethodGet
+		_ = c.store.Delete(req.Context(), cacheKey(get))
+	}
+}
+
+// SetCache serves the GET requests from the cache while fresh, skipping the attempts altogether, & stores their
+// eligible responses. Requests with a Cache-Control no-cache, no-store or max-age=0 directive bypass the lookup.
+func (c Controller) SetCache(cache *Cache) Controller {
+	c.config.cache = cache
+	return c
+}
+
+// cachedResponse returns the response stored for the request if it may be served without any attempt, ie: while
+// fresh, or while stale within the stale-while-revalidate window, its revalidation being started in the background
+func (c *Controller) cachedResponse() (*http.Response, bool) {
+	cache := c.config.cache
+	if cache == nil || c.revalidating {
+		return nil, false
+	}
+
+	directives := cacheControl(c.req.Header)
+	_, noCache := directives["no-cache"]
+	_, noStore := directives["no-store"]
+	if noCache || noStore || directives["max-age"] == "0" {
+		return nil, false
+	}
+
+	now := c.clock().Now()
+	entry, ok := cache.lookup(c.req)
+	if !ok {
+		return nil, false
+	}
+	if entry.fresh(now) {
+		return entry.response(c.req, now), true
+	}
+	if !cache.servesStale(entry, "stale-while-revalidate", cache.staleWhileRevalidate, now) {
+		return nil, false
+	}
+
+	c.revalidate()
+	return entry.response(c.req, now), true
+}
+
+// cacheResponse stores the response of the request, or invalidates the entry of its URL for unsafe requests.
+// A failed outcome is replaced by the stale response within the stale-if-error window, reporting so.
+func (c *Controller) cacheResponse(resp *http.Response, err error) (*http.Response, bool, error) {
+	cache := c.config.cache
+	if cache == nil {
+		return resp, false, err
+	}
+
+	if err != nil || serverError(resp) {
+		if stale, ok := c.staleResponse(); ok {
+			closeBody(resp)
+			return stale, true, nil
+		}
+	}
+	if err != nil || resp == nil {
+		return resp, false, err
+	}
+
+	cache.invalidate(c.req, resp)
+	if serr := cache.put(c.req, resp, c.clock().Now(), c.config.memBudget); serr != nil {
+		return nil, false, serr
+	}
+	return resp, false, nil
+}
@@ -0,0 +1,96 @@
+package reqctl_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestCache(t *testing.T) {
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&calls, 1)
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/vary":
+			w.Header().Set("Cache-Control", "public, max-age=60")
+			w.Header().Set("Vary", "Accept-Language")
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store, max-age=60")
+		}
+		_, _ = w.Write([]byte(r.URL.Path + " " + r.Header.Get("Accept-Language") + " " + strconv.FormatInt(n, 10)))
+	}))
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	policy := reqctl.NewPolicy().WithCache(reqctl.NewCache(nil)).WithClock(clock)
+	get := func(method, path string, header ...string) (string, bool) {
+		req, _ := http.NewRequest(method, server.URL+path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		res := policy.DoResult(context.Background(), req)
+		body, err := res.String()
+		if err != nil {
+			t.Fatalf("Expected %s %s to succeed, got %v", method, path, err)
+		}
+		return body, res.FromCache
+	}
+
+	first, _ := get("GET", "/fresh")
+	if body, cached := get("GET", "/fresh"); body != first || !cached {
+		t.Errorf("Expected the fresh response to be served from the cache, got %q", body)
+	}
+	if _, cached := get("GET", "/fresh", "Cache-Control", "no-cache"); cached {
+		t.Errorf("Expected no-cache requests to bypass the cache")
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	if _, cached := get("GET", "/fresh"); cached {
+		t.Errorf("Expected the stale response to be fetched again")
+	}
+	if _, cached := get("GET", "/fresh"); !cached {
+		t.Errorf("Expected the refetched response to be stored")
+	}
+
+	get("POST", "/fresh")
+	if _, cached := get("GET", "/fresh"); cached {
+		t.Errorf("Expected the successful POST to invalidate the URL")
+	}
+
+	en, _ := get("GET", "/vary", "Accept-Language", "en")
+	if body, cached := get("GET", "/vary", "Accept-Language", "en"); !cached || body != en {
+		t.Errorf("Expected the same variant to be served from the cache, got %q", body)
+	}
+	if _, cached := get("GET", "/vary", "Accept-Language", "fr"); cached {
+		t.Errorf("Expected another variant to miss the cache")
+	}
+
+	get("GET", "/no-store")
+	if _, cached := get("GET", "/no-store"); cached {
+		t.Errorf("Expected no-store responses not to be stored")
+	}
+	get("GET", "/none")
+	if _, cached := get("GET", "/none"); cached {
+		t.Errorf("Expected responses without freshness not to be stored")
+	}
+
+	get("GET", "/fresh", "Authorization", "Bearer alice")
+	if _, cached := get("GET", "/fresh", "Authorization", "Bearer alice"); cached {
+		t.Errorf("Expected private responses to authorized requests not to be stored")
+	}
+	alice, _ := get("GET", "/vary", "Authorization", "Bearer alice")
+	if body, cached := get("GET", "/vary", "Authorization", "Bearer alice"); !cached || body != alice {
+		t.Errorf("Expected public responses to authorized requests to be stored, got %q", body)
+	}
+	if _, cached := get("GET", "/vary", "Authorization", "Bearer bob"); cached {
+		t.Errorf("Expected the response stored for a user not to be served to another")
+	}
+}
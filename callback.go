@@ -0,0 +1,157 @@
+package reqctl
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+)
+
+// ErrCallbackPoolClosed is returned when submitting a request to a closed callback pool
+var ErrCallbackPoolClosed = errors.New("reqctl: callback pool is closed")
+
+// CallbackPanicError describes a panic of a completion callback or of its request, recovered so that it does not
+// crash the process
+type CallbackPanicError struct {
+	Value any
+	Stack []byte
+}
+
+// Error describes the recovered value
+func (e *CallbackPanicError) Error() string {
+	return fmt.Sprintf("reqctl: completion callback panicked: %v", e.Value)
+}
+
+// callbackTask is a request awaiting a worker, along with its callback
+type callbackTask struct {
+	c  Controller
+	fn func(Result)
+}
+
+// CallbackPool executes the requests submitted by DoWithCallback & calls back with their outcome, on a bounded set of
+// workers or on a goroutine each. Panics of the requests & of the callbacks are recovered & reported to the panic
+// handler, which logs them by default.
+type CallbackPool struct {
+	tasks chan callbackTask
+	wg    sync.WaitGroup
+
+	mu      sync.Mutex
+	onPanic func(*CallbackPanicError)
+
+	// closeMu is held by the submissions, so that the tasks are not closed while sent to
+	closeMu sync.RWMutex
+	closed  bool
+}
+
+// DefaultCallbackPool executes the callbacks of the controllers configured without a pool, on a goroutine each
+var DefaultCallbackPool = NewCallbackPool(0, 0)
+
+// NewCallbackPool creates a pool of workers, queueing up to queueSize requests while all of them are busy.
+// A pool without workers executes every request on its own goroutine.
+func NewCallbackPool(workers, queueSize int) *CallbackPool {
+	p := &CallbackPool{
+		onPanic: func(e *CallbackPanicError) {
+			log.Printf("%v\n%s", e, e.Stack)
+		},
+	}
+	if workers <= 0 {
+		return p
+	}
+
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	p.tasks = make(chan callbackTask, queueSize)
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer p.wg.Done()
+			for task := range p.tasks {
+				p.execute(task)
+			}
+		}()
+	}
+	return p
+}
+
+// SetPanicHandler reports the panics of the requests & callbacks to fn, called from the goroutine of the callback
+func (p *CallbackPool) SetPanicHandler(fn func(*CallbackPanicError)) *CallbackPool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onPanic = fn
+	return p
+}
+
+// submit queues the request, blocking while the queue is full until a worker frees up or the request context is done
+func (p *CallbackPool) submit(task callbackTask) error {
+	p.closeMu.RLock()
+	defer p.closeMu.RUnlock()
+	if p.closed {
+		return ErrCallbackPoolClosed
+	}
+
+	if p.tasks == nil {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.execute(task)
+		}()
+		return nil
+	}
+
+	select {
+	case p.tasks <- task:
+		return nil
+	case <-task.c.ctx.Done():
+		return task.c.ctx.Err()
+	}
+}
+
+// execute runs the request & calls back with its outcome, closing the response body afterwards
+func (p *CallbackPool) execute(task callbackTask) {
+	// The request may panic as well, eg: in a middleware or a hook, without taking the worker down
+	defer func() {
+		if v := recover(); v != nil {
+			p.mu.Lock()
+			onPanic := p.onPanic
+			p.mu.Unlock()
+			if onPanic != nil {
+				onPanic(&CallbackPanicError{Value: v, Stack: debug.Stack()})
+			}
+		}
+	}()
+	res := task.c.DoResult()
+	defer closeBody(res.Response)
+	task.fn(res)
+}
+
+// Close stops accepting requests & waits for the submitted ones to be called back
+func (p *CallbackPool) Close() {
+	p.closeMu.Lock()
+	if !p.closed {
+		p.closed = true
+		if p.tasks != nil {
+			close(p.tasks)
+		}
+	}
+	p.closeMu.Unlock()
+	p.wg.Wait()
+}
+
+// SetCallbackPool executes the requests submitted by DoWithCallback on the pool, DefaultCallbackPool if nil
+func (c Controller) SetCallbackPool(pool *CallbackPool) Controller {
+	c.config.callbackPool = pool
+	return c
+}
+
+// DoWithCallback executes the request in the background & calls fn with its outcome, on the configured callback
+// pool. The response body is closed once fn returns, hence it must be read within fn. It fails if the pool is
+// closed, or if the context of the request is done while waiting for room in the queue of the pool.
+func (c Controller) DoWithCallback(fn func(Result)) error {
+	pool := c.config.callbackPool
+	if pool == nil {
+		pool = DefaultCallbackPool
+	}
+	return pool.submit(callbackTask{c: c, fn: fn})
+}
@@ -0,0 +1,105 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestDoWithCallback(t *testing.T) {
+	var inFlight, peak int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&inFlight, 1)
+		defer atomic.AddInt64(&inFlight, -1)
+		for {
+			p := atomic.LoadInt64(&peak)
+			if n <= p || atomic.CompareAndSwapInt64(&peak, p, n) {
+				break
+			}
+		}
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var panics []*reqctl.CallbackPanicError
+	pool := reqctl.NewCallbackPool(2, 10).SetPanicHandler(func(e *reqctl.CallbackPanicError) {
+		mu.Lock()
+		defer mu.Unlock()
+		panics = append(panics, e)
+	})
+
+	var succeeded int64
+	policy := reqctl.NewPolicy().WithCallbackPool(pool)
+	for i := 0; i < 5; i++ {
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		i := i
+		err := policy.Request(context.Background(), req).DoWithCallback(func(res reqctl.Result) {
+			if i == 0 {
+				panic("boom")
+			}
+			if res.Err == nil && res.Response.StatusCode == http.StatusOK {
+				atomic.AddInt64(&succeeded, 1)
+			}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	pool.Close()
+
+	if succeeded != 4 {
+		t.Errorf("Expected the other callbacks to be called despite the panic, got %d", succeeded)
+	}
+	if len(panics) != 1 || panics[0].Value != "boom" || len(panics[0].Stack) == 0 {
+		t.Errorf("Expected the panic to be reported, got %v", panics)
+	}
+	if peak > 2 {
+		t.Errorf("Expected at most 2 requests in flight, got %d", peak)
+	}
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	if err := policy.Request(context.Background(), req).DoWithCallback(func(reqctl.Result) {}); !errors.Is(err, reqctl.ErrCallbackPoolClosed) {
+		t.Errorf("Expected the closed pool to reject the request, got %v", err)
+	}
+}
+
+func TestDoWithCallbackDefaultPool(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	done := make(chan reqctl.Result, 1)
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	if err := reqctl.Request(context.Background(), req).DoWithCallback(func(res reqctl.Result) { done <- res }); err != nil {
+		t.Fatal(err)
+	}
+	if res := <-done; res.Err != nil {
+		t.Errorf("Expected the request to succeed, got %v", res.Err)
+	}
+}
+
+func TestDoWithCallbackRequestPanic(t *testing.T) {
+	panics := make(chan *reqctl.CallbackPanicError, 1)
+	pool := reqctl.NewCallbackPool(1, 1).SetPanicHandler(func(e *reqctl.CallbackPanicError) { panics <- e })
+	defer pool.Close()
+
+	// A panicking middleware does not take the worker down
+	failing := func(next reqctl.Handler) reqctl.Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			panic("middleware")
+		}
+	}
+	req, _ := http.NewRequest("GET", "http://localhost", nil)
+	if err := reqctl.NewPolicy().WithCallbackPool(pool).Use(failing).Request(context.Background(), req).
+		DoWithCallback(func(reqctl.Result) {}); err != nil {
+		t.Fatal(err)
+	}
+	if e := <-panics; e.Value != "middleware" {
+		t.Errorf("Expected the panic of the request to be reported, got %v", e.Value)
+	}
+}
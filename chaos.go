@@ -0,0 +1,73 @@
+package reqctl
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// ErrInjectedFault is the error injected by Chaos, unless another one is configured
+var ErrInjectedFault = errors.New("reqctl: injected fault")
+
+// Chaos injects faults into the attempts on the client side, to verify that the retry & circuit breaker
+// configuration behaves under failure without breaking the upstream. Rates are probabilities within [0, 1],
+// evaluated independently for every attempt.
+type Chaos struct {
+	// LatencyRate is the probability of delaying the attempt by Latency before sending it
+	LatencyRate float64
+	Latency     time.Duration
+	// ErrorRate is the probability of failing the attempt with Err without sending it, ErrInjectedFault if not set
+	ErrorRate float64
+	Err       error
+	// StatusRate is the probability of answering the attempt with Status without sending it
+	StatusRate float64
+	Status     int
+}
+
+// ChaosMiddleware returns the middleware injecting the faults into the attempts
+func ChaosMiddleware(chaos Chaos) Middleware {
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			if chaos.Latency > 0 && hit(chaos.LatencyRate) {
+				if err := clockFromContext(req.Context()).Sleep(req.Context(), chaos.Latency); err != nil {
+					return nil, err
+				}
+			}
+
+			if hit(chaos.ErrorRate) {
+				if chaos.Err != nil {
+					return nil, chaos.Err
+				}
+				return nil, ErrInjectedFault
+			}
+
+			if chaos.Status != 0 && hit(chaos.StatusRate) {
+				return &http.Response{
+					Status:     fmt.Sprintf("%d %s", chaos.Status, http.StatusText(chaos.Status)),
+					StatusCode: chaos.Status,
+					Proto:      "HTTP/1.1",
+					ProtoMajor: 1,
+					ProtoMinor: 1,
+					Header:     http.Header{},
+					Body:       http.NoBody,
+					Request:    req,
+				}, nil
+			}
+
+			return next(req)
+		}
+	}
+}
+
+// hit reports whether an event of the probability occurs
+func hit(rate float64) bool {
+	return rate > 0 && rand.Float64() < rate
+}
+
+// SetChaos injects faults into every attempt of the request, as the innermost middleware used so far.
+// Meant for tests & game days only.
+func (c Controller) SetChaos(chaos Chaos) Controller {
+	return c.Use(ChaosMiddleware(chaos))
+}
@@ -0,0 +1,72 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestChaos(t *testing.T) {
+	calls := 0
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+	do := func(chaos reqctl.Chaos) (*http.Response, error) {
+		calls = 0
+		request, _ := http.NewRequest("GET", "http://localhost", nil)
+		return reqctl.Request(context.Background(), request).
+			SetChaos(chaos).
+			SetClient(client).
+			Do()
+	}
+
+	if _, err := do(reqctl.Chaos{ErrorRate: 1}); !errors.Is(err, reqctl.ErrInjectedFault) || calls != 0 {
+		t.Errorf("Expected the injected fault without sending, got %v after %d calls", err, calls)
+	}
+
+	resp, err := do(reqctl.Chaos{StatusRate: 1, Status: 503})
+	if err != nil || resp.StatusCode != 503 || calls != 0 {
+		t.Errorf("Expected the injected status without sending, got %v, %v after %d calls", resp, err, calls)
+	}
+
+	start := time.Now()
+	resp, err = do(reqctl.Chaos{LatencyRate: 1, Latency: 20 * time.Millisecond, ErrorRate: 0})
+	if err != nil || calls != 1 || time.Since(start) < 20*time.Millisecond {
+		t.Errorf("Expected the attempt to be delayed & sent, got %v after %d calls", err, calls)
+	} else {
+		resp.Body.Close()
+	}
+
+	// The latency follows the clock of the controller
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	resp, err = reqctl.Request(context.Background(), request).
+		SetChaos(reqctl.Chaos{LatencyRate: 1, Latency: time.Minute}).
+		SetClock(clock).
+		SetClient(client).
+		Do()
+	if err != nil || len(clock.slept) != 1 || clock.slept[0] != time.Minute {
+		t.Errorf("Expected the latency to be slept on the clock, got %v after %v", err, clock.slept)
+	} else {
+		resp.Body.Close()
+	}
+
+	// Faults are injected per attempt, hence retries overcome a partial error rate
+	calls = 0
+	request, _ = http.NewRequest("GET", "http://localhost", nil)
+	_, err = reqctl.Request(context.Background(), request).
+		SetSimpleRetry(0, 50).
+		SetChaos(reqctl.Chaos{ErrorRate: 0.5}).
+		SetClient(client).
+		Do()
+	if err != nil || calls != 1 {
+		t.Errorf("Expected the retries to overcome the faults, got %v after %d calls", err, calls)
+	}
+}
@@ -0,0 +1,58 @@
+package reqctl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// RetryOnStatus returns a checker retrying responses with any of the given status codes
+func RetryOnStatus(codes ...int) RetryCheckFunc {
+	set := make(map[int]bool, len(codes))
+	for _, code := range codes {
+		set[code] = true
+	}
+
+	return func(resp *http.Response, err error) bool {
+		return err == nil && resp != nil && set[resp.StatusCode]
+	}
+}
+
+// RetryOnTimeout returns a checker retrying attempts that timed out
+func RetryOnTimeout() RetryCheckFunc {
+	return func(_ *http.Response, err error) bool {
+		return err != nil && classifyError(err) == ReasonTimeout
+	}
+}
+
+// RetryOnNetworkError returns a checker retrying attempts that failed without a response,
+// except the ones cancelled by the caller
+func RetryOnNetworkError() RetryCheckFunc {
+	return func(_ *http.Response, err error) bool {
+		return err != nil && !errors.Is(err, context.Canceled)
+	}
+}
+
+// Any combines the checkers into one retrying when any of them asks for a retry
+func Any(checkers ...RetryCheckFunc) RetryCheckFunc {
+	return func(resp *http.Response, err error) bool {
+		for _, checker := range checkers {
+			if checker(resp, err) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// All combines the checkers into one retrying only when all of them ask for a retry
+func All(checkers ...RetryCheckFunc) RetryCheckFunc {
+	return func(resp *http.Response, err error) bool {
+		for _, checker := range checkers {
+			if !checker(resp, err) {
+				return false
+			}
+		}
+		return len(checkers) > 0
+	}
+}
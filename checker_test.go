@@ -0,0 +1,70 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestRetryCheckers(t *testing.T) {
+	status := func(code int) *http.Response {
+		return &http.Response{StatusCode: code}
+	}
+	timeout := fmt.Errorf("dial: %w", context.DeadlineExceeded)
+	refused := errors.New("connection refused")
+
+	onStatus := reqctl.RetryOnStatus(502, 503)
+	if !onStatus(status(503), nil) || onStatus(status(500), nil) || onStatus(nil, refused) {
+		t.Errorf("RetryOnStatus should retry only the given status codes")
+	}
+
+	onTimeout := reqctl.RetryOnTimeout()
+	if !onTimeout(nil, timeout) || onTimeout(nil, refused) || onTimeout(status(504), nil) {
+		t.Errorf("RetryOnTimeout should retry only timeouts")
+	}
+
+	onNetwork := reqctl.RetryOnNetworkError()
+	if !onNetwork(nil, refused) || onNetwork(nil, context.Canceled) || onNetwork(status(503), nil) {
+		t.Errorf("RetryOnNetworkError should retry errors other than cancellation")
+	}
+
+	anyOf := reqctl.Any(onStatus, onNetwork)
+	if !anyOf(status(502), nil) || !anyOf(nil, refused) || anyOf(status(404), nil) {
+		t.Errorf("Any should retry when any checker asks for it")
+	}
+
+	allOf := reqctl.All(onNetwork, onTimeout)
+	if !allOf(nil, timeout) || allOf(nil, refused) {
+		t.Errorf("All should retry only when every checker asks for it")
+	}
+
+	if reqctl.All()(nil, refused) || reqctl.Any()(nil, refused) {
+		t.Errorf("Empty combinators should not retry")
+	}
+}
+
+func TestRetryCheckerCombined(t *testing.T) {
+	calls := 0
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			if calls < 3 {
+				return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+			}
+			return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	resp, err := reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(0, 5, reqctl.Any(reqctl.RetryOnStatus(503), reqctl.RetryOnNetworkError())).
+		SetClient(client).
+		Do()
+	if err != nil || resp.StatusCode != 200 || calls != 3 {
+		t.Errorf("Expected success on the third attempt, got %d calls with %v", calls, err)
+	}
+}
@@ -0,0 +1,81 @@
+package reqctl
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client wraps an HTTP client with a default policy, applied to every request sent through it.
+// It lets a service standardize on a single resilient client instead of configuring each call site.
+type Client struct {
+	policy Policy
+}
+
+// NewClient creates a client which sends the requests via client as per the policy.
+// A nil client uses the client of the policy, or else http.DefaultClient.
+func NewClient(client *http.Client, policy Policy) *Client {
+	if client != nil {
+		policy = policy.WithClient(client)
+	}
+
+	return &Client{
+		policy: policy,
+	}
+}
+
+// Policy returns the default policy of the client
+func (c *Client) Policy() Policy {
+	return c.policy
+}
+
+// Request creates a controller for the request, configured as per the client policy
+func (c *Client) Request(ctx context.Context, req *http.Request) *Controller {
+	return c.policy.Request(ctx, req)
+}
+
+// Do executes the request as per the client policy
+func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return c.policy.Do(ctx, req)
+}
+
+// Get issues a GET to the specified URL
+func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
+	return c.send(ctx, http.MethodGet, url, "", nil)
+}
+
+// Head issues a HEAD to the specified URL
+func (c *Client) Head(ctx context.Context, url string) (*http.Response, error) {
+	return c.send(ctx, http.MethodHead, url, "", nil)
+}
+
+// Post issues a POST to the specified URL with the given content type & body.
+// Retried & parallel attempts need a replayable body, eg: *bytes.Reader or *strings.Reader.
+func (c *Client) Post(ctx context.Context, url, contentType string, body io.Reader) (*http.Response, error) {
+	return c.send(ctx, http.MethodPost, url, contentType, body)
+}
+
+// PostForm issues a POST to the specified URL with the URL-encoded data as the body
+func (c *Client) PostForm(ctx context.Context, url string, data url.Values) (*http.Response, error) {
+	return c.Post(ctx, url, "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
+}
+
+// CloseIdleConnections closes the idle connections of the HTTP client the requests are sent via
+func (c *Client) CloseIdleConnections() {
+	c.policy.template.client().CloseIdleConnections()
+}
+
+// send builds & executes the request as per the client policy
+func (c *Client) send(ctx context.Context, method, url, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return c.Do(ctx, req)
+}
@@ -0,0 +1,78 @@
+package reqctl_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestClient(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first call of every request fails
+		if atomic.AddInt32(&calls, 1)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(r.Method + ":" + string(body)))
+	}))
+	defer server.Close()
+
+	checker := func(resp *http.Response, err error) bool {
+		return err != nil || resp.StatusCode >= 500
+	}
+
+	client := reqctl.NewClient(server.Client(), reqctl.NewPolicy().
+		WithSimpleRetryWithChecker(time.Millisecond, 1, checker).
+		WithRetryNonIdempotent(true))
+
+	ctx := context.Background()
+	expected := []string{"GET:", "POST:a=b"}
+	responses := []func() (*http.Response, error){
+		func() (*http.Response, error) { return client.Get(ctx, server.URL) },
+		func() (*http.Response, error) { return client.PostForm(ctx, server.URL, url.Values{"a": {"b"}}) },
+	}
+
+	for i, do := range responses {
+		resp, err := do()
+		if err != nil {
+			t.Errorf("Obtained error: %v", err)
+			continue
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != expected[i] {
+			t.Errorf("Expected body %q, got %q", expected[i], body)
+		}
+	}
+}
+
+// idleTransport counts the calls closing its idle connections
+type idleTransport struct {
+	http.RoundTripper
+	closed int32
+}
+
+func (t *idleTransport) CloseIdleConnections() {
+	atomic.AddInt32(&t.closed, 1)
+}
+
+func TestClientCloseIdleConnections(t *testing.T) {
+	transport := &idleTransport{RoundTripper: http.DefaultTransport}
+	client := reqctl.NewClient(&http.Client{Transport: transport}, reqctl.NewPolicy())
+	client.CloseIdleConnections()
+
+	if atomic.LoadInt32(&transport.closed) != 1 {
+		t.Errorf("Expected the idle connections of the transport to be closed")
+	}
+}
@@ -0,0 +1,65 @@
+package reqctl
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of time of the retry waits, parallel call delays & elapsed time budget,
+// replaceable in tests by a fake clock so that backoffs complete without waiting
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// Sleep waits for the duration, returning the context error as soon as the context is done
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// SystemClock is the Clock of the time package, used by default
+var SystemClock Clock = systemClock{}
+
+// systemClock follows the wall clock
+type systemClock struct{}
+
+// Now returns time.Now
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep waits on a timer
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	return sleep(ctx, d)
+}
+
+// SetClock drives the retry waits, parallel call delays, elapsed time budget, throttled bodies & chaos latencies of
+// the request by the clock. Timeouts & durations of the attempts still follow the wall clock.
+func (c Controller) SetClock(clock Clock) Controller {
+	c.config.clock = clock
+	return c
+}
+
+// clockCtxKey is the context key under which the clock of the attempt is stored
+type clockCtxKey struct{}
+
+// withClock attaches the clock to the context, if any
+func withClock(ctx context.Context, clock Clock) context.Context {
+	if clock == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, clockCtxKey{}, clock)
+}
+
+// clockFromContext returns the clock attached to the context, SystemClock if absent
+func clockFromContext(ctx context.Context) Clock {
+	if clock, ok := ctx.Value(clockCtxKey{}).(Clock); ok {
+		return clock
+	}
+	return SystemClock
+}
+
+// clock returns the clock of the controller
+func (c *Controller) clock() Clock {
+	if c.config.clock == nil {
+		return SystemClock
+	}
+	return c.config.clock
+}
@@ -0,0 +1,73 @@
+package reqctl_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+// fakeClock advances its time by the slept durations, without waiting
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	slept []time.Duration
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	f.slept = append(f.slept, d)
+	return ctx.Err()
+}
+
+func TestClock(t *testing.T) {
+	calls := 0
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+	do := func(clock *fakeClock, maxElapsed time.Duration) reqctl.Result {
+		calls = 0
+		request, _ := http.NewRequest("GET", "http://localhost", nil)
+		return reqctl.Request(context.Background(), request).
+			SetExponentialRetryWithChecker(time.Second, 5, reqctl.RetryOnStatus(503)).
+			SetMaxElapsedTime(maxElapsed).
+			SetClock(clock).
+			SetClient(client).
+			DoResult()
+	}
+
+	start := time.Now()
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	res := do(clock, 0)
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second}
+	if !reflect.DeepEqual(clock.slept, expected) || calls != 6 {
+		t.Errorf("Expected the exponential waits %v over 6 calls, got %v over %d", expected, clock.slept, calls)
+	}
+	if res.Elapsed != 31*time.Second {
+		t.Errorf("Expected the elapsed time as per the clock, got %s", res.Elapsed)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Expected the retries not to wait on the wall clock")
+	}
+
+	clock = &fakeClock{now: time.Unix(0, 0)}
+	do(clock, 10*time.Second)
+	if calls != 4 {
+		t.Errorf("Expected the elapsed time budget to follow the clock, got %d calls", calls)
+	}
+}
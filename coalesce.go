@@ -0,0 +1,138 @@
+package reqctl
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Coalescer shares the outcome of a request in flight with the identical requests made meanwhile, so that N
+// callers retrying against a struggling upstream do not multiply its load. Only GET & HEAD requests are coalesced,
+// keyed by their method, URL & credentials ( Authorization & Cookie headers ) unless configured otherwise, so that
+// callers never obtain the response of another's credentials. It is safe to share across controllers.
+type Coalescer struct {
+	key          func(req *http.Request) string
+	maxBodyBytes int64
+
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// flight is a request in flight, along with its outcome once done
+type flight struct {
+	done chan struct{}
+	// response is nil when the outcome could not be shared, eg: the body is too large
+	response *storedResponse
+	err      error
+}
+
+// NewCoalescer creates a coalescer. Bodies larger than 1MB are not shared, the waiting requests being sent
+// on their own instead.
+func NewCoalescer() *Coalescer {
+	return &Coalescer{
+		key:          coalesceKey,
+		maxBodyBytes: defaultCachedBodyBytes,
+		flights:      map[string]*flight{},
+	}
+}
+
+// coalesceKey keys the request by its method, URL & credentials
+func coalesceKey(req *http.Request) string {
+	key := req.Method + " " + req.URL.String()
+	for _, name := range []string{"Authorization", "Cookie"} {
+		if values := req.Header.Values(name); len(values) > 0 {
+			key += "\n" + name + ": " + strings.Join(values, "; ")
+		}
+	}
+	return key
+}
+
+// SetKey sets the function keying the requests, eg: to include the headers the response depends on
+func (co *Coalescer) SetKey(key func(req *http.Request) string) *Coalescer {
+	co.key = key
+	return co
+}
+
+// SetMaxBodyBytes bounds the bodies shared by the coalescer
+func (co *Coalescer) SetMaxBodyBytes(n int64) *Coalescer {
+	co.maxBodyBytes = n
+	return co
+}
+
+// join returns the flight of the key, reporting whether the caller leads it
+func (co *Coalescer) join(key string) (*flight, bool) {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+
+	if f, ok := co.flights[key]; ok {
+		return f, false
+	}
+	f := &flight{done: make(chan struct{})}
+	co.flights[key] = f
+	return f, true
+}
+
+// land publishes the outcome of the flight, restoring the body of the response for its leader.
+// The outcome of a leader whose context is done is not shared, the waiting requests being sent on their own.
+func (co *Coalescer) land(key string, f *flight, abandoned bool, budget *MemoryBudget, resp *http.Response, err error) (*http.Response, error) {
+	co.mu.Lock()
+	delete(co.flights, key)
+	co.mu.Unlock()
+	defer close(f.done)
+
+	if abandoned && err != nil {
+		return resp, err
+	}
+	if err != nil || resp == nil {
+		f.err = err
+		return resp, err
+	}
+
+	body, complete, err := bufferResponse(resp, co.maxBodyBytes, budget)
+	if err != nil {
+		f.err = err
+		return nil, err
+	}
+	if complete {
+		f.response = &storedResponse{statusCode: resp.StatusCode, header: resp.Header.Clone(), body: body}
+	}
+	return resp, nil
+}
+
+// SetCoalescing shares the outcome of the identical GET & HEAD requests in flight as per the coalescer,
+// the requests joining a flight waiting for its outcome instead of being sent
+func (c Controller) SetCoalescing(coalescer *Coalescer) Controller {
+	c.config.coalescer = coalescer
+	return c
+}
+
+// coalesce sends the request, or else waits for the outcome of the identical request in flight, reporting so
+func (c *Controller) coalesce(client *http.Client, exec *execution) (*Controller, *http.Response, bool, error) {
+	co := c.config.coalescer
+	if co == nil || (c.req.Method != http.MethodGet && c.req.Method != http.MethodHead) {
+		next, resp, err := c.send(client, exec)
+		return next, resp, false, err
+	}
+
+	key := co.key(c.req)
+	f, leader := co.join(key)
+	if leader {
+		next, resp, err := c.send(client, exec)
+		resp, err = co.land(key, f, c.ctx.Err() != nil, c.config.memBudget, resp, err)
+		return next, resp, false, err
+	}
+
+	select {
+	case <-f.done:
+	case <-c.ctx.Done():
+		return c, nil, false, c.ctx.Err()
+	}
+
+	if f.err != nil {
+		return c, nil, true, f.err
+	} else if f.response == nil {
+		next, resp, err := c.send(client, exec)
+		return next, resp, false, err
+	}
+	return c, f.response.response(c.req), true, nil
+}
@@ -0,0 +1,143 @@
+package reqctl_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestCoalescing(t *testing.T) {
+	var calls int64
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		<-release
+		_, _ = w.Write([]byte("shared"))
+	}))
+	defer server.Close()
+
+	policy := reqctl.NewPolicy().WithCoalescing(reqctl.NewCoalescer())
+	const callers = 5
+	var wg sync.WaitGroup
+	var shared int64
+	bodies := make([]string, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req, _ := http.NewRequest("GET", server.URL, nil)
+			res := policy.DoResult(context.Background(), req)
+			if res.Shared {
+				atomic.AddInt64(&shared, 1)
+			}
+			bodies[i], _ = res.String()
+		}(i)
+	}
+
+	// The callers join the flight of the first one while its response is held
+	for atomic.LoadInt64(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt64(&calls); n != 1 || shared != callers-1 {
+		t.Errorf("Expected a single call shared by %d callers, got %d calls shared by %d", callers-1, n, shared)
+	}
+	for i, body := range bodies {
+		if body != "shared" {
+			t.Errorf("Expected caller %d to read the whole body, got %q", i, body)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	if res := policy.DoResult(context.Background(), req); res.Shared || res.Err != nil {
+		t.Errorf("Expected a request after the flight landed to be sent, got %v", res.Err)
+	} else {
+		res.Response.Body.Close()
+	}
+}
+
+func TestCoalescingLeaderCancelled(t *testing.T) {
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&calls, 1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write([]byte("own"))
+	}))
+	defer server.Close()
+
+	policy := reqctl.NewPolicy().WithCoalescing(reqctl.NewCoalescer())
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() {
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		leader <- policy.DoResult(ctx, req).Err
+	}()
+	for atomic.LoadInt64(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	follower := make(chan string, 1)
+	go func() {
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		body, err := policy.DoResult(context.Background(), req).String()
+		if err != nil {
+			body = err.Error()
+		}
+		follower <- body
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	// The cancellation of the leader is its own, the follower sends the request instead
+	if err := <-leader; err == nil {
+		t.Errorf("Expected the cancelled leader to fail")
+	}
+	if body := <-follower; body != "own" {
+		t.Errorf("Expected the follower to send on its own, got %q", body)
+	}
+}
+
+func TestCoalescingCredentials(t *testing.T) {
+	var calls int64
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		<-release
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	policy := reqctl.NewPolicy().WithCoalescing(reqctl.NewCoalescer())
+	var wg sync.WaitGroup
+	bodies := make([]string, 2)
+	for i, token := range []string{"Bearer alice", "Bearer bob"} {
+		wg.Add(1)
+		go func(i int, token string) {
+			defer wg.Done()
+			req, _ := http.NewRequest("GET", server.URL, nil)
+			req.Header.Set("Authorization", token)
+			bodies[i], _ = policy.DoResult(context.Background(), req).String()
+		}(i, token)
+	}
+
+	for i := 0; i < 100 && atomic.LoadInt64(&calls) < 2; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if bodies[0] != "Bearer alice" || bodies[1] != "Bearer bob" {
+		t.Errorf("Expected the requests of distinct credentials not to be coalesced, got %v", bodies)
+	}
+}
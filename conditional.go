@@ -0,0 +1,160 @@
+package reqctl
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// defaultCachedBodyBytes bounds the bodies stored by the caches unless configured otherwise
+const defaultCachedBodyBytes = 1 << 20
+
+// storedResponse is a response retained along with its body, to be served again
+type storedResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+// response rebuilds the stored response for the request
+func (s *storedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(s.statusCode) + " " + http.StatusText(s.statusCode),
+		StatusCode:    s.statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        s.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(s.body)),
+		ContentLength: int64(len(s.body)),
+		Request:       req,
+	}
+}
+
+// bufferResponse reads the body up to the max bytes, restoring it on the response. It reports whether the body
+// was read in full, a larger body being left to stream after the bytes already read. The buffer is reserved in the
+// budget until the response is closed, the budget's fallback being applied if it is exhausted.
+func bufferResponse(resp *http.Response, maxBytes int64, budget *MemoryBudget) ([]byte, bool, error) {
+	n := maxBytes
+	if resp.ContentLength >= 0 && resp.ContentLength < n {
+		n = resp.ContentLength
+	}
+	if !budget.Reserve(n) {
+		if budget.Fallback() == FallbackShed {
+			resp.Body.Close()
+			return nil, false, ErrMemoryBudgetExceeded
+		}
+		return nil, false, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, n+1))
+	if err != nil {
+		budget.Release(n)
+		resp.Body.Close()
+		return nil, false, err
+	}
+
+	if int64(len(data)) > n {
+		budget.Release(n)
+		resp.Body = &teeBody{Reader: io.MultiReader(bytes.NewReader(data), resp.Body), Closer: resp.Body}
+		return nil, false, nil
+	}
+
+	budget.Release(n - int64(len(data)))
+	resp.Body.Close()
+	resp.Body = &budgetedBody{Reader: bytes.NewReader(data), budget: budget, reserved: int64(len(data))}
+	return data, true, nil
+}
+
+// ConditionalCache stores the validators ( ETag & Last-Modified ) of the responses to GET & HEAD requests along
+// with their representation. Subsequent requests are made conditional via If-None-Match & If-Modified-Since, a
+// 304 Not Modified being answered with the stored representation. It is safe to share across controllers.
+type ConditionalCache struct {
+	lru          *lru[*storedResponse]
+	maxBodyBytes int64
+}
+
+// NewConditionalCache creates a cache holding up to maxEntries representations, unbounded if not positive.
+// Bodies larger than 1MB are not stored, unless configured otherwise.
+func NewConditionalCache(maxEntries int) *ConditionalCache {
+	return &ConditionalCache{
+		lru:          newLRU[*storedResponse](maxEntries),
+		maxBodyBytes: defaultCachedBodyBytes,
+	}
+}
+
+// SetMaxBodyBytes bounds the bodies stored by the cache
+func (cc *ConditionalCache) SetMaxBodyBytes(n int64) *ConditionalCache {
+	cc.maxBodyBytes = n
+	return cc
+}
+
+// Middleware returns the middleware making the attempts conditional
+func (cc *ConditionalCache) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			// Requests already conditional are the caller's own business
+			if (req.Method != http.MethodGet && req.Method != http.MethodHead) ||
+				req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+				return next(req)
+			}
+
+			key := req.Method + " " + req.URL.String()
+			stored, ok := cc.lru.get(key)
+			if ok {
+				if etag := stored.header.Get("ETag"); etag != "" {
+					req.Header.Set("If-None-Match", etag)
+				}
+				if modified := stored.header.Get("Last-Modified"); modified != "" {
+					req.Header.Set("If-Modified-Since", modified)
+				}
+			}
+
+			resp, err := next(req)
+			if err != nil || resp == nil {
+				return resp, err
+			}
+
+			if ok && resp.StatusCode == http.StatusNotModified {
+				closeBody(resp)
+				return cc.refresh(key, stored, resp).response(req), nil
+			}
+			if resp.StatusCode != http.StatusOK {
+				return resp, nil
+			}
+			if resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "" {
+				cc.lru.remove(key)
+				return resp, nil
+			}
+
+			body, complete, err := bufferResponse(resp, cc.maxBodyBytes, budgetFromContext(req.Context()))
+			if err != nil {
+				return nil, err
+			}
+			if complete {
+				cc.lru.put(key, &storedResponse{statusCode: resp.StatusCode, header: resp.Header.Clone(), body: body})
+			}
+			return resp, nil
+		}
+	}
+}
+
+// refresh stores the representation updated with the headers of the 304 response
+func (cc *ConditionalCache) refresh(key string, stored *storedResponse, notModified *http.Response) *storedResponse {
+	updated := *stored
+	updated.header = stored.header.Clone()
+	for name, values := range notModified.Header {
+		if name != "Content-Length" {
+			updated.header[name] = values
+		}
+	}
+	cc.lru.put(key, &updated)
+	return &updated
+}
+
+// SetConditional makes the GET & HEAD attempts conditional as per the cache, as the innermost middleware used so
+// far. A 304 Not Modified is answered with the stored representation, classified by the retry checker as a 200.
+func (c Controller) SetConditional(cache *ConditionalCache) Controller {
+	return c.Use(cache.Middleware())
+}
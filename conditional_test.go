@@ -0,0 +1,68 @@
+package reqctl_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestConditionalCache(t *testing.T) {
+	var full, notModified int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt64(&notModified, 1)
+			w.Header().Set("X-Checked", "yes")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt64(&full, 1)
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("representation"))
+	}))
+	defer server.Close()
+
+	policy := reqctl.NewPolicy().WithConditional(reqctl.NewConditionalCache(8))
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest("GET", server.URL+"/item", nil)
+		result := policy.DoResult(context.Background(), req)
+		body, err := result.String()
+		if err != nil || body != "representation" || result.Response.StatusCode != http.StatusOK {
+			t.Fatalf("Expected the representation on request %d, got %q, %v", i+1, body, err)
+		}
+		if i > 0 && result.Response.Header.Get("X-Checked") != "yes" {
+			t.Errorf("Expected the headers of the 304 to be merged on request %d", i+1)
+		}
+	}
+
+	if full != 1 || notModified != 2 {
+		t.Errorf("Expected 1 full & 2 revalidated responses, got %d & %d", full, notModified)
+	}
+}
+
+func TestConditionalCacheEviction(t *testing.T) {
+	var conditional int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") != "" {
+			atomic.AddInt64(&conditional, 1)
+		}
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	policy := reqctl.NewPolicy().WithConditional(reqctl.NewConditionalCache(1))
+	for _, path := range []string{"/a", "/b", "/a"} {
+		req, _ := http.NewRequest("GET", server.URL+path, nil)
+		if _, err := policy.DoResult(context.Background(), req).Bytes(); err != nil {
+			t.Fatalf("Expected %s to succeed, got %v", path, err)
+		}
+	}
+
+	if conditional != 0 {
+		t.Errorf("Expected /a to be evicted by /b, got %d conditional requests", conditional)
+	}
+}
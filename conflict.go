@@ -0,0 +1,67 @@
+package reqctl
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+// RefreshFunc re-reads a resource after a write conflict & recomputes the write,
+// returning the new request body along with the ETag of the freshly read representation.
+type RefreshFunc func(ctx context.Context, conflict *http.Response) (body []byte, etag string, err error)
+
+// conflictConfig holds the configuration of the read-modify-write loop
+type conflictConfig struct {
+	refresh      RefreshFunc
+	maxRefreshes int
+}
+
+// SetConflictRefresh encapsulates the optimistic concurrency loop for conditional writes. When the write results in
+// 412 or 409, the refresh function is invoked to re-read the resource & recompute the body, and the write is sent
+// again under the policy with If-Match set to the new ETag, at most maxRefreshes times.
+func (c Controller) SetConflictRefresh(refresh RefreshFunc, maxRefreshes int) Controller {
+	c.config.conflictCfg = &conflictConfig{
+		refresh:      refresh,
+		maxRefreshes: maxRefreshes,
+	}
+	return c
+}
+
+// isConflict reports whether the response rejected a conditional write
+func isConflict(resp *http.Response) bool {
+	return resp != nil && (resp.StatusCode == http.StatusPreconditionFailed || resp.StatusCode == http.StatusConflict)
+}
+
+// executeWithRefresh executes the request, refreshing & resending it on write conflicts
+func (c *Controller) executeWithRefresh(client *http.Client, exec *execution) (*http.Response, error) {
+	cfg := c.config.conflictCfg
+	resp, err := c.execute(client, exec)
+	if cfg == nil {
+		return resp, err
+	}
+
+	for i := 0; i < cfg.maxRefreshes && err == nil && isConflict(resp); i++ {
+		body, etag, rErr := cfg.refresh(c.ctx, resp)
+		closeBody(resp)
+		if rErr != nil {
+			return nil, rErr
+		}
+
+		next := *c
+		next.req = c.req.Clone(c.ctx)
+		next.req.Body = io.NopCloser(bytes.NewReader(body))
+		next.req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		next.req.ContentLength = int64(len(body))
+		if etag != "" {
+			next.req.Header.Set("If-Match", etag)
+		}
+
+		c = &next
+		resp, err = c.execute(client, exec)
+	}
+
+	return resp, err
+}
@@ -0,0 +1,59 @@
+package reqctl_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestConflictRefresh(t *testing.T) {
+	var mu sync.Mutex
+	version := "v2"
+	var stored string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Header.Get("If-Match") != version {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		stored = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	request, err := http.NewRequest("PUT", server.URL, strings.NewReader("stale"))
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+	request.Header.Set("If-Match", "v1")
+
+	refreshes := 0
+	refresh := func(ctx context.Context, conflict *http.Response) ([]byte, string, error) {
+		refreshes++
+		return []byte("fresh"), "v2", nil
+	}
+
+	resp, err := reqctl.Request(context.Background(), request).
+		SetConflictRefresh(refresh, 2).
+		Do()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+
+	if resp.StatusCode != http.StatusNoContent || stored != "fresh" || refreshes != 1 {
+		t.Errorf("Expected a single refresh storing %q, got status %d, stored %q, refreshes %d",
+			"fresh", resp.StatusCode, stored, refreshes)
+	}
+}
@@ -0,0 +1,126 @@
+package reqctl
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AttemptFailure is the record of an attempt of a request given up
+type AttemptFailure struct {
+	At time.Time `json:"at"`
+	// Delivery is the 1 based delivery of the attempt for the requests of a delivery queue, 0 otherwise
+	Delivery   int    `json:"delivery,omitempty"`
+	Attempt    int    `json:"attempt"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Err        string `json:"error,omitempty"`
+}
+
+// DeadLetter is a request given up permanently, serialized along with the record of its attempts.
+// Sensitive header values are redacted.
+type DeadLetter struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	// Body of the request, captured only for replayable bodies
+	Body     []byte           `json:"body,omitempty"`
+	Failures []AttemptFailure `json:"failures"`
+	GaveUpAt time.Time        `json:"gave_up_at"`
+}
+
+// DeadLetterSink receives the requests given up permanently, eg: to persist them for a later replay or to alert
+type DeadLetterSink interface {
+	DeadLetter(ctx context.Context, letter DeadLetter) error
+}
+
+// DeadLetterFunc adapts a function into a DeadLetterSink
+type DeadLetterFunc func(ctx context.Context, letter DeadLetter) error
+
+// DeadLetter calls the function
+func (f DeadLetterFunc) DeadLetter(ctx context.Context, letter DeadLetter) error {
+	return f(ctx, letter)
+}
+
+// JSONDeadLetterSink writes the dead letters as JSON lines, eg: to an append-only file
+type JSONDeadLetterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONDeadLetterSink creates a sink writing to w
+func NewJSONDeadLetterSink(w io.Writer) *JSONDeadLetterSink {
+	return &JSONDeadLetterSink{w: w}
+}
+
+// DeadLetter writes the letter as a single line
+func (s *JSONDeadLetterSink) DeadLetter(_ context.Context, letter DeadLetter) error {
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(data, '\n'))
+	return err
+}
+
+// newDeadLetter serializes the request given up, redacting its sensitive headers
+func newDeadLetter(method, url string, header http.Header, body []byte, failures []AttemptFailure) DeadLetter {
+	header = header.Clone()
+	for _, name := range redactedHeaders {
+		if header.Get(name) != "" {
+			header.Set(name, redactedValue)
+		}
+	}
+	return DeadLetter{
+		Method:   method,
+		URL:      url,
+		Header:   header,
+		Body:     body,
+		Failures: failures,
+		GaveUpAt: time.Now(),
+	}
+}
+
+// attemptFailures converts the records of the attempts of a delivery
+func attemptFailures(records []AttemptRecord, delivery int) []AttemptFailure {
+	failures := make([]AttemptFailure, 0, len(records))
+	for _, rec := range records {
+		failure := AttemptFailure{At: rec.Start, Delivery: delivery, Attempt: rec.Seq, StatusCode: rec.StatusCode}
+		if rec.Err != nil {
+			failure.Err = rec.Err.Error()
+		}
+		failures = append(failures, failure)
+	}
+	return failures
+}
+
+// SetDeadLetter sends the requests given up to the sink, ie: whose final outcome is still deemed failed by the retry
+// checker once the attempts are settled. Requests whose context is done are not given up, & errors of the sink are
+// ignored. Refer DeliveryQueue.SetDeadLetter for the queued requests.
+func (c Controller) SetDeadLetter(sink DeadLetterSink) Controller {
+	c.config.deadLetter = sink
+	return c
+}
+
+// giveUp sends the failed request to the dead letter sink
+func (c *Controller) giveUp(res Result) {
+	if c.config.deadLetter == nil || res.Shared || res.FromCache || c.ctx.Err() != nil || !c.failed(res.Response, res.Err) {
+		return
+	}
+
+	var body []byte
+	if c.req.GetBody != nil {
+		if r, err := c.req.GetBody(); err == nil {
+			body, _ = io.ReadAll(r)
+			r.Close()
+		}
+	}
+	failures := attemptFailures(res.records, 0)
+	letter := newDeadLetter(c.req.Method, c.req.URL.String(), c.req.Header, body, failures)
+	_ = c.config.deadLetter.DeadLetter(c.ctx, letter)
+}
@@ -0,0 +1,109 @@
+package reqctl_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestDeadLetter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("ok") == "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	var letters []reqctl.DeadLetter
+	policy := reqctl.NewPolicy().
+		WithSimpleRetryWithChecker(time.Millisecond, 2, reqctl.RetryOnStatus(503)).
+		WithRetryNonIdempotent(true).
+		WithDeadLetter(reqctl.DeadLetterFunc(func(_ context.Context, letter reqctl.DeadLetter) error {
+			letters = append(letters, letter)
+			return nil
+		}))
+
+	resp, err := reqctl.Post(context.Background(), server.URL).
+		Header("Authorization", "Bearer secret").
+		WithBody("text/plain", strings.NewReader("payload")).
+		Policy(policy).
+		Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if len(letters) != 1 {
+		t.Fatalf("Expected the request to be given up, got %d letters", len(letters))
+	}
+	letter := letters[0]
+	if letter.Method != http.MethodPost || string(letter.Body) != "payload" || letter.Header.Get("Authorization") != "[REDACTED]" {
+		t.Errorf("Expected the request to be serialized & redacted, got %+v", letter)
+	}
+	if len(letter.Failures) != 3 {
+		t.Fatalf("Expected every attempt to be recorded, got %+v", letter.Failures)
+	}
+	for i, failure := range letter.Failures {
+		if failure.Attempt != i+1 || failure.StatusCode != http.StatusServiceUnavailable || failure.At.IsZero() {
+			t.Errorf("Expected the attempt %d to be recorded, got %+v", i+1, failure)
+		}
+	}
+
+	// Successful requests are not given up
+	resp, err = reqctl.Get(context.Background(), server.URL).Query("ok", "1").Policy(policy).Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(letters) != 1 {
+		t.Errorf("Expected the successful request not to be given up, got %d letters", len(letters))
+	}
+}
+
+func TestDeliveryQueueDeadLetter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	q := reqctl.NewDeliveryQueue(reqctl.NewMemoryQueueStore()).
+		SetRedeliveryBackoff(time.Millisecond, time.Millisecond).
+		SetMaxDeliveries(2).
+		SetDeadLetter(reqctl.NewJSONDeadLetterSink(&buf))
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	policy, err := reqctl.PolicySpec{
+		Version: reqctl.PolicySpecVersion,
+		Retry: &reqctl.RetrySpec{
+			Strategy:   "simple",
+			MaxRetries: 1,
+			Interval:   reqctl.Duration(time.Millisecond),
+			Checker:    &reqctl.CheckerSpec{Statuses: []int{502}},
+		},
+	}.Policy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Enqueue(context.Background(), req, policy); err != nil {
+		t.Fatal(err)
+	}
+	runQueue(t, q)
+
+	var letter reqctl.DeadLetter
+	if err := json.Unmarshal(buf.Bytes(), &letter); err != nil {
+		t.Fatalf("Expected a JSON dead letter, got %q: %v", buf.String(), err)
+	}
+	if len(letter.Failures) != 4 {
+		t.Fatalf("Expected the attempts of both deliveries, got %+v", letter.Failures)
+	}
+	if letter.Failures[0].Delivery != 1 || letter.Failures[3].Delivery != 2 || letter.Failures[3].Attempt != 2 {
+		t.Errorf("Expected the failures to be ordered by delivery & attempt, got %+v", letter.Failures)
+	}
+}
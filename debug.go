@@ -0,0 +1,246 @@
+package reqctl
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxTrackedHosts bounds the number of hosts tracked by HostMetrics, further hosts are not tracked
+const maxTrackedHosts = 1024
+
+// HostStats is a snapshot of the recent attempts sent to a host
+type HostStats struct {
+	Attempts    int64         `json:"attempts"`
+	Retries     int64         `json:"retries"`
+	Failures    int64         `json:"failures"`
+	LastStatus  int           `json:"last_status,omitempty"`
+	LastError   string        `json:"last_error,omitempty"`
+	LastAttempt time.Time     `json:"last_attempt"`
+	AvgLatency  time.Duration `json:"avg_latency_ns"`
+	// RetriesByReason counts the retries by why the previous attempt was retried
+	RetriesByReason map[RetryReason]int64 `json:"retries_by_reason,omitempty"`
+}
+
+// debugState holds the live decision state exposed by DebugHandler
+var debugState = struct {
+	mu       sync.Mutex
+	policies map[string]Policy
+	breakers map[string]*CircuitBreaker
+}{
+	policies: map[string]Policy{},
+	breakers: map[string]*CircuitBreaker{},
+}
+
+// debugHosts holds the counters of every host, apart from the debug state so that the attempts of distinct hosts
+// do not contend on a process wide lock
+var debugHosts struct {
+	counters sync.Map // host -> *hostCounters
+	tracked  int64
+}
+
+// hostCounters accumulates the attempts of a host
+type hostCounters struct {
+	mu      sync.Mutex
+	stats   HostStats
+	latency time.Duration
+}
+
+// RegisterPolicy exposes the policy under name in DebugHandler, replacing any policy registered with the same name
+func RegisterPolicy(name string, p Policy) {
+	debugState.mu.Lock()
+	defer debugState.mu.Unlock()
+	debugState.policies[name] = p
+}
+
+// registerBreaker exposes the breaker in DebugHandler, the latest breaker with a name wins
+func registerBreaker(b *CircuitBreaker) {
+	debugState.mu.Lock()
+	defer debugState.mu.Unlock()
+	debugState.breakers[b.name] = b
+}
+
+// recordHost accounts the attempt in the stats of its host
+func recordHost(attempt Attempt, req *http.Request, resp *http.Response, err error, failed bool, latency time.Duration) {
+	host := req.URL.Host
+	value, ok := debugHosts.counters.Load(host)
+	if !ok {
+		if atomic.LoadInt64(&debugHosts.tracked) >= maxTrackedHosts {
+			return
+		}
+		if value, ok = debugHosts.counters.LoadOrStore(host, &hostCounters{}); !ok {
+			atomic.AddInt64(&debugHosts.tracked, 1)
+		}
+	}
+
+	counters := value.(*hostCounters)
+	counters.mu.Lock()
+	defer counters.mu.Unlock()
+
+	stats := &counters.stats
+	stats.Attempts++
+	if attempt.Retried() {
+		stats.Retries++
+		if stats.RetriesByReason == nil {
+			stats.RetriesByReason = map[RetryReason]int64{}
+		}
+		stats.RetriesByReason[attempt.Reason]++
+	}
+	if failed {
+		stats.Failures++
+	}
+
+	stats.LastStatus, stats.LastError = 0, ""
+	if resp != nil {
+		stats.LastStatus = resp.StatusCode
+	}
+	if err != nil {
+		stats.LastError = err.Error()
+	}
+
+	stats.LastAttempt = time.Now()
+	counters.latency += latency
+	stats.AvgLatency = counters.latency / time.Duration(stats.Attempts)
+}
+
+// HostMetrics returns the attempt stats of every host reached by the controllers, keyed by host
+func HostMetrics() map[string]HostStats {
+	res := map[string]HostStats{}
+	debugHosts.counters.Range(func(host, value any) bool {
+		counters := value.(*hostCounters)
+		counters.mu.Lock()
+		stats := counters.stats
+		if stats.RetriesByReason != nil {
+			stats.RetriesByReason = copyReasons(stats.RetriesByReason)
+		}
+		res[host.(string)] = stats
+		counters.mu.Unlock()
+		return true
+	})
+	return res
+}
+
+// policySnapshot describes the configuration of a policy for operators
+type policySnapshot struct {
+	Retry            string          `json:"retry"`
+	MaxRetries       int             `json:"max_retries,omitempty"`
+	RetryInterval    string          `json:"retry_interval,omitempty"`
+	Jitter           Jitter          `json:"jitter,omitempty"`
+	MaxBackoff       string          `json:"max_backoff,omitempty"`
+	MaxElapsed       string          `json:"max_elapsed,omitempty"`
+	Timeout          string          `json:"timeout,omitempty"`
+	ParallelDelay    string          `json:"parallel_delay,omitempty"`
+	ParallelSchedule []string        `json:"parallel_schedule,omitempty"`
+	SoftFail         bool            `json:"soft_fail,omitempty"`
+	CircuitBreaker   string          `json:"circuit_breaker,omitempty"`
+	MemoryBudget     *budgetSnapshot `json:"memory_budget,omitempty"`
+}
+
+// budgetSnapshot describes the usage of a memory budget
+type budgetSnapshot struct {
+	Max   int64 `json:"max_bytes"`
+	InUse int64 `json:"in_use_bytes"`
+}
+
+// breakerSnapshot describes the state of a circuit breaker
+type breakerSnapshot struct {
+	State     BreakerState `json:"state"`
+	Threshold int64        `json:"threshold"`
+	Window    string       `json:"window"`
+	Cooldown  string       `json:"cooldown"`
+}
+
+// debugSnapshot is the document served by DebugHandler
+type debugSnapshot struct {
+	Policies  map[string]policySnapshot  `json:"policies"`
+	Breakers  map[string]breakerSnapshot `json:"circuit_breakers"`
+	Hosts     map[string]HostStats       `json:"hosts"`
+	Transport PoolStats                  `json:"transport"`
+}
+
+// durationString formats the duration, leaving zero values empty
+func durationString(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
+// snapshot describes the controller configuration
+func (c *Controller) snapshot() policySnapshot {
+	retryCfg := c.config.retryCfg
+	res := policySnapshot{
+		Retry:      string(retryCfg.RetryType),
+		Jitter:     c.config.jitter,
+		MaxBackoff: durationString(c.config.maxBackoff),
+		MaxElapsed: durationString(c.config.maxElapsed),
+		Timeout:    durationString(c.config.timeout),
+		SoftFail:   c.config.softFail,
+	}
+
+	if retryCfg.RetryType != noRetry {
+		res.MaxRetries = retryCfg.MaxCount
+		res.RetryInterval = retryCfg.RetryInterval.String()
+	}
+	if c.config.asyncCfg != nil {
+		res.ParallelDelay = c.config.asyncCfg.Delay.String()
+		for _, offset := range c.config.asyncCfg.Schedule {
+			res.ParallelSchedule = append(res.ParallelSchedule, offset.String())
+		}
+	}
+	if c.config.breaker != nil {
+		res.CircuitBreaker = c.config.breaker.Name()
+	}
+	if budget := c.config.memBudget; budget != nil {
+		res.MemoryBudget = &budgetSnapshot{Max: budget.max, InUse: budget.InUse()}
+	}
+	return res
+}
+
+// DebugHandler serves the live decision state of the library as JSON: the registered policies, circuit breaker
+// states, memory budgets, per host attempt stats & transport usage. Mount it on an internal debug server only.
+func DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		doc := debugSnapshot{
+			Policies:  map[string]policySnapshot{},
+			Breakers:  map[string]breakerSnapshot{},
+			Hosts:     HostMetrics(),
+			Transport: TransportStats(),
+		}
+
+		debugState.mu.Lock()
+		policies := make(map[string]Policy, len(debugState.policies))
+		for name, p := range debugState.policies {
+			policies[name] = p
+		}
+		breakers := make([]*CircuitBreaker, 0, len(debugState.breakers))
+		for _, b := range debugState.breakers {
+			breakers = append(breakers, b)
+		}
+		debugState.mu.Unlock()
+
+		for name, p := range policies {
+			doc.Policies[name] = p.template.snapshot()
+		}
+
+		// Breaker states are read outside the lock, as external stores may be slow
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+		defer cancel()
+		for _, b := range breakers {
+			doc.Breakers[b.name] = breakerSnapshot{
+				State:     b.State(ctx),
+				Threshold: b.threshold,
+				Window:    b.window.String(),
+				Cooldown:  b.cooldown.String(),
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(doc)
+	})
+}
@@ -0,0 +1,99 @@
+package reqctl_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestDebugHandler(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	breaker := reqctl.NewCircuitBreaker("debug-test", 2, time.Minute, time.Minute)
+	policy := reqctl.NewPolicy().
+		WithSimpleRetryWithChecker(0, 2, reqctl.RetryOnStatus(503)).
+		WithTimeout(time.Second).
+		WithCircuitBreaker(breaker).
+		WithClient(client)
+	reqctl.RegisterPolicy("debug-test", policy)
+
+	request, _ := http.NewRequest("GET", "http://debug.test/items", nil)
+	if _, err := policy.Do(context.Background(), request); err == nil {
+		t.Errorf("Expected the breaker to open")
+	}
+
+	rec := httptest.NewRecorder()
+	reqctl.DebugHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/reqctl", nil))
+
+	var doc struct {
+		Policies map[string]struct {
+			Retry          string `json:"retry"`
+			MaxRetries     int    `json:"max_retries"`
+			Timeout        string `json:"timeout"`
+			CircuitBreaker string `json:"circuit_breaker"`
+		} `json:"policies"`
+		Breakers map[string]struct {
+			State string `json:"state"`
+		} `json:"circuit_breakers"`
+		Hosts map[string]reqctl.HostStats `json:"hosts"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+		t.Fatalf("Invalid debug document: %v", err)
+	}
+
+	p := doc.Policies["debug-test"]
+	if p.Retry != "simple" || p.MaxRetries != 2 || p.Timeout != "1s" || p.CircuitBreaker != "debug-test" {
+		t.Errorf("Unexpected policy snapshot: %+v", p)
+	}
+
+	if state := doc.Breakers["debug-test"].State; state != "open" {
+		t.Errorf("Expected open breaker, got %q", state)
+	}
+
+	host := doc.Hosts["debug.test"]
+	if host.Attempts != 2 || host.Retries != 1 || host.Failures != 2 || host.LastStatus != 503 ||
+		host.RetriesByReason[reqctl.ReasonServerError] != 1 {
+		t.Errorf("Unexpected host stats: %+v", host)
+	}
+
+	if metrics := reqctl.HostMetrics()["debug.test"]; metrics.Attempts != 2 {
+		t.Errorf("Expected host metrics to match the debug document, got %+v", metrics)
+	}
+}
+
+func TestHostMetricsHedged(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			// The first call is slow, hence the parallel call is fired
+			if attempt, _ := reqctl.AttemptFromContext(r.Context()); !attempt.Hedged {
+				time.Sleep(20 * time.Millisecond)
+			}
+			return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	request, _ := http.NewRequest("GET", "http://hedged.test/items", nil)
+	resp, err := reqctl.Request(context.Background(), request).
+		SetParallelCallWithDelay(time.Millisecond).
+		SetClient(client).
+		Do()
+	if err != nil {
+		t.Fatalf("Obtained error: %v", err)
+	}
+	resp.Body.Close()
+
+	// Wait for the losing call to be accounted
+	time.Sleep(40 * time.Millisecond)
+	if host := reqctl.HostMetrics()["hedged.test"]; host.Attempts != 2 || host.Retries != 0 {
+		t.Errorf("Expected 2 attempts without retries, got %+v", host)
+	}
+}
@@ -0,0 +1,146 @@
+package reqctl
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Decompressor decodes a body of a content encoding
+type Decompressor func(r io.Reader) (io.ReadCloser, error)
+
+// defaultDecompressors are the content encodings decoded by the standard library
+var defaultDecompressors = map[string]Decompressor{
+	"gzip": func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	"deflate": decodeDeflate,
+}
+
+// decodeDeflate decodes a deflate body, ie: zlib as per RFC 9110, or else raw deflate as sent by some servers
+func decodeDeflate(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// DecompressMiddleware returns the middleware advertising the supported content encodings & decoding the response
+// bodies transparently. gzip & deflate are supported out of the box, other encodings such as br or zstd are decoded
+// by the decompressors, keyed by their lowercase name. Requests setting their own Accept-Encoding are left encoded.
+func DecompressMiddleware(decompressors map[string]Decompressor) Middleware {
+	supported := make(map[string]Decompressor, len(defaultDecompressors)+len(decompressors))
+	for name, d := range defaultDecompressors {
+		supported[name] = d
+	}
+	for name, d := range decompressors {
+		supported[strings.ToLower(name)] = d
+	}
+
+	names := make([]string, 0, len(supported))
+	for name := range supported {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	accept := strings.Join(names, ", ")
+
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Accept-Encoding") != "" {
+				return next(req)
+			}
+
+			req.Header.Set("Accept-Encoding", accept)
+			resp, err := next(req)
+			if err != nil || resp == nil || !hasBody(req, resp) {
+				return resp, err
+			}
+
+			if err := decompress(resp, supported); err != nil {
+				closeBody(resp)
+				return nil, err
+			}
+			return resp, nil
+		}
+	}
+}
+
+// hasBody reports whether the response has a body to decode, unlike the responses to HEAD requests, 204 & 304
+func hasBody(req *http.Request, resp *http.Response) bool {
+	return resp.Body != nil && resp.Body != http.NoBody && resp.ContentLength != 0 && req.Method != http.MethodHead &&
+		resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotModified
+}
+
+// decodedBody is a decoded body, closing its decoders along with the body
+type decodedBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+// Close closes the decoders, from the outermost, & then the body
+func (d *decodedBody) Close() error {
+	var err error
+	for i := len(d.closers) - 1; i >= 0; i-- {
+		if cerr := d.closers[i].Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// decompress decodes the body as per its content encodings, which are listed in the order they were applied
+func decompress(resp *http.Response, supported map[string]Decompressor) error {
+	value := resp.Header.Get("Content-Encoding")
+	if value == "" {
+		return nil
+	}
+
+	encodings := strings.Split(value, ",")
+	decoders := make([]Decompressor, 0, len(encodings))
+	for i := len(encodings) - 1; i >= 0; i-- {
+		name := strings.ToLower(strings.TrimSpace(encodings[i]))
+		if name == "identity" {
+			continue
+		}
+		d, ok := supported[name]
+		if !ok {
+			// Bodies of unsupported encodings are returned as is
+			return nil
+		}
+		decoders = append(decoders, d)
+	}
+
+	body := &decodedBody{Reader: resp.Body, closers: []io.Closer{resp.Body}}
+	for _, d := range decoders {
+		rc, err := d(body.Reader)
+		if err != nil {
+			// The body is closed by the caller, the decoders opened so far along with it
+			body.closers = body.closers[1:]
+			body.Close()
+			return fmt.Errorf("reqctl: decoding %s response: %w", value, err)
+		}
+		body.Reader = rc
+		body.closers = append(body.closers, rc)
+	}
+
+	resp.Body = body
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// SetDecompression decodes the response bodies transparently as the innermost middleware used so far,
+// refer DecompressMiddleware. It composes with the retries, the size limit applying to the decoded body.
+func (c Controller) SetDecompression(decompressors map[string]Decompressor) Controller {
+	return c.Use(DecompressMiddleware(decompressors))
+}
@@ -0,0 +1,98 @@
+package reqctl_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestDecompression(t *testing.T) {
+	var accepted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accepted = r.Header.Get("Accept-Encoding")
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write([]byte(strings.Repeat("a", 1024)))
+		zw.Close()
+
+		// The custom encoding base64 encodes the gzip stream
+		w.Header().Set("Content-Encoding", "gzip, x-b64")
+		_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString(buf.Bytes())))
+	}))
+	defer server.Close()
+
+	decompressors := map[string]reqctl.Decompressor{
+		"X-B64": func(r io.Reader) (io.ReadCloser, error) {
+			return io.NopCloser(base64.NewDecoder(base64.StdEncoding, r)), nil
+		},
+	}
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	result := reqctl.Request(context.Background(), req).SetDecompression(decompressors).DoResult()
+	if result.Err != nil || result.Response.Header.Get("Content-Encoding") != "" || !result.Response.Uncompressed {
+		t.Fatalf("Expected the response to be decoded, got %v", result.Err)
+	}
+	if body, err := result.String(); err != nil || body != strings.Repeat("a", 1024) {
+		t.Errorf("Expected the decoded body, got %d bytes, %v", len(body), err)
+	}
+	if accepted != "deflate, gzip, x-b64" {
+		t.Errorf("Expected the supported encodings to be advertised, got %q", accepted)
+	}
+
+	_, err := reqctl.Request(context.Background(), req).
+		SetDecompression(decompressors).
+		SetMaxResponseBytes(512).
+		DoResult().
+		Bytes()
+	if !errors.Is(err, reqctl.ErrResponseTooLarge) {
+		t.Errorf("Expected the size limit to apply to the decoded body, got %v", err)
+	}
+}
+
+func TestDecompressionDeflate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "deflate")
+		if r.Method == http.MethodHead {
+			return
+		}
+
+		var buf bytes.Buffer
+		var zw io.WriteCloser
+		if r.URL.Path == "/raw" {
+			zw, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+		} else {
+			zw = zlib.NewWriter(&buf)
+		}
+		_, _ = zw.Write([]byte("deflated " + r.URL.Path))
+		zw.Close()
+		_, _ = w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	// deflate is zlib as per the RFC, some servers sending raw deflate instead
+	for _, path := range []string{"/zlib", "/raw"} {
+		req, _ := http.NewRequest("GET", server.URL+path, nil)
+		body, err := reqctl.Request(context.Background(), req).SetDecompression(nil).DoResult().String()
+		if err != nil || body != "deflated "+path {
+			t.Errorf("Expected the %s body to be decoded, got %q, %v", path, body, err)
+		}
+	}
+
+	req, _ := http.NewRequest("HEAD", server.URL, nil)
+	if res := reqctl.Request(context.Background(), req).SetDecompression(nil).DoResult(); res.Err != nil {
+		t.Errorf("Expected the HEAD response without a body not to be decoded, got %v", res.Err)
+	} else {
+		res.Response.Body.Close()
+	}
+}
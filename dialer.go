@@ -0,0 +1,141 @@
+package reqctl
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+// EndpointPinning defines how the attempts of a logical request are mapped to the resolved addresses of the host
+type EndpointPinning int
+
+const (
+	// PinNone leaves address selection to the transport ( default )
+	PinNone = EndpointPinning(iota)
+	// PinPerRequest resolves the host once & sends every attempt of the request to the same address
+	PinPerRequest
+	// PinRotate resolves the host once & sends every attempt of the request to the next address
+	PinRotate
+)
+
+// dialSettings holds the per attempt dial overrides, read by the dialer returned from DialContext
+type dialSettings struct {
+	host           string
+	ip             string
+	connectTimeout time.Duration
+}
+
+// dialCtxKey is the context key under which the dial settings are stored
+type dialCtxKey struct{}
+
+// managedClient is used by Do when no client is configured and dial level features are enabled.
+// It is shared across controllers so that the connection pool is reused.
+var managedClient = &http.Client{
+	Transport: newManagedTransport(),
+}
+
+// newManagedTransport clones the default transport with a dialer honoring the attempt dial settings
+func newManagedTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = DialContext(&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	})
+	return transport
+}
+
+// DialContext wraps the dialer so that it honors the dial settings of the controller, eg: endpoint pinning
+// & connect timeout, and accounts the open connections in TransportStats.
+// Use it as the http.Transport.DialContext of clients passed to the controller.
+//
+// Note: Pinning applies only when a new connection is dialed, pooled connections are reused as is.
+func DialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		settings, ok := ctx.Value(dialCtxKey{}).(dialSettings)
+		if !ok {
+			return trackDial(dialer.DialContext(ctx, network, addr))
+		}
+
+		if settings.connectTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, settings.connectTimeout)
+			defer cancel()
+		}
+
+		// Only the target host is pinned, connections to proxies are left untouched
+		if host, port, err := net.SplitHostPort(addr); err == nil && settings.ip != "" && host == settings.host {
+			addr = net.JoinHostPort(settings.ip, port)
+		}
+
+		return trackDial(dialer.DialContext(ctx, network, addr))
+	}
+}
+
+// trackDial registers the dialed connection in the pool stats
+func trackDial(conn net.Conn, err error) (net.Conn, error) {
+	if err != nil {
+		return nil, err
+	}
+	return newTrackedConn(conn), nil
+}
+
+// SetEndpointPinning configures how the attempts & parallel calls of a request are mapped to the resolved addresses
+// of the host. Resolution happens once per logical request, and falls back to regular dialing on failure.
+func (c Controller) SetEndpointPinning(pinning EndpointPinning) Controller {
+	c.config.pinning = pinning
+	return c
+}
+
+// SetConnectTimeout bounds the time spent establishing a connection, separately from the timeout of the attempt.
+// It is enforced at the dial layer, hence requires the shared transport used by Do or a client dialing via DialContext.
+func (c Controller) SetConnectTimeout(timeout time.Duration) Controller {
+	c.config.connectTimeout = timeout
+	return c
+}
+
+// usesDialSettings reports whether any dial level feature is enabled
+func (c *Controller) usesDialSettings() bool {
+	return c.config.pinning != PinNone || c.config.connectTimeout > 0
+}
+
+// resolve looks up the addresses of the request host, for pinning its attempts
+func (c *Controller) resolve() []string {
+	host := c.req.URL.Hostname()
+	if c.config.pinning == PinNone || net.ParseIP(host) != nil {
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(c.ctx, host)
+	if err != nil {
+		return nil
+	}
+
+	ips := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		ips = append(ips, addr.IP.String())
+	}
+	return ips
+}
+
+// withDialSettings attaches the dial settings of the attempt to the context
+func (c *Controller) withDialSettings(ctx context.Context, exec *execution, attempt Attempt) context.Context {
+	if !c.usesDialSettings() {
+		return ctx
+	}
+
+	settings := dialSettings{
+		host:           c.req.URL.Hostname(),
+		connectTimeout: c.config.connectTimeout,
+	}
+
+	if n := len(exec.addrs); n > 0 {
+		if c.config.pinning == PinRotate {
+			settings.ip = exec.addrs[(attempt.Seq-1)%n]
+		} else {
+			settings.ip = exec.addrs[0]
+		}
+	}
+
+	return context.WithValue(ctx, dialCtxKey{}, settings)
+}
@@ -0,0 +1,118 @@
+package reqctl_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestDialContextPinning(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	host, port, _ := net.SplitHostPort(serverURL.Host)
+
+	if addrs, err := net.LookupHost("localhost"); err != nil || len(addrs) == 0 || addrs[0] != host {
+		t.Skip("localhost does not resolve to the test server address")
+	}
+
+	var mu sync.Mutex
+	var dialed []string
+	dial := reqctl.DialContext(&net.Dialer{})
+	client := &http.Client{
+		Transport: &http.Transport{
+			DisableKeepAlives: true,
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				conn, err := dial(ctx, network, addr)
+				if err == nil {
+					mu.Lock()
+					dialed = append(dialed, conn.RemoteAddr().String())
+					mu.Unlock()
+				}
+				return conn, err
+			},
+		},
+	}
+
+	// localhost resolves to the loopback address, which the server listens on
+	request, err := http.NewRequest("GET", "http://localhost:"+port, nil)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	checker := func(resp *http.Response, err error) bool {
+		return true
+	}
+
+	_, err = reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(0, 2, checker).
+		SetEndpointPinning(reqctl.PinPerRequest).
+		SetClient(client).
+		Do()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+
+	if len(dialed) != 3 {
+		t.Errorf("Expected 3 dials, got %v", dialed)
+		return
+	}
+
+	for _, addr := range dialed {
+		if addr != dialed[0] {
+			t.Errorf("Expected every attempt pinned to %v, got %v", dialed[0], dialed)
+		}
+	}
+}
+
+func TestConnectTimeout(t *testing.T) {
+	// The resolver hangs until the dial is cancelled, simulating a connect which never completes
+	dialer := &net.Dialer{
+		Resolver: &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+		},
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: reqctl.DialContext(dialer),
+		},
+	}
+
+	request, err := http.NewRequest("GET", "http://hanging.invalid", nil)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err = reqctl.Request(ctx, request).
+		SetConnectTimeout(50 * time.Millisecond).
+		SetClient(client).
+		Do()
+	if err == nil {
+		t.Errorf("Request should have failed on connect timeout")
+	}
+
+	if time.Since(start) > time.Second {
+		t.Errorf("Expected connect to be aborted after 50ms, took %v", time.Since(start))
+	}
+}
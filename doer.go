@@ -0,0 +1,34 @@
+package reqctl
+
+import (
+	"context"
+	"net/http"
+)
+
+// Doer executes requests, implemented by Policy & Client. Application code can depend on it,
+// so that tests can substitute a fake instead of hitting the network.
+type Doer interface {
+	Do(ctx context.Context, req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc adapts a function into a Doer, eg: for hand written fakes
+type DoerFunc func(ctx context.Context, req *http.Request) (*http.Response, error)
+
+// Do calls the function
+func (f DoerFunc) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return f(ctx, req)
+}
+
+// ResultDoer executes requests returning their outcome along with the record of the attempts,
+// implemented by Policy & Client
+type ResultDoer interface {
+	DoResult(ctx context.Context, req *http.Request) Result
+}
+
+var (
+	_ Doer       = Policy{}
+	_ Doer       = (*Client)(nil)
+	_ Doer       = DoerFunc(nil)
+	_ ResultDoer = Policy{}
+	_ ResultDoer = (*Client)(nil)
+)
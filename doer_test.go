@@ -0,0 +1,35 @@
+package reqctl_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+// fetchStatus is application code depending on the Doer interface
+func fetchStatus(doer reqctl.Doer, url string) (int, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := doer.Do(context.Background(), req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func TestDoerFake(t *testing.T) {
+	fake := reqctl.DoerFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusTeapot, Body: http.NoBody, Request: req}, nil
+	})
+
+	status, err := fetchStatus(fake, "http://localhost")
+	if err != nil || status != http.StatusTeapot {
+		t.Errorf("Expected status code 418 from the fake, got %d, error: %v", status, err)
+	}
+}
@@ -0,0 +1,145 @@
+package reqctl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrContentChanged is returned when the content downloaded changes between the resumed requests
+var ErrContentChanged = errors.New("reqctl: content changed during download")
+
+// offsetWriter writes sequentially from an offset of a WriterAt, recording its error apart from the reader's
+type offsetWriter struct {
+	w   io.WriterAt
+	off int64
+	err error
+}
+
+// Write writes at the current offset
+func (o *offsetWriter) Write(p []byte) (int, error) {
+	n, err := o.w.WriteAt(p, o.off)
+	o.off += int64(n)
+	o.err = err
+	return n, err
+}
+
+// rangeValidator returns the validator of the response usable in If-Range, ie: a strong ETag or else Last-Modified
+func rangeValidator(resp *http.Response) string {
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
+
+// parseContentRange returns the first byte & the complete length of a Content-Range header, eg: bytes 10-99/100.
+// The length is -1 when unknown.
+func parseContentRange(value string) (int64, int64, bool) {
+	if !strings.HasPrefix(value, "bytes ") {
+		return 0, 0, false
+	}
+	byteRange, size, ok := strings.Cut(strings.TrimPrefix(value, "bytes "), "/")
+	if !ok {
+		return 0, 0, false
+	}
+	first, _, ok := strings.Cut(byteRange, "-")
+	if !ok {
+		return 0, 0, false
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if size == "*" {
+		return start, -1, true
+	}
+	length, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, length, true
+}
+
+// DownloadInto downloads the response body of the request into w. A body failing midway is resumed from the last
+// received offset with a Range request, up to the retry count of the controller & after its backoff, the attempts
+// of each request following its retry policy as usual. The content is matched across the requests by If-Range with
+// its ETag or Last-Modified, failing with ErrContentChanged if it changes. Servers ignoring ranges send the content
+// from the start again. The content is requested unencoded ( Accept-Encoding: identity ), so that the offsets are
+// those of the content sent. Requests with their own Range are not supported, failing as such. It returns the number
+// of bytes of the content written.
+func DownloadInto(c *Controller, w io.WriterAt) (int64, error) {
+	if c.req.Header.Get("Range") != "" {
+		return 0, errors.New("reqctl: downloading a request with a Range header is not supported")
+	}
+
+	var offset int64
+	total := int64(-1)
+	validator := ""
+
+	var backoff time.Duration
+	for resume := 0; ; resume++ {
+		dl := *c
+		dl.req = c.req.Clone(c.ctx)
+		dl.req.Header.Set("Accept-Encoding", "identity")
+		if offset > 0 {
+			dl.req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+			if validator != "" {
+				dl.req.Header.Set("If-Range", validator)
+			}
+		}
+
+		resp, err := dl.Do()
+		if err != nil {
+			return offset, err
+		}
+
+		switch {
+		case offset > 0 && resp.StatusCode == http.StatusPartialContent:
+			start, length, ok := parseContentRange(resp.Header.Get("Content-Range"))
+			if !ok || start != offset || (total >= 0 && length >= 0 && length != total) {
+				closeBody(resp)
+				return offset, fmt.Errorf("%w: range %q resuming at %d", ErrContentChanged,
+					resp.Header.Get("Content-Range"), offset)
+			}
+		case resp.StatusCode == http.StatusOK:
+			if offset > 0 && validator != "" && rangeValidator(resp) != validator {
+				closeBody(resp)
+				return offset, ErrContentChanged
+			}
+			offset, total, validator = 0, resp.ContentLength, rangeValidator(resp)
+		default:
+			if err := checkStatus(resp, nil); err != nil {
+				return offset, err
+			}
+			closeBody(resp)
+			return offset, fmt.Errorf("reqctl: unexpected download status %d", resp.StatusCode)
+		}
+
+		out := &offsetWriter{w: w, off: offset}
+		_, err = io.Copy(out, resp.Body)
+		resp.Body.Close()
+		offset = out.off
+		if out.err != nil {
+			return offset, out.err
+		}
+		if err == nil && total >= 0 && offset < total {
+			err = fmt.Errorf("%w: read %d of %d bytes", ErrTruncatedBody, offset, total)
+		}
+		if err == nil {
+			return offset, nil
+		}
+
+		if resume >= c.config.retryCfg.MaxCount || c.ctx.Err() != nil {
+			return offset, err
+		}
+		backoff = c.backoff(resume, backoff)
+		if err := c.clock().Sleep(c.ctx, backoff); err != nil {
+			return offset, err
+		}
+	}
+}
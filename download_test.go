@@ -0,0 +1,77 @@
+package reqctl_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+// flakyContent serves the content with ranges, the first response being cut midway
+func flakyContent(t *testing.T, etag func(call int64) string) (*httptest.Server, []byte, *int64) {
+	content := bytes.Repeat([]byte("0123456789"), 10_000)
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := atomic.AddInt64(&calls, 1)
+		if encoding := r.Header.Get("Accept-Encoding"); encoding != "identity" {
+			t.Errorf("Expected the content to be requested unencoded, got %q", encoding)
+		}
+		w.Header().Set("ETag", etag(call))
+		if call == 1 {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			_, _ = w.Write(content[:len(content)/3])
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(server.Close)
+	return server, content, &calls
+}
+
+func TestDownloadInto(t *testing.T) {
+	server, content, calls := flakyContent(t, func(int64) string { return `"v1"` })
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "download"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	c := reqctl.Request(context.Background(), req).SetSimpleRetry(time.Millisecond, 2)
+	n, err := reqctl.DownloadInto(&c, f)
+	if err != nil || n != int64(len(content)) {
+		t.Fatalf("Expected the download to resume, got %d bytes, %v", n, err)
+	}
+	if got, _ := os.ReadFile(f.Name()); !bytes.Equal(got, content) {
+		t.Errorf("Expected the downloaded content to match, got %d bytes", len(got))
+	}
+	if atomic.LoadInt64(calls) != 2 {
+		t.Errorf("Expected a single resumed request, got %d calls", atomic.LoadInt64(calls))
+	}
+}
+
+func TestDownloadIntoContentChanged(t *testing.T) {
+	server, _, _ := flakyContent(t, func(call int64) string { return `"v` + strconv.FormatInt(call, 10) + `"` })
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "download"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	c := reqctl.Request(context.Background(), req).SetSimpleRetry(time.Millisecond, 2)
+	if _, err := reqctl.DownloadInto(&c, f); !errors.Is(err, reqctl.ErrContentChanged) {
+		t.Errorf("Expected the changed content to be detected, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package reqctl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+)
+
+// maxDumpBodyBytes bounds the bytes of the request & response bodies written by the debug dump
+const maxDumpBodyBytes = 4 << 10
+
+// debugDump writes the failed attempts to a writer, serializing the dumps of parallel calls
+type debugDump struct {
+	mu          sync.Mutex
+	w           io.Writer
+	includeBody bool
+}
+
+// SetDebugDump writes the request & response of every failed attempt to w, as per httputil.DumpRequestOut &
+// httputil.DumpResponse. Attempts are deemed failed as per the retry checker. Bodies are included when asked,
+// truncated to 4KB, and sensitive header values are redacted. The response body remains readable in full.
+func (c Controller) SetDebugDump(w io.Writer, includeBody bool) Controller {
+	if w == nil {
+		c.config.dump = nil
+	} else {
+		c.config.dump = &debugDump{w: w, includeBody: includeBody}
+	}
+	return c
+}
+
+// dumpAttempt writes the failed attempt, if the debug dump is enabled
+func (c *Controller) dumpAttempt(attempt Attempt, req *http.Request, resp *http.Response, err error) {
+	d := c.config.dump
+	if d == nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- reqctl: failed attempt %d of %s ---\n", attempt.Seq, attempt.CorrelationID)
+
+	sent := req.Clone(req.Context())
+	sent.Body, sent.ContentLength = nil, 0
+	for _, name := range redactedHeaders {
+		if sent.Header.Get(name) != "" {
+			sent.Header.Set(name, redactedValue)
+		}
+	}
+	if out, dErr := httputil.DumpRequestOut(sent, false); dErr == nil {
+		buf.Write(out)
+	}
+	if d.includeBody && req.GetBody != nil {
+		if body, bErr := req.GetBody(); bErr == nil {
+			writeBodyPreview(&buf, body)
+			body.Close()
+		}
+	}
+
+	if resp != nil {
+		if out, dErr := httputil.DumpResponse(resp, false); dErr == nil {
+			buf.Write(out)
+		}
+		if d.includeBody && resp.Body != nil {
+			// The previewed bytes are replayed ahead of the rest of the body
+			var preview bytes.Buffer
+			_, _ = io.CopyN(&preview, resp.Body, maxDumpBodyBytes+1)
+			writeBodyPreview(&buf, bytes.NewReader(preview.Bytes()))
+			resp.Body = &teeBody{Reader: io.MultiReader(&preview, resp.Body), Closer: resp.Body}
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(&buf, "error: %v\n", err)
+	}
+	buf.WriteString("\n")
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, _ = d.w.Write(buf.Bytes())
+}
+
+// writeBodyPreview writes the body, truncated to maxDumpBodyBytes
+func writeBodyPreview(buf *bytes.Buffer, body io.Reader) {
+	n, _ := io.CopyN(buf, body, maxDumpBodyBytes)
+	if n == maxDumpBodyBytes {
+		if extra, _ := io.CopyN(io.Discard, body, 1); extra > 0 {
+			buf.WriteString("\n[truncated]")
+		}
+	}
+	buf.WriteString("\n")
+}
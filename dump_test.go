@@ -0,0 +1,73 @@
+package reqctl_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestDebugDump(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls++; calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(strings.Repeat("x", 5000)))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var dump strings.Builder
+	request, _ := http.NewRequest("PUT", server.URL, strings.NewReader("payload"))
+	request.Header.Set("Authorization", "Bearer secret")
+	resp, err := reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(0, 1, reqctl.RetryOnStatus(503)).
+		SetDebugDump(&dump, true).
+		SetClient(server.Client()).
+		Do()
+	if err != nil {
+		t.Fatalf("Obtained error: %v", err)
+	}
+	resp.Body.Close()
+
+	out := dump.String()
+	for _, expected := range []string{"failed attempt 1 of", "PUT / HTTP/1.1", "payload", "503 Service Unavailable", "[truncated]"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected the dump to contain %q, got:\n%s", expected, out)
+		}
+	}
+	if strings.Contains(out, "secret") || strings.Contains(out, "failed attempt 2") {
+		t.Errorf("Expected only the failed attempt with redacted headers, got:\n%s", out)
+	}
+}
+
+func TestDebugDumpKeepsBody(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: 503, Body: io.NopCloser(strings.NewReader("unavailable")), Request: r}, nil
+		}),
+	}
+
+	var dump strings.Builder
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	resp, err := reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(0, 0, reqctl.RetryOnStatus(503)).
+		SetDebugDump(&dump, true).
+		SetClient(client).
+		Do()
+	if err != nil {
+		t.Fatalf("Obtained error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "unavailable" || !strings.Contains(dump.String(), "unavailable") {
+		t.Errorf("Expected the body to be dumped & readable, got %q", body)
+	}
+}
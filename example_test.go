@@ -2,16 +2,22 @@ package reqctl_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/RohanPoojary/reqctl"
+	"github.com/RohanPoojary/reqctl/reqctltest"
 )
 
 func Example_retry() {
+	// Start a server failing the first 2 attempts
+	server := reqctltest.NewServer(reqctltest.FailThenSucceed(2, http.StatusServiceUnavailable)...)
+	defer server.Close()
+
 	// Create a new request
-	request, err := http.NewRequest("GET", "https://httpbin.org/status/200", nil)
+	request, err := http.NewRequest("GET", server.URL, nil)
 	if err != nil {
 		fmt.Printf("Error creating request: %v", err)
 		return
@@ -19,7 +25,7 @@ func Example_retry() {
 
 	// Create a new request controller
 	ctlr := reqctl.Request(context.TODO(), request).
-		SetExponentialRetry(100*time.Millisecond, 3)
+		SetExponentialRetryWithChecker(10*time.Millisecond, 3, reqctl.RetryOnStatus(http.StatusServiceUnavailable))
 
 	// Execute the request
 	httpResp, err := ctlr.Do()
@@ -34,8 +40,12 @@ func Example_retry() {
 }
 
 func Example_timeout() {
+	// Start a server taking 1 second to respond
+	server := reqctltest.NewServer(reqctltest.Step{Delay: time.Second})
+	defer server.Close()
+
 	// Create a new request
-	request, err := http.NewRequest("GET", "https://httpbin.org/delay/1", nil)
+	request, err := http.NewRequest("GET", server.URL, nil)
 	if err != nil {
 		fmt.Printf("Error creating request: %v", err)
 		return
@@ -47,16 +57,20 @@ func Example_timeout() {
 
 	// Request should fail as the api takes 1 second to respond
 	_, err = ctlr.Do()
-	fmt.Println("Request failed:", err)
+	fmt.Println("Request failed:", errors.Is(err, context.DeadlineExceeded))
 
 	// Output:
 	//
-	// Request failed: Get "https://httpbin.org/delay/1": context deadline exceeded
+	// Request failed: true
 }
 
 func Example_fastestFirst() {
+	// Start a server responding slowly to the first attempt only
+	server := reqctltest.NewServer(reqctltest.Step{Delay: time.Second}, reqctltest.Step{})
+	defer server.Close()
+
 	// Create a new request
-	request, err := http.NewRequest("GET", "https://httpbin.org/status/200", nil)
+	request, err := http.NewRequest("GET", server.URL, nil)
 	if err != nil {
 		fmt.Printf("Error creating request: %v", err)
 		return
@@ -79,8 +93,11 @@ func Example_fastestFirst() {
 }
 
 func Example_advanced() {
+	server := reqctltest.NewServer()
+	defer server.Close()
+
 	// Create a new request
-	request, err := http.NewRequest("GET", "https://httpbin.org/status/200", nil)
+	request, err := http.NewRequest("GET", server.URL, nil)
 	if err != nil {
 		fmt.Printf("Error creating request: %v", err)
 		return
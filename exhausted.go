@@ -0,0 +1,70 @@
+package reqctl
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrRetriesExhausted is matched by the errors of requests whose retries are exhausted, refer ExhaustedError
+var ErrRetriesExhausted = errors.New("reqctl: retries exhausted")
+
+// ExhaustedAction defines the outcome of a request whose retries are exhausted while the retry checker still asks
+// for a retry
+type ExhaustedAction string
+
+const (
+	// ReturnLastResponse returns the outcome of the last attempt as is, eg: a 503 response without error ( default )
+	ReturnLastResponse = ExhaustedAction("last_response")
+	// ReturnError closes the response of the last attempt & returns an ExhaustedError
+	ReturnError = ExhaustedAction("error")
+)
+
+// ExhaustedError is returned when the retries are exhausted, under the ReturnError action
+type ExhaustedError struct {
+	// Attempts is the number of attempts made
+	Attempts int
+	// StatusCode of the last response, 0 if no response was obtained
+	StatusCode int
+	// Err returned by the last attempt
+	Err error
+}
+
+// Error describes the outcome of the last attempt
+func (e *ExhaustedError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%v after %d attempts: %v", ErrRetriesExhausted, e.Attempts, e.Err)
+	}
+	return fmt.Sprintf("%v after %d attempts: status %d", ErrRetriesExhausted, e.Attempts, e.StatusCode)
+}
+
+// Is matches ErrRetriesExhausted
+func (e *ExhaustedError) Is(target error) bool {
+	return target == ErrRetriesExhausted
+}
+
+// Unwrap returns the error of the last attempt
+func (e *ExhaustedError) Unwrap() error {
+	return e.Err
+}
+
+// OnExhausted defines the outcome of the request once its retries are exhausted while the retry checker still
+// asks for a retry, including when the retries are stopped early by the elapsed time or connection limits.
+// The soft fail fallback takes precedence when configured.
+func (c Controller) OnExhausted(action ExhaustedAction) Controller {
+	c.config.onExhausted = action
+	return c
+}
+
+// exhausted returns the outcome of the request whose retries are exhausted
+func (c *Controller) exhausted(attempts int, resp *http.Response, err error) (*http.Response, error) {
+	if c.config.onExhausted != ReturnError {
+		return resp, err
+	}
+
+	res := &ExhaustedError{Attempts: attempts, Err: err}
+	if resp != nil {
+		res.StatusCode = resp.StatusCode
+	}
+	return nil, res
+}
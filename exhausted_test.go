@@ -0,0 +1,41 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestOnExhausted(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+	do := func(action reqctl.ExhaustedAction) (*http.Response, error) {
+		request, _ := http.NewRequest("GET", "http://localhost", nil)
+		return reqctl.Request(context.Background(), request).
+			SetSimpleRetryWithChecker(0, 2, reqctl.RetryOnStatus(503)).
+			OnExhausted(action).
+			SetClient(client).
+			Do()
+	}
+
+	resp, err := do(reqctl.ReturnLastResponse)
+	if err != nil || resp == nil || resp.StatusCode != 503 {
+		t.Errorf("Expected the last response, got %v, %v", resp, err)
+	} else {
+		resp.Body.Close()
+	}
+
+	resp, err = do(reqctl.ReturnError)
+	var exhausted *reqctl.ExhaustedError
+	if resp != nil || !errors.Is(err, reqctl.ErrRetriesExhausted) || !errors.As(err, &exhausted) {
+		t.Errorf("Expected the exhaustion error, got %v, %v", resp, err)
+	} else if exhausted.Attempts != 3 || exhausted.StatusCode != 503 {
+		t.Errorf("Expected 3 attempts ending with 503, got %+v", exhausted)
+	}
+}
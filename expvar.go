@@ -0,0 +1,44 @@
+package reqctl
+
+import "expvar"
+
+// processStats aggregates the stats of every request sent by the process
+var processStats = newStatsCollector()
+
+// expvarSnapshot is the document published by PublishExpvar
+type expvarSnapshot struct {
+	Total     Stats                `json:"total"`
+	Policies  map[string]Stats     `json:"policies"`
+	Hosts     map[string]HostStats `json:"hosts"`
+	Transport PoolStats            `json:"transport"`
+}
+
+// PublishExpvar publishes the stats under name in expvar, hence under /debug/vars: the totals across every
+// request of the process, the stats of the policies registered via RegisterPolicy, the per host attempt stats
+// & the transport usage. Publishing again under a name already in use is a no-op.
+func PublishExpvar(name string) {
+	if expvar.Get(name) != nil {
+		return
+	}
+
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		doc := expvarSnapshot{
+			Total:     processStats.snapshot(),
+			Policies:  map[string]Stats{},
+			Hosts:     HostMetrics(),
+			Transport: TransportStats(),
+		}
+
+		debugState.mu.Lock()
+		policies := make(map[string]Policy, len(debugState.policies))
+		for name, p := range debugState.policies {
+			policies[name] = p
+		}
+		debugState.mu.Unlock()
+
+		for name, p := range policies {
+			doc.Policies[name] = p.Stats()
+		}
+		return doc
+	}))
+}
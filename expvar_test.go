@@ -0,0 +1,42 @@
+package reqctl_test
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	policy := reqctl.NewPolicy().WithClient(client)
+	reqctl.RegisterPolicy("expvar-test", policy)
+	reqctl.PublishExpvar("reqctl-test")
+	reqctl.PublishExpvar("reqctl-test")
+
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	resp, err := policy.Do(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Obtained error: %v", err)
+	}
+	resp.Body.Close()
+
+	var doc struct {
+		Total    reqctl.Stats            `json:"total"`
+		Policies map[string]reqctl.Stats `json:"policies"`
+	}
+	if err := json.Unmarshal([]byte(expvar.Get("reqctl-test").String()), &doc); err != nil {
+		t.Fatalf("Expected the published stats to be JSON: %v", err)
+	}
+	if doc.Total.Requests < 1 || doc.Policies["expvar-test"].Requests != 1 {
+		t.Errorf("Expected the request in the published stats, got %+v", doc)
+	}
+}
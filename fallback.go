@@ -0,0 +1,60 @@
+package reqctl
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// FallbackError is returned when the request failed against the primary & every fallback endpoint
+type FallbackError struct {
+	// Endpoints are the endpoints tried in order, starting with the primary
+	Endpoints []string
+	// Err is the error of the last endpoint
+	Err error
+}
+
+// Error describes the endpoints tried & the last error
+func (e *FallbackError) Error() string {
+	return fmt.Sprintf("reqctl: request failed against %s: %v", strings.Join(e.Endpoints, ", "), e.Err)
+}
+
+// Unwrap returns the error of the last endpoint
+func (e *FallbackError) Unwrap() error {
+	return e.Err
+}
+
+// SetFallbackEndpoints replays the request against the endpoints in order, under their scheme, host & path prefix,
+// once the attempts against the previous one are exhausted. Every endpoint gets the full retry strategy, and an
+// outcome is deemed failed as per the retry checker. Requests whose body cannot be replayed are not replayed.
+func (c Controller) SetFallbackEndpoints(endpoints ...*url.URL) Controller {
+	c.config.fallbacks = append([]*url.URL(nil), endpoints...)
+	return c
+}
+
+// executeWithFallback executes the request against the primary endpoint, then against the fallbacks on failure
+func (c *Controller) executeWithFallback(client *http.Client, exec *execution) (*http.Response, error) {
+	resp, err := c.executeWithRefresh(client, exec)
+	if len(c.config.fallbacks) == 0 {
+		return resp, err
+	}
+
+	tried := []string{(&url.URL{Scheme: c.req.URL.Scheme, Host: c.req.URL.Host}).String()}
+	for _, endpoint := range c.config.fallbacks {
+		if !c.failed(resp, err) || !isReplayable(c.req) || c.ctx.Err() != nil {
+			break
+		}
+		closeBody(resp)
+
+		next := *c
+		next.req = rebase(c.req, endpoint)
+		resp, err = next.executeWithRefresh(client, exec)
+		tried = append(tried, endpoint.String())
+	}
+
+	if err != nil && len(tried) > 1 {
+		err = &FallbackError{Endpoints: tried, Err: err}
+	}
+	return resp, err
+}
@@ -0,0 +1,58 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestFallbackEndpoints(t *testing.T) {
+	primaryCalls := 0
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryCalls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	down.Close()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer healthy.Close()
+
+	downURL, _ := url.Parse(down.URL)
+	healthyURL, _ := url.Parse(healthy.URL)
+
+	checker := func(resp *http.Response, err error) bool {
+		return err != nil || resp.StatusCode >= 500
+	}
+	request, _ := http.NewRequest("GET", primary.URL+"/items", nil)
+	ctrl := reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(time.Millisecond, 1, checker)
+
+	resp, err := ctrl.SetFallbackEndpoints(downURL, healthyURL).Do()
+	if err != nil {
+		t.Fatalf("Obtained error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != "/items" || primaryCalls != 2 {
+		t.Errorf("Expected the healthy fallback after retrying the primary, got %q after %d calls", body, primaryCalls)
+	}
+
+	_, err = ctrl.SetFallbackEndpoints(downURL).Do()
+	var fallbackErr *reqctl.FallbackError
+	if !errors.As(err, &fallbackErr) || len(fallbackErr.Endpoints) != 2 || fallbackErr.Endpoints[1] != down.URL {
+		t.Errorf("Expected the tried endpoints in the error, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package reqctl
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// GroupResult is the outcome of a controller executed by a group
+type GroupResult struct {
+	// Index is the position in which the controller was added to the group
+	Index    int
+	Response *http.Response
+	Err      error
+}
+
+// Group executes many controllers with bounded concurrency, delivering the results as they complete
+type Group struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	sem     chan struct{}
+	results chan GroupResult
+
+	mu      sync.Mutex
+	added   int
+	pending int
+	closed  bool
+}
+
+// NewGroup creates a group whose controllers are cancelled along with ctx.
+// At most maxConcurrent controllers execute at once, a non positive value is unbounded.
+func NewGroup(ctx context.Context, maxConcurrent int) *Group {
+	gCtx, cancel := context.WithCancel(ctx)
+	g := &Group{
+		ctx:     gCtx,
+		cancel:  cancel,
+		results: make(chan GroupResult, 16),
+	}
+
+	if maxConcurrent > 0 {
+		g.sem = make(chan struct{}, maxConcurrent)
+	}
+	return g
+}
+
+// Add schedules the controller for execution & returns its index. All controllers should be added
+// before consuming the results.
+func (g *Group) Add(c Controller) int {
+	g.mu.Lock()
+	idx := g.added
+	g.added++
+	g.pending++
+	g.mu.Unlock()
+
+	go g.run(idx, c)
+	return idx
+}
+
+// run executes the controller once a concurrency slot is available
+func (g *Group) run(idx int, c Controller) {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+			defer func() { <-g.sem }()
+		case <-g.ctx.Done():
+			g.results <- GroupResult{Index: idx, Err: g.ctx.Err()}
+			return
+		}
+	}
+
+	res := runBound(g.ctx, c)
+	g.results <- GroupResult{Index: idx, Response: res.Response, Err: res.Err}
+}
+
+// Next blocks until the next controller completes, returning false once every added controller is delivered
+func (g *Group) Next() (GroupResult, bool) {
+	g.mu.Lock()
+	if g.pending == 0 || g.closed {
+		g.mu.Unlock()
+		return GroupResult{}, false
+	}
+	g.pending--
+	last := g.pending == 0
+	g.mu.Unlock()
+
+	res := <-g.results
+	if last {
+		// Every controller has completed, hence the group context can be released
+		g.cancel()
+	}
+	return res, true
+}
+
+// Close cancels the controllers in flight, closing the bodies of the results which are not yet delivered
+func (g *Group) Close() {
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		return
+	}
+	g.closed = true
+	pending := g.pending
+	g.pending = 0
+	g.mu.Unlock()
+
+	g.cancel()
+	go func() {
+		for i := 0; i < pending; i++ {
+			res := <-g.results
+			closeBody(res.Response)
+		}
+	}()
+}
@@ -0,0 +1,26 @@
+//go:build go1.23
+
+package reqctl
+
+import (
+	"iter"
+	"net/http"
+)
+
+// Results returns an iterator over the outcomes of the group as they complete.
+// Exiting the loop early cancels the controllers still in flight.
+func (g *Group) Results() iter.Seq2[*http.Response, error] {
+	return func(yield func(*http.Response, error) bool) {
+		for {
+			res, ok := g.Next()
+			if !ok {
+				return
+			}
+
+			if !yield(res.Response, res.Err) {
+				g.Close()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+//go:build go1.23
+
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestGroupResultsEarlyExit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	slowErr := make(chan error, 1)
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			resp, err := http.DefaultTransport.RoundTrip(r)
+			if r.URL.Path == "/slow" {
+				slowErr <- err
+			}
+			return resp, err
+		}),
+	}
+
+	group := reqctl.NewGroup(context.Background(), 0)
+	for _, path := range []string{"/fast", "/slow"} {
+		request, _ := http.NewRequest("GET", server.URL+path, nil)
+		group.Add(reqctl.Request(context.Background(), request).SetClient(client))
+	}
+
+	count := 0
+	group.Results()(func(resp *http.Response, err error) bool {
+		count++
+		if err == nil {
+			resp.Body.Close()
+		}
+		return false
+	})
+
+	if count != 1 {
+		t.Errorf("Expected a single result before exiting, got %d", count)
+	}
+
+	select {
+	case err := <-slowErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the slow request to be cancelled, got %v", err)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Errorf("Expected the slow request to be cancelled on early exit")
+	}
+}
@@ -0,0 +1,65 @@
+package reqctl_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestGroup(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	group := reqctl.NewGroup(context.Background(), 2)
+	paths := []string{"/a", "/b", "/c", "/d", "/e"}
+	for _, path := range paths {
+		request, _ := http.NewRequest("GET", server.URL+path, nil)
+		group.Add(*reqctl.Request(context.Background(), request))
+	}
+
+	seen := map[int]string{}
+	for {
+		res, ok := group.Next()
+		if !ok {
+			break
+		}
+
+		if res.Err != nil {
+			t.Errorf("Obtained error: %v", res.Err)
+			continue
+		}
+
+		body, _ := io.ReadAll(res.Response.Body)
+		res.Response.Body.Close()
+		seen[res.Index] = string(body)
+	}
+
+	for i, path := range paths {
+		if seen[i] != path {
+			t.Errorf("Expected result %d to be %q, got %q", i, path, seen[i])
+		}
+	}
+
+	if maxInFlight > 2 {
+		t.Errorf("Expected at most 2 concurrent requests, got %d", maxInFlight)
+	}
+}
@@ -0,0 +1,78 @@
+package reqctl
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrNoParallelCall is returned when every scheduled parallel call is skipped without any being fired
+var ErrNoParallelCall = errors.New("reqctl: no parallel call fired")
+
+// SetParallelCallSchedule fires a parallel call at every offset of the schedule ( eg: 0ms, 50ms & 200ms ) from the
+// start of the request, until a call wins. A scheduled call is skipped while maxFanOut calls are already in flight,
+// a maxFanOut of 0 is unbounded. The acceptor of SetParallelCallFirstAcceptable, if set before, is retained.
+func (c Controller) SetParallelCallSchedule(maxFanOut int, schedule ...time.Duration) Controller {
+	var cfg asyncRetryConfig
+	if c.config.asyncCfg != nil {
+		cfg = *c.config.asyncCfg
+	}
+
+	cfg.Schedule = append([]time.Duration(nil), schedule...)
+	cfg.MaxFanOut = maxFanOut
+	c.config.asyncCfg = &cfg
+	return c
+}
+
+// delays returns the offsets at which the parallel calls to the host are fired
+func (a *asyncRetryConfig) delays(host string) []time.Duration {
+	if len(a.Schedule) > 0 {
+		return a.Schedule
+	}
+
+	delay := a.Delay
+	if a.Tracker != nil {
+		if d, ok := a.Tracker.Percentile(host, a.Percentile); ok {
+			delay = d
+		}
+	}
+
+	return []time.Duration{
+		0,     // The first request
+		delay, // Delayed request
+	}
+}
+
+// SetParallelEndpoints races the request across the endpoints ( eg: one base URL per region ), the i-th parallel call
+// being sent to the i-th endpoint under its scheme, host & path prefix. The first acceptable response wins and the
+// losers are cancelled. Without a parallel call configuration, a call is fired to every endpoint right away,
+// else the configured delays, schedule & acceptor are retained.
+func (c Controller) SetParallelEndpoints(endpoints ...*url.URL) Controller {
+	cfg := asyncRetryConfig{
+		Schedule: make([]time.Duration, len(endpoints)),
+	}
+	if c.config.asyncCfg != nil {
+		cfg = *c.config.asyncCfg
+	}
+
+	cfg.Endpoints = append([]*url.URL(nil), endpoints...)
+	c.config.asyncCfg = &cfg
+	return c
+}
+
+// rebase returns a copy of the request sent to the endpoint
+func rebase(req *http.Request, endpoint *url.URL) *http.Request {
+	res := req.Clone(req.Context())
+	res.URL.Scheme = endpoint.Scheme
+	res.URL.Host = endpoint.Host
+	if prefix := strings.TrimSuffix(endpoint.Path, "/"); prefix != "" {
+		res.URL.Path = prefix + res.URL.Path
+		res.URL.RawPath = ""
+	}
+
+	// The Host header follows the endpoint
+	res.Host = ""
+	return res
+}
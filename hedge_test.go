@@ -0,0 +1,112 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestParallelCallSchedule(t *testing.T) {
+	var calls int32
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			// Only the third call is fast
+			if atomic.AddInt32(&calls, 1) != 3 {
+				select {
+				case <-r.Context().Done():
+					return nil, r.Context().Err()
+				case <-time.After(200 * time.Millisecond):
+				}
+			}
+			return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	do := func(maxFanOut int) (int32, time.Duration) {
+		atomic.StoreInt32(&calls, 0)
+		request, _ := http.NewRequest("GET", "http://localhost", nil)
+		start := time.Now()
+		resp, err := reqctl.Request(context.Background(), request).
+			SetParallelCallSchedule(maxFanOut, 0, 10*time.Millisecond, 20*time.Millisecond).
+			SetClient(client).
+			Do()
+		if err != nil {
+			t.Errorf("Obtained error: %v", err)
+			return 0, 0
+		}
+		resp.Body.Close()
+		return atomic.LoadInt32(&calls), time.Since(start)
+	}
+
+	if n, elapsed := do(0); n != 3 || elapsed > 150*time.Millisecond {
+		t.Errorf("Expected the third scheduled call to win, got %d calls in %v", n, elapsed)
+	}
+
+	if n, elapsed := do(2); n != 2 || elapsed < 150*time.Millisecond {
+		t.Errorf("Expected the fan out to skip the third call, got %d calls in %v", n, elapsed)
+	}
+}
+
+func TestParallelCallScheduleContextDone(t *testing.T) {
+	var calls int32
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&calls, 1)
+			return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	// The context ends before the first offset, hence every call is skipped
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	_, err := reqctl.Request(ctx, request).
+		SetParallelCallSchedule(0, 50*time.Millisecond, 100*time.Millisecond).
+		SetClient(client).
+		Do()
+	if !errors.Is(err, context.DeadlineExceeded) || atomic.LoadInt32(&calls) != 0 {
+		t.Errorf("Expected the request to fail with its context, got %v after %d calls", err, calls)
+	}
+}
+
+func TestParallelEndpoints(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer slow.Close()
+
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer fast.Close()
+
+	slowURL, _ := url.Parse(slow.URL)
+	fastURL, _ := url.Parse(fast.URL + "/eu")
+
+	request, _ := http.NewRequest("GET", slow.URL+"/items", nil)
+	start := time.Now()
+	resp, err := reqctl.Request(context.Background(), request).
+		SetParallelEndpoints(slowURL, fastURL).
+		Do()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "/eu/items" || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Expected the fast endpoint to win under its path prefix, got %q", body)
+	}
+}
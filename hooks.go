@@ -0,0 +1,67 @@
+package reqctl
+
+import (
+	"net/http"
+	"time"
+)
+
+// Hooks observe the attempts of a logical request, hooks left nil are skipped. They are called synchronously from
+// the goroutine executing the attempt, hence parallel calls may invoke them concurrently.
+type Hooks struct {
+	// OnAttemptStart is called right before the attempt is sent, with the request as sent
+	OnAttemptStart func(attempt Attempt, req *http.Request)
+	// OnAttemptDone is called once the attempt obtains its response headers or fails
+	OnAttemptDone func(rec AttemptRecord)
+	// OnRetryScheduled is called before waiting for a retry, with its 1 based number, the reason of the retry & the
+	// outcome being retried
+	OnRetryScheduled func(retry int, reason RetryReason, wait time.Duration, resp *http.Response, err error)
+	// OnGiveUp is called when the retries stop before a successful outcome, with the attempts made & the outcome
+	// returned. It is not called for outcomes which the retry checker does not ask to retry.
+	OnGiveUp func(attempts int, resp *http.Response, err error)
+}
+
+// SetHooks observes the attempts of the request with the hooks, replacing the hooks set previously
+func (c Controller) SetHooks(hooks Hooks) Controller {
+	c.config.hooks = hooks
+	return c
+}
+
+// attemptStarted notifies the start of the attempt
+func (c *Controller) attemptStarted(attempt Attempt, req *http.Request) {
+	if hook := c.config.hooks.OnAttemptStart; hook != nil {
+		hook(attempt, req)
+	}
+}
+
+// attemptDone registers & accounts the completed attempt, then notifies it
+func (c *Controller) attemptDone(exec *execution, rec AttemptRecord) {
+	exec.record(rec)
+	c.config.stats.recordAttempt(rec.Attempt)
+	processStats.recordAttempt(rec.Attempt)
+	if hook := c.config.hooks.OnAttemptDone; hook != nil {
+		hook(rec)
+	}
+	if c.config.logger != nil {
+		c.config.logger.attemptDone(c, rec)
+	}
+}
+
+// retryScheduled notifies the retry about to be waited for
+func (c *Controller) retryScheduled(retry int, reason RetryReason, wait time.Duration, resp *http.Response, err error) {
+	if hook := c.config.hooks.OnRetryScheduled; hook != nil {
+		hook(retry, reason, wait, resp, err)
+	}
+	if c.config.logger != nil {
+		c.config.logger.retryScheduled(c, retry, reason, wait, resp, err)
+	}
+}
+
+// gaveUp notifies the retries stopping before a successful outcome
+func (c *Controller) gaveUp(attempts int, resp *http.Response, err error) {
+	if hook := c.config.hooks.OnGiveUp; hook != nil {
+		hook(attempts, resp, err)
+	}
+	if c.config.logger != nil {
+		c.config.logger.gaveUp(c, attempts, resp, err)
+	}
+}
@@ -0,0 +1,52 @@
+package reqctl_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestHooks(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	var events []string
+	hooks := reqctl.Hooks{
+		OnAttemptStart: func(attempt reqctl.Attempt, req *http.Request) {
+			events = append(events, fmt.Sprintf("start %d", attempt.Seq))
+		},
+		OnAttemptDone: func(rec reqctl.AttemptRecord) {
+			events = append(events, fmt.Sprintf("done %d %d", rec.Seq, rec.StatusCode))
+		},
+		OnRetryScheduled: func(retry int, reason reqctl.RetryReason, wait time.Duration, resp *http.Response, err error) {
+			events = append(events, fmt.Sprintf("retry %d %s %s %d", retry, reason, wait, resp.StatusCode))
+		},
+		OnGiveUp: func(attempts int, resp *http.Response, err error) {
+			events = append(events, fmt.Sprintf("give up %d %d", attempts, resp.StatusCode))
+		},
+	}
+
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	_, _ = reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(time.Millisecond, 2, reqctl.RetryOnStatus(503)).
+		SetHooks(hooks).
+		SetClient(client).
+		Do()
+
+	expected := []string{
+		"start 1", "done 1 503", "retry 1 server_error 1ms 503",
+		"start 2", "done 2 503", "retry 2 server_error 1ms 503",
+		"start 3", "done 3 503", "give up 3 503",
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected the events %v, got %v", expected, events)
+	}
+}
@@ -0,0 +1,64 @@
+package reqctl
+
+import "net/http"
+
+// IdempotencyKeyHeader is the conventional header carrying the idempotency key of a request
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// RequestPredicate decides whether a feature applies to the request
+type RequestPredicate func(req *http.Request) bool
+
+// IsIdempotent reports whether the request can safely be sent more than once,
+// ie: it uses an idempotent method or carries an Idempotency-Key header.
+func IsIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get(IdempotencyKeyHeader) != ""
+}
+
+// SetParallelCallPredicate restricts parallel calls to the requests matching the predicate, eg: IsIdempotent.
+// Other requests are sent without a parallel call, so that parallel calls can be enabled fleet wide safely.
+func (c Controller) SetParallelCallPredicate(predicate RequestPredicate) Controller {
+	c.config.hedgePredicate = predicate
+	return c
+}
+
+// SetRetryNonIdempotent allows the automatic retry of requests which are not idempotent, eg: POST & PATCH without
+// an Idempotency-Key header. By default such requests are sent once irrespective of the retry strategy,
+// so that enabling retries does not cause duplicate writes.
+func (c Controller) SetRetryNonIdempotent(allow bool) Controller {
+	c.config.retryNonIdempotent = allow
+	return c
+}
+
+// SetIdempotencyKey attaches a key generated per logical request to every attempt under the header, so that servers
+// supporting idempotency keys can deduplicate the retries & parallel calls. The key is the correlation ID of the
+// request, and the key already carried by the request is kept. An empty header uses IdempotencyKeyHeader.
+// Requests carrying the key are retried like idempotent ones.
+func (c Controller) SetIdempotencyKey(header string) Controller {
+	if header == "" {
+		header = IdempotencyKeyHeader
+	}
+	c.config.idempotencyHeader = header
+	return c
+}
+
+// withIdempotencyKey returns the controller for the request carrying its idempotency key, if configured
+func (c *Controller) withIdempotencyKey(exec *execution) *Controller {
+	header := c.config.idempotencyHeader
+	if header == "" || c.req.Header.Get(header) != "" {
+		return c
+	}
+
+	next := *c
+	next.req = c.req.Clone(c.ctx)
+	next.req.Header.Set(header, exec.correlationID)
+	return &next
+}
+
+// retryAllowed reports whether the request may be retried automatically
+func (c *Controller) retryAllowed() bool {
+	return c.config.retryNonIdempotent || c.config.idempotencyHeader != "" || IsIdempotent(c.req)
+}
@@ -0,0 +1,135 @@
+package reqctl_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestParallelCallPredicate(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(30 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	policy := reqctl.NewPolicy().
+		WithParallelCallWithDelay(5 * time.Millisecond).
+		WithParallelCallPredicate(reqctl.IsIdempotent)
+
+	do := func(req *http.Request) int32 {
+		atomic.StoreInt32(&calls, 0)
+		resp, err := policy.Do(context.Background(), req)
+		if err != nil {
+			t.Errorf("Obtained error: %v", err)
+			return 0
+		}
+		resp.Body.Close()
+
+		// Wait for the losing call to reach the server
+		time.Sleep(50 * time.Millisecond)
+		return atomic.LoadInt32(&calls)
+	}
+
+	post, _ := http.NewRequest("POST", server.URL, strings.NewReader("payload"))
+	if n := do(post); n != 1 {
+		t.Errorf("Expected no parallel call for POST, got %d calls", n)
+	}
+
+	keyed, _ := http.NewRequest("POST", server.URL, strings.NewReader("payload"))
+	keyed.Header.Set(reqctl.IdempotencyKeyHeader, "key")
+	if n := do(keyed); n != 2 {
+		t.Errorf("Expected a parallel call for POST with idempotency key, got %d calls", n)
+	}
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	var calls int32
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&calls, 1)
+			return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	policy := reqctl.NewPolicy().
+		WithSimpleRetryWithChecker(0, 2, reqctl.RetryOnStatus(503)).
+		WithClient(client)
+
+	do := func(p reqctl.Policy, req *http.Request) int32 {
+		atomic.StoreInt32(&calls, 0)
+		resp, err := p.Do(context.Background(), req)
+		if err != nil {
+			t.Errorf("Obtained error: %v", err)
+			return 0
+		}
+		resp.Body.Close()
+		return atomic.LoadInt32(&calls)
+	}
+
+	post, _ := http.NewRequest("POST", "http://localhost", strings.NewReader("payload"))
+	if n := do(policy, post); n != 1 {
+		t.Errorf("Expected POST to be sent once, got %d calls", n)
+	}
+
+	keyed, _ := http.NewRequest("POST", "http://localhost", strings.NewReader("payload"))
+	keyed.Header.Set(reqctl.IdempotencyKeyHeader, "key")
+	if n := do(policy, keyed); n != 3 {
+		t.Errorf("Expected POST with idempotency key to be retried, got %d calls", n)
+	}
+
+	if n := do(policy.WithRetryNonIdempotent(true), post); n != 3 {
+		t.Errorf("Expected POST to be retried once allowed, got %d calls", n)
+	}
+
+	put, _ := http.NewRequest("PUT", "http://localhost", strings.NewReader("payload"))
+	if n := do(policy, put); n != 3 {
+		t.Errorf("Expected PUT to be retried, got %d calls", n)
+	}
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	var keys []string
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			keys = append(keys, r.Header.Get("X-Request-Key"))
+			return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	policy := reqctl.NewPolicy().
+		WithSimpleRetryWithChecker(0, 2, reqctl.RetryOnStatus(503)).
+		WithIdempotencyKey("X-Request-Key")
+	do := func(req *http.Request) {
+		keys = nil
+		_, _ = policy.Request(context.Background(), req).SetClient(client).Do()
+	}
+
+	post, _ := http.NewRequest("POST", "http://localhost", strings.NewReader("payload"))
+	do(post)
+	if len(keys) != 3 || keys[0] == "" || keys[1] != keys[0] || keys[2] != keys[0] {
+		t.Errorf("Expected every retry of the POST to carry the same key, got %v", keys)
+	}
+	first := keys[0]
+
+	post, _ = http.NewRequest("POST", "http://localhost", strings.NewReader("payload"))
+	do(post)
+	if len(keys) == 0 || keys[0] == first {
+		t.Errorf("Expected a new key for another logical request, got %v", keys)
+	}
+
+	post, _ = http.NewRequest("POST", "http://localhost", strings.NewReader("payload"))
+	post.Header.Set("X-Request-Key", "supplied")
+	do(post)
+	if len(keys) == 0 || keys[0] != "supplied" {
+		t.Errorf("Expected the supplied key to be kept, got %v", keys)
+	}
+}
@@ -0,0 +1,60 @@
+package reqctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxErrorBodyBytes bounds the body retained by a StatusError
+const maxErrorBodyBytes = 4 << 10
+
+// StatusError is returned by the decoding helpers for responses whose status is not a success
+type StatusError struct {
+	StatusCode int
+	// Body is the start of the response body, up to 4KB
+	Body []byte
+}
+
+// Error describes the status of the response
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("reqctl: unexpected status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// checkStatus returns a *StatusError for responses whose status is not within success, any 2xx if empty.
+// The body of rejected responses is closed.
+func checkStatus(resp *http.Response, success []int) error {
+	ok := resp.StatusCode/100 == 2 && len(success) == 0
+	for _, status := range success {
+		ok = ok || resp.StatusCode == status
+	}
+	if ok {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+	closeBody(resp)
+	return &StatusError{StatusCode: resp.StatusCode, Body: body}
+}
+
+// DoJSON executes the request & decodes the JSON body of the response into T, once its status is checked to be
+// within success ( any 2xx if empty ). The body is closed, the response being returned for its status & headers.
+func DoJSON[T any](c *Controller, success ...int) (T, *http.Response, error) {
+	var res T
+	resp, err := c.DoResult().consume(func(resp *http.Response) error {
+		if err := checkStatus(resp, success); err != nil {
+			return err
+		}
+		defer closeBody(resp)
+
+		// A re-run decodes into a fresh value, discarding the fields set by the failed body
+		var v T
+		if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+			return fmt.Errorf("reqctl: decoding response: %w", err)
+		}
+		res = v
+		return nil
+	})
+	return res, resp, err
+}
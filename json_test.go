@@ -0,0 +1,55 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestDoJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/item":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id": 7, "name": "widget"}`))
+		case "/created":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": 8}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": "not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	request := func(path string) *reqctl.Controller {
+		req, _ := http.NewRequest("GET", server.URL+path, nil)
+		return reqctl.Request(context.Background(), req)
+	}
+
+	got, resp, err := reqctl.DoJSON[item](request("/item"))
+	if err != nil || got.ID != 7 || got.Name != "widget" || resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected the decoded item, got %+v, %v", got, err)
+	}
+
+	if _, _, err := reqctl.DoJSON[item](request("/created"), http.StatusOK); err == nil {
+		t.Errorf("Expected 201 to be rejected when only 200 is a success")
+	}
+
+	var statusErr *reqctl.StatusError
+	_, resp, err = reqctl.DoJSON[item](request("/missing"))
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != 404 || string(statusErr.Body) != `{"error": "not found"}` {
+		t.Errorf("Expected the status error with its body, got %v", err)
+	}
+	if resp == nil || resp.StatusCode != 404 {
+		t.Errorf("Expected the rejected response to be returned, got %v", resp)
+	}
+}
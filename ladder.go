@@ -0,0 +1,110 @@
+package reqctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Step is a single way of obtaining the response, used as a rung of a degradation ladder
+type Step func(ctx context.Context) (*http.Response, error)
+
+// rung is a step of the ladder along with its time budget
+type rung struct {
+	step   Step
+	budget time.Duration
+}
+
+// Ladder attempts its steps in order until one of them produces an acceptable response,
+// eg: primary with parallel calls, then a fallback endpoint with a single attempt, then a stale cached response.
+type Ladder struct {
+	budget time.Duration
+	accept func(*http.Response, error) bool
+	rungs  []rung
+}
+
+// NewLadder creates an empty ladder, whose steps together may take at most budget. A zero budget is unbounded.
+func NewLadder(budget time.Duration) Ladder {
+	return Ladder{
+		budget: budget,
+	}
+}
+
+// Then appends a step to the ladder, bounded by its own budget & what remains of the ladder budget.
+// A zero budget bounds the step only by the ladder budget.
+func (l Ladder) Then(budget time.Duration, step Step) Ladder {
+	rungs := make([]rung, len(l.rungs), len(l.rungs)+1)
+	copy(rungs, l.rungs)
+
+	l.rungs = append(rungs, rung{step: step, budget: budget})
+	return l
+}
+
+// SetAcceptor sets the function deciding whether a step succeeded, by default any response below 500 without error
+func (l Ladder) SetAcceptor(accept func(*http.Response, error) bool) Ladder {
+	l.accept = accept
+	return l
+}
+
+// Do executes the steps in order, returning the first accepted response or else the outcome of the last step
+func (l Ladder) Do(ctx context.Context) (*http.Response, error) {
+	if len(l.rungs) == 0 {
+		return nil, errors.New("reqctl: ladder has no steps")
+	}
+
+	// The ladder budget is released along with the body of the returned response
+	cancel := context.CancelFunc(func() {})
+	if l.budget > 0 {
+		ctx, cancel = context.WithTimeout(ctx, l.budget)
+	}
+
+	accept := l.accept
+	if accept == nil {
+		accept = func(resp *http.Response, err error) bool {
+			return err == nil && resp != nil && resp.StatusCode < 500
+		}
+	}
+
+	last := len(l.rungs) - 1
+	for _, r := range l.rungs[:last] {
+		resp, err := l.doStep(ctx, r)
+		if accept(resp, err) {
+			return withCancel(resp, cancel), err
+		}
+		closeBody(resp)
+	}
+
+	resp, err := l.doStep(ctx, l.rungs[last])
+	if err != nil {
+		cancel()
+		return resp, fmt.Errorf("reqctl: all %d ladder steps failed: %w", len(l.rungs), err)
+	}
+
+	return withCancel(resp, cancel), nil
+}
+
+// doStep executes a step within its budget. The step context is released once the response body is closed.
+func (l Ladder) doStep(ctx context.Context, r rung) (*http.Response, error) {
+	if r.budget <= 0 {
+		return r.step(ctx)
+	}
+
+	sCtx, cancel := context.WithTimeout(ctx, r.budget)
+	resp, err := r.step(sCtx)
+	if err != nil {
+		cancel()
+		return resp, err
+	}
+
+	return withCancel(resp, cancel), nil
+}
+
+// Step returns the controller as a ladder step, executed under the context given by the ladder
+func (c Controller) Step() Step {
+	return func(ctx context.Context) (*http.Response, error) {
+		c.ctx = ctx
+		return c.Do()
+	}
+}
@@ -0,0 +1,63 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestLadder(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer primary.Close()
+
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte("fallback"))
+	}))
+	defer fallback.Close()
+
+	primaryReq, _ := http.NewRequest("GET", primary.URL, nil)
+	fallbackReq, _ := http.NewRequest("GET", fallback.URL, nil)
+
+	stale := func(ctx context.Context) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("stale"))}, nil
+	}
+
+	// The fallback is too slow for its budget, hence the stale response is served
+	ladder := reqctl.NewLadder(time.Second).
+		Then(0, reqctl.Request(context.Background(), primaryReq).SetParallelCallWithDelay(10*time.Millisecond).Step()).
+		Then(20*time.Millisecond, reqctl.Request(context.Background(), fallbackReq).Step()).
+		Then(0, stale)
+
+	resp, err := ladder.Do(context.Background())
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "stale" {
+		t.Errorf("Expected stale response, got %q", body)
+	}
+}
+
+func TestLadderExhausted(t *testing.T) {
+	failing := func(ctx context.Context) (*http.Response, error) {
+		return nil, errors.New("unavailable")
+	}
+
+	_, err := reqctl.NewLadder(0).Then(0, failing).Then(0, failing).Do(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "unavailable") {
+		t.Errorf("Expected error of the last step, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package reqctl
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// minLatencySamples is the number of samples a host needs before its percentiles are used
+const minLatencySamples = 10
+
+// LatencyTracker records the attempt durations of every host over a rolling window of samples,
+// so that the parallel call delay follows the upstream latency as it shifts.
+type LatencyTracker struct {
+	size int
+
+	mu    sync.Mutex
+	hosts map[string]*latencyWindow
+}
+
+// latencyWindow is the ring of the latest samples of a host
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+}
+
+// NewLatencyTracker creates a tracker keeping the latest samples of every host
+func NewLatencyTracker(samples int) *LatencyTracker {
+	if samples < minLatencySamples {
+		samples = minLatencySamples
+	}
+
+	return &LatencyTracker{
+		size:  samples,
+		hosts: map[string]*latencyWindow{},
+	}
+}
+
+// Record adds an attempt duration to the window of the host
+func (l *LatencyTracker) Record(host string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w, ok := l.hosts[host]
+	if !ok {
+		w = &latencyWindow{samples: make([]time.Duration, 0, l.size)}
+		l.hosts[host] = w
+	}
+
+	if len(w.samples) < l.size {
+		w.samples = append(w.samples, d)
+		return
+	}
+	w.samples[w.next] = d
+	w.next = (w.next + 1) % l.size
+}
+
+// Percentile returns the p-th percentile ( 0 < p <= 100 ) of the host durations,
+// false until the host has enough samples
+func (l *LatencyTracker) Percentile(host string, p float64) (time.Duration, bool) {
+	l.mu.Lock()
+	w, ok := l.hosts[host]
+	if !ok || len(w.samples) < minLatencySamples {
+		l.mu.Unlock()
+		return 0, false
+	}
+	samples := append([]time.Duration(nil), w.samples...)
+	l.mu.Unlock()
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	idx := int(math.Ceil(p/100*float64(len(samples)))) - 1
+	if idx < 0 {
+		idx = 0
+	} else if idx >= len(samples) {
+		idx = len(samples) - 1
+	}
+	return samples[idx], true
+}
+
+// SetAdaptiveParallelCall configures asynchronous retry, where the parallel call is fired once the attempt takes
+// longer than the percentile ( eg: 95 ) of the recent attempt durations of the host, as recorded by the tracker.
+// Every attempt of the controller with a response is recorded, the fallback delay is used until enough are. Attempts
+// cancelled or timed out are recorded at their elapsed time, the least their latency would have been, so that the
+// percentile does not only follow the attempts fast enough to complete. The acceptor of
+// SetParallelCallFirstAcceptable, if set before, is retained, whereas the schedule is replaced.
+func (c Controller) SetAdaptiveParallelCall(tracker *LatencyTracker, percentile float64, fallback time.Duration) Controller {
+	var cfg asyncRetryConfig
+	if c.config.asyncCfg != nil {
+		cfg = *c.config.asyncCfg
+	}
+
+	cfg.Delay, cfg.Tracker, cfg.Percentile, cfg.Schedule = fallback, tracker, percentile, nil
+	c.config.asyncCfg = &cfg
+	return c
+}
+
+// censored reports whether the attempt failed as it was cancelled or timed out, rather than on its own
+func censored(ctx context.Context, err error) bool {
+	return ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
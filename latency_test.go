@@ -0,0 +1,116 @@
+package reqctl_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestLatencyTracker(t *testing.T) {
+	tracker := reqctl.NewLatencyTracker(20)
+	if _, ok := tracker.Percentile("a", 95); ok {
+		t.Errorf("Expected no percentile without samples")
+	}
+
+	for i := 1; i <= 40; i++ {
+		tracker.Record("a", time.Duration(i)*time.Millisecond)
+	}
+
+	// Only the latest 20 samples remain, 21ms to 40ms
+	if p, ok := tracker.Percentile("a", 50); !ok || p != 30*time.Millisecond {
+		t.Errorf("Expected P50 of 30ms, got %v", p)
+	}
+	if p, _ := tracker.Percentile("a", 95); p != 39*time.Millisecond {
+		t.Errorf("Expected P95 of 39ms, got %v", p)
+	}
+}
+
+func TestAdaptiveParallelCall(t *testing.T) {
+	var calls int32
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(5 * time.Millisecond)
+			return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	tracker := reqctl.NewLatencyTracker(10)
+	for i := 0; i < 10; i++ {
+		tracker.Record("localhost", 100*time.Millisecond)
+	}
+
+	// The fallback would fire a parallel call right away, the observed latency does not
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	resp, err := reqctl.Request(context.Background(), request).
+		SetAdaptiveParallelCall(tracker, 95, time.Millisecond).
+		SetClient(client).
+		Do()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+	resp.Body.Close()
+
+	time.Sleep(20 * time.Millisecond)
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Expected the parallel call to wait for the P95, got %d calls", n)
+	}
+}
+
+func TestAdaptiveParallelCallCensored(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			<-r.Context().Done()
+			return nil, r.Context().Err()
+		}),
+	}
+
+	// The attempts timing out are recorded at their elapsed time, the upstream being at least as slow
+	tracker := reqctl.NewLatencyTracker(10)
+	for i := 0; i < 10; i++ {
+		request, _ := http.NewRequest("GET", "http://localhost", nil)
+		reqctl.Request(context.Background(), request).
+			SetAdaptiveParallelCall(tracker, 50, time.Hour).
+			SetTimeout(10 * time.Millisecond).
+			SetClient(client).
+			Do()
+	}
+	if p, ok := tracker.Percentile("localhost", 50); !ok || p < 10*time.Millisecond {
+		t.Errorf("Expected the timed out attempts to be recorded, got %v", p)
+	}
+}
+
+func TestAdaptiveParallelCallAcceptor(t *testing.T) {
+	var calls int32
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				return &http.Response{StatusCode: 500, Body: http.NoBody, Request: r}, nil
+			}
+			return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	accept := func(resp *http.Response, err error) bool {
+		return err == nil && resp.StatusCode < 500
+	}
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	resp, err := reqctl.Request(context.Background(), request).
+		SetParallelCallFirstAcceptable(time.Hour, accept).
+		SetAdaptiveParallelCall(reqctl.NewLatencyTracker(10), 95, time.Millisecond).
+		SetClient(client).
+		Do()
+	if err != nil {
+		t.Fatalf("Obtained error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Expected the acceptor to be retained, got %d", resp.StatusCode)
+	}
+}
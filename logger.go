@@ -0,0 +1,13 @@
+package reqctl
+
+import (
+	"net/http"
+	"time"
+)
+
+// attemptLogger emits the records of the attempts, implemented for log/slog where available
+type attemptLogger interface {
+	attemptDone(c *Controller, rec AttemptRecord)
+	retryScheduled(c *Controller, retry int, reason RetryReason, wait time.Duration, resp *http.Response, err error)
+	gaveUp(c *Controller, attempts int, resp *http.Response, err error)
+}
@@ -0,0 +1,94 @@
+//go:build go1.21
+
+package reqctl
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// SetLogger makes the controller emit a debug record for every completed attempt, and an info record
+// for every retry scheduled & for the retries stopping before a successful outcome
+func (c Controller) SetLogger(logger *slog.Logger) Controller {
+	if logger == nil {
+		c.config.logger = nil
+	} else {
+		c.config.logger = slogLogger{logger: logger}
+	}
+	return c
+}
+
+// WithLogger emits records of the attempts, refer Controller.SetLogger
+func (p Policy) WithLogger(logger *slog.Logger) Policy {
+	p.template = p.template.SetLogger(logger)
+	return p
+}
+
+// slogLogger emits the attempt records via log/slog
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (l slogLogger) attemptDone(c *Controller, rec AttemptRecord) {
+	ctx := c.ctx
+	if !l.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := append(requestAttrs(rec.Request),
+		slog.String("correlation_id", rec.CorrelationID),
+		slog.Int("attempt", rec.Seq),
+		slog.Bool("hedged", rec.Hedged),
+		slog.Duration("duration", rec.Duration),
+	)
+	if rec.Reason != ReasonNone {
+		attrs = append(attrs, slog.String("reason", string(rec.Reason)))
+	}
+	attrs = append(attrs, outcomeAttrs(rec.StatusCode, rec.Err)...)
+	l.logger.LogAttrs(ctx, slog.LevelDebug, "reqctl: attempt done", attrs...)
+}
+
+func (l slogLogger) retryScheduled(c *Controller, retry int, reason RetryReason, wait time.Duration, resp *http.Response, err error) {
+	attrs := append(requestAttrs(c.req),
+		slog.Int("retry", retry),
+		slog.String("reason", string(reason)),
+		slog.Duration("wait", wait),
+	)
+	attrs = append(attrs, outcomeAttrs(statusCode(resp), err)...)
+	l.logger.LogAttrs(c.ctx, slog.LevelInfo, "reqctl: retry scheduled", attrs...)
+}
+
+func (l slogLogger) gaveUp(c *Controller, attempts int, resp *http.Response, err error) {
+	attrs := append(requestAttrs(c.req), slog.Int("attempts", attempts))
+	attrs = append(attrs, outcomeAttrs(statusCode(resp), err)...)
+	l.logger.LogAttrs(c.ctx, slog.LevelInfo, "reqctl: retries stopped", attrs...)
+}
+
+// requestAttrs describes the request, without the URL credentials
+func requestAttrs(req *http.Request) []slog.Attr {
+	return []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.Redacted()),
+	}
+}
+
+// outcomeAttrs describes the outcome of an attempt
+func outcomeAttrs(status int, err error) []slog.Attr {
+	var attrs []slog.Attr
+	if status != 0 {
+		attrs = append(attrs, slog.Int("status", status))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	return attrs
+}
+
+// statusCode returns the status of the response, 0 if no response was obtained
+func statusCode(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
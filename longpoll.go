@@ -0,0 +1,57 @@
+package reqctl
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// minPollBackoff is the least wait after a failed poll, when the retry configuration does not provide one
+const minPollBackoff = time.Second
+
+// maxPollFailures bounds the consecutive failures the backoff grows with, the backoff staying at its highest then
+const maxPollFailures = 32
+
+// LongPoll re-issues the request after each response until ctx is done, eg: to receive from a queue or watch
+// a resource. Responses not deemed failed by the retry checker are handled by fn, the next request being issued
+// after the interval, right away if not positive. Failed polls, once retried as per the controller, back off as per
+// its retry configuration ( at least 1s ) before the next one, the backoff growing with up to 32 consecutive failures.
+// It returns the context error once ctx is done, or the first error of fn, the body being closed once handled.
+func LongPoll(ctx context.Context, c *Controller, interval time.Duration, fn func(resp *http.Response) error) error {
+	poll := *c
+	poll.ctx = ctx
+	clock := c.clock()
+
+	var failures int
+	var backoff time.Duration
+	for {
+		poll.req = c.req.Clone(ctx)
+		resp, err := poll.Do()
+		if ctx.Err() != nil {
+			closeBody(resp)
+			return ctx.Err()
+		}
+
+		delay := interval
+		if err != nil || poll.failed(resp, nil) {
+			closeBody(resp)
+			if backoff = poll.backoff(failures, backoff); backoff < minPollBackoff {
+				backoff = minPollBackoff
+			}
+			if delay = backoff; failures < maxPollFailures {
+				failures++
+			}
+		} else {
+			err = fn(resp)
+			closeBody(resp)
+			if err != nil {
+				return err
+			}
+			failures, backoff = 0, 0
+		}
+
+		if err := clock.Sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+	"github.com/RohanPoojary/reqctl/reqctltest"
+)
+
+func TestLongPoll(t *testing.T) {
+	server := reqctltest.NewServer(
+		reqctltest.Step{Body: "a"},
+		reqctltest.Step{Status: http.StatusServiceUnavailable},
+		reqctltest.Step{Status: http.StatusServiceUnavailable},
+		reqctltest.Step{Body: "b"},
+		reqctltest.Step{Body: "c"},
+	)
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	c := reqctl.Request(context.Background(), req).
+		SetExponentialRetryWithChecker(2*time.Second, 0, reqctl.RetryOnStatus(503)).
+		SetClock(clock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var received []string
+	err := reqctl.LongPoll(ctx, &c, 100*time.Millisecond, func(resp *http.Response) error {
+		body, _ := io.ReadAll(resp.Body)
+		received = append(received, string(body))
+		if len(received) == 3 {
+			cancel()
+		}
+		return nil
+	})
+
+	if !errors.Is(err, context.Canceled) || !reflect.DeepEqual(received, []string{"a", "b", "c"}) {
+		t.Errorf("Expected the 3 bodies until cancelled, got %v, %v", received, err)
+	}
+	expected := []time.Duration{100 * time.Millisecond, 2 * time.Second, 4 * time.Second, 100 * time.Millisecond}
+	if !reflect.DeepEqual(clock.slept[:4], expected) {
+		t.Errorf("Expected the interval after responses & backoff after failures %v, got %v", expected, clock.slept)
+	}
+}
+
+func TestLongPollHandlerError(t *testing.T) {
+	server := reqctltest.NewServer()
+	defer server.Close()
+
+	failure := errors.New("stop")
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	err := reqctl.LongPoll(context.Background(), reqctl.Request(context.Background(), req), 0,
+		func(*http.Response) error { return failure })
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected the handler error, got %v", err)
+	}
+	server.AssertAttempts(t, 1)
+}
@@ -0,0 +1,73 @@
+package reqctl
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lruEntry is a value held by the LRU under its key
+type lruEntry[V any] struct {
+	key   string
+	value V
+}
+
+// lru holds values by key, evicting the least recently used beyond the max entries
+type lru[V any] struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+}
+
+// newLRU creates an empty LRU, unbounded if maxEntries is not positive
+func newLRU[V any](maxEntries int) *lru[V] {
+	return &lru[V]{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+	}
+}
+
+// get returns the value under the key, marking it as recently used
+func (l *lru[V]) get(key string) (V, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	l.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry[V]).value, true
+}
+
+// put stores the value under the key, replacing any previous one
+func (l *lru[V]) put(key string, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.entries[key]; ok {
+		elem.Value.(*lruEntry[V]).value = value
+		l.order.MoveToFront(elem)
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(&lruEntry[V]{key: key, value: value})
+	if l.maxEntries > 0 && l.order.Len() > l.maxEntries {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry[V]).key)
+	}
+}
+
+// remove deletes the value under the key
+func (l *lru[V]) remove(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.entries[key]; ok {
+		l.order.Remove(elem)
+		delete(l.entries, key)
+	}
+}
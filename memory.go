@@ -0,0 +1,116 @@
+package reqctl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrMemoryBudgetExceeded is returned when a body had to be buffered, but the memory budget is exhausted
+var ErrMemoryBudgetExceeded = errors.New("reqctl: memory budget for buffered bodies exceeded")
+
+// BudgetFallback defines the behavior when buffering a body would exceed the memory budget
+type BudgetFallback int
+
+const (
+	// FallbackStream skips buffering & streams the body, losing the features which need it buffered ( default )
+	FallbackStream = BudgetFallback(iota)
+	// FallbackShed fails the request with ErrMemoryBudgetExceeded
+	FallbackShed
+)
+
+// MemoryBudget bounds the total bytes buffered by controllers sharing it, across in-flight requests. It bounds the
+// request bodies buffered via SetBufferRequestBody & the response bodies buffered by the cache, the coalescer & the
+// conditional cache, until the response is closed. Responses of unknown length reserve the max body bytes of the
+// buffering feature while being read. The entries retained by the caches are bounded by the caches on their own.
+type MemoryBudget struct {
+	max      int64
+	used     int64
+	fallback BudgetFallback
+}
+
+// NewMemoryBudget creates a budget of maxBytes, applying the fallback when it is exhausted
+func NewMemoryBudget(maxBytes int64, fallback BudgetFallback) *MemoryBudget {
+	return &MemoryBudget{
+		max:      maxBytes,
+		fallback: fallback,
+	}
+}
+
+// Reserve claims n bytes of the budget, returning false if it would be exceeded
+func (m *MemoryBudget) Reserve(n int64) bool {
+	if m == nil {
+		return true
+	}
+
+	for {
+		used := atomic.LoadInt64(&m.used)
+		if used+n > m.max {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&m.used, used, used+n) {
+			return true
+		}
+	}
+}
+
+// Release returns n previously reserved bytes to the budget
+func (m *MemoryBudget) Release(n int64) {
+	if m != nil {
+		atomic.AddInt64(&m.used, -n)
+	}
+}
+
+// InUse returns the bytes currently reserved
+func (m *MemoryBudget) InUse() int64 {
+	if m == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&m.used)
+}
+
+// Fallback returns the behavior applied when the budget is exhausted
+func (m *MemoryBudget) Fallback() BudgetFallback {
+	if m == nil {
+		return FallbackStream
+	}
+	return m.fallback
+}
+
+// memBudgetCtxKey is the context key under which the memory budget of the attempt is stored
+type memBudgetCtxKey struct{}
+
+// withMemoryBudget attaches the budget to the context, if any
+func withMemoryBudget(ctx context.Context, budget *MemoryBudget) context.Context {
+	if budget == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, memBudgetCtxKey{}, budget)
+}
+
+// budgetFromContext returns the memory budget attached to the context, nil if absent
+func budgetFromContext(ctx context.Context) *MemoryBudget {
+	budget, _ := ctx.Value(memBudgetCtxKey{}).(*MemoryBudget)
+	return budget
+}
+
+// budgetedBody is a buffered response body, releasing its reservation of the budget once closed
+type budgetedBody struct {
+	*bytes.Reader
+	budget   *MemoryBudget
+	reserved int64
+	once     sync.Once
+}
+
+func (b *budgetedBody) Close() error {
+	b.once.Do(func() { b.budget.Release(b.reserved) })
+	return nil
+}
+
+// SetMemoryBudget bounds the bytes buffered by the controller's buffering features with the shared budget
+func (c Controller) SetMemoryBudget(budget *MemoryBudget) Controller {
+	c.config.memBudget = budget
+	return c
+}
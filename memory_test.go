@@ -0,0 +1,137 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestMemoryBudget(t *testing.T) {
+	budget := reqctl.NewMemoryBudget(100, reqctl.FallbackShed)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	reserved := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if budget.Reserve(10) {
+				mu.Lock()
+				reserved++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if reserved != 10 || budget.InUse() != 100 {
+		t.Errorf("Expected 10 reservations using 100 bytes, got %d using %d", reserved, budget.InUse())
+	}
+
+	budget.Release(30)
+	if !budget.Reserve(30) || budget.Reserve(1) {
+		t.Errorf("Expected released bytes to be reservable exactly once")
+	}
+
+	// A nil budget is unbounded
+	var unbounded *reqctl.MemoryBudget
+	if !unbounded.Reserve(1<<40) || unbounded.InUse() != 0 || unbounded.Fallback() != reqctl.FallbackStream {
+		t.Errorf("Expected a nil budget to be unbounded")
+	}
+}
+
+func TestMemoryBudgetResponses(t *testing.T) {
+	var calls, conditional int64
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		if r.Header.Get("If-None-Match") != "" {
+			atomic.AddInt64(&conditional, 1)
+		}
+		if r.URL.Path == "/held" {
+			<-release
+		}
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("payload"))
+	}))
+	defer server.Close()
+
+	// The budget is exhausted, hence the responses stream through instead of being buffered
+	budget := reqctl.NewMemoryBudget(100, reqctl.FallbackStream)
+	budget.Reserve(100)
+	get := func(policy reqctl.Policy, path string) reqctl.Result {
+		req, _ := http.NewRequest("GET", server.URL+path, nil)
+		res := policy.DoResult(context.Background(), req)
+		if body, err := res.String(); err != nil || body != "payload" {
+			t.Fatalf("Expected the body to stream through, got %q, %v", body, err)
+		}
+		return res
+	}
+
+	cached := reqctl.NewPolicy().WithCache(reqctl.NewCache(nil)).WithMemoryBudget(budget)
+	get(cached, "/cache")
+	if res := get(cached, "/cache"); res.FromCache || calls != 2 {
+		t.Errorf("Expected the response not to be stored, got %d calls", calls)
+	}
+
+	atomic.StoreInt64(&calls, 0)
+	validated := reqctl.NewPolicy().WithConditional(reqctl.NewConditionalCache(8)).WithMemoryBudget(budget)
+	get(validated, "/conditional")
+	get(validated, "/conditional")
+	if conditional != 0 {
+		t.Errorf("Expected the representation not to be stored, got %d conditional requests", conditional)
+	}
+
+	atomic.StoreInt64(&calls, 0)
+	coalesced := reqctl.NewPolicy().WithCoalescing(reqctl.NewCoalescer()).WithMemoryBudget(budget)
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			get(coalesced, "/held")
+		}()
+	}
+	for atomic.LoadInt64(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt64(&calls); n != 3 {
+		t.Errorf("Expected the waiting requests to be sent on their own, got %d calls", n)
+	}
+
+	// Buffered responses hold their reservation until closed
+	budget.Release(100)
+	req, _ := http.NewRequest("GET", server.URL+"/reserved", nil)
+	resp, err := cached.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Obtained error: %v", err)
+	}
+	if n := budget.InUse(); n != int64(len("payload")) {
+		t.Errorf("Expected the buffered body to be reserved, got %d bytes in use", n)
+	}
+	resp.Body.Close()
+	if n := budget.InUse(); n != 0 {
+		t.Errorf("Expected the reservation to be released once closed, got %d bytes in use", n)
+	}
+
+	// The requests are shed once the budget is exhausted, if configured so
+	shed := reqctl.NewMemoryBudget(100, reqctl.FallbackShed)
+	shed.Reserve(100)
+	req, _ = http.NewRequest("GET", server.URL+"/shed", nil)
+	_, err = reqctl.NewPolicy().WithCache(reqctl.NewCache(nil)).WithMemoryBudget(shed).Do(context.Background(), req)
+	if !errors.Is(err, reqctl.ErrMemoryBudgetExceeded) {
+		t.Errorf("Expected reqctl.ErrMemoryBudgetExceeded, got %v", err)
+	}
+}
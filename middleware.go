@@ -0,0 +1,28 @@
+package reqctl
+
+import "net/http"
+
+// Handler sends an attempt & returns its response
+type Handler func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps the handler sending the attempts, eg: to inject headers, log, measure or rewrite the responses
+type Middleware func(next Handler) Handler
+
+// Use wraps every attempt of the request, including retries & parallel calls, with the middlewares. They are
+// appended to the ones used previously, the first one being the outermost. The outcome returned by the
+// middlewares is the one classified by the retry checker.
+func (c Controller) Use(middlewares ...Middleware) Controller {
+	chain := make([]Middleware, 0, len(c.config.middlewares)+len(middlewares))
+	chain = append(chain, c.config.middlewares...)
+	c.config.middlewares = append(chain, middlewares...)
+	return c
+}
+
+// handler returns the client sending the attempts, wrapped with the middlewares
+func (c *Controller) handler(client *http.Client) Handler {
+	h := Handler(client.Do)
+	for i := len(c.config.middlewares) - 1; i >= 0; i-- {
+		h = c.config.middlewares[i](h)
+	}
+	return h
+}
@@ -0,0 +1,57 @@
+package reqctl_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestMiddleware(t *testing.T) {
+	var headers []string
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			headers = append(headers, r.Header.Get("X-Layer"))
+			return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	layer := func(name string) reqctl.Middleware {
+		return func(next reqctl.Handler) reqctl.Handler {
+			return func(req *http.Request) (*http.Response, error) {
+				req.Header.Set("X-Layer", req.Header.Get("X-Layer")+name)
+				return next(req)
+			}
+		}
+	}
+
+	// The outermost middleware rewrites the second throttled response into a success
+	calls := 0
+	rewrite := func(next reqctl.Handler) reqctl.Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			if calls++; calls == 2 {
+				resp.StatusCode = 200
+			}
+			return resp, err
+		}
+	}
+
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	resp, err := reqctl.NewPolicy().
+		WithSimpleRetryWithChecker(0, 5, reqctl.RetryOnStatus(503)).
+		Use(rewrite, layer("a")).
+		Request(context.Background(), request).
+		Use(layer("b")).
+		SetClient(client).
+		Do()
+
+	if err != nil || resp.StatusCode != 200 {
+		t.Errorf("Expected the rewritten response to stop the retries, got %v, %v", resp, err)
+	}
+	if expected := []string{"ab", "ab"}; !reflect.DeepEqual(headers, expected) {
+		t.Errorf("Expected the middlewares to wrap every attempt in order, got %v", headers)
+	}
+}
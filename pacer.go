@@ -0,0 +1,174 @@
+package reqctl
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Pacer spaces the attempts of the controllers sharing it to one per interval, keeping them under an upstream quota.
+// Attempts waiting for their turn are scheduled fairly across labels ( eg: tenants or internal callers ) using
+// weighted fair queueing, so that a noisy label cannot starve the others.
+type Pacer struct {
+	interval time.Duration
+
+	mu      sync.Mutex
+	weights map[string]float64
+	finish  map[string]float64
+	vtime   float64
+	seq     uint64
+	next    time.Time
+	queue   []*paceWaiter
+	timer   *time.Timer
+}
+
+// paceWaiter is an attempt awaiting its turn
+type paceWaiter struct {
+	tag   float64
+	seq   uint64
+	ready chan struct{}
+}
+
+// NewPacer creates a pacer releasing an attempt every interval, with every label weighted 1
+func NewPacer(interval time.Duration) *Pacer {
+	return &Pacer{
+		interval: interval,
+		weights:  map[string]float64{},
+		finish:   map[string]float64{},
+	}
+}
+
+// SetWeight sets the share of the label, a label of weight 2 is released twice as often as one of weight 1
+// while both are waiting. Non positive weights are ignored.
+func (p *Pacer) SetWeight(label string, weight float64) *Pacer {
+	if weight <= 0 {
+		return p
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.weights[label] = weight
+	return p
+}
+
+// Wait blocks until the turn of an attempt under the label, or until the context is done
+func (p *Pacer) Wait(ctx context.Context, label string) error {
+	p.mu.Lock()
+	now := time.Now()
+	if len(p.queue) == 0 && !now.Before(p.next) {
+		p.next = now.Add(p.interval)
+		p.mu.Unlock()
+		return nil
+	}
+
+	w := &paceWaiter{
+		tag:   p.tag(label),
+		seq:   p.seq,
+		ready: make(chan struct{}),
+	}
+	p.seq++
+	p.queue = append(p.queue, w)
+	p.schedule(now)
+	p.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		p.mu.Lock()
+		p.remove(w)
+		p.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// tag computes the virtual finish time of a new attempt under the label ( self-clocked fair queueing ).
+// Must be called with the lock held.
+func (p *Pacer) tag(label string) float64 {
+	weight, ok := p.weights[label]
+	if !ok {
+		weight = 1
+	}
+
+	start := p.vtime
+	if finish := p.finish[label]; finish > start {
+		start = finish
+	}
+
+	p.finish[label] = start + 1/weight
+	return p.finish[label]
+}
+
+// schedule arms the timer releasing the next waiter. Must be called with the lock held.
+func (p *Pacer) schedule(now time.Time) {
+	if p.timer == nil {
+		p.timer = time.AfterFunc(p.next.Sub(now), p.release)
+	}
+}
+
+// release hands the turn to the waiter with the lowest tag
+func (p *Pacer) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.timer = nil
+	if len(p.queue) == 0 {
+		return
+	}
+
+	now := time.Now()
+	if now.Before(p.next) {
+		p.schedule(now)
+		return
+	}
+
+	best := 0
+	for i, w := range p.queue {
+		if w.tag < p.queue[best].tag || (w.tag == p.queue[best].tag && w.seq < p.queue[best].seq) {
+			best = i
+		}
+	}
+
+	w := p.queue[best]
+	p.queue = append(p.queue[:best], p.queue[best+1:]...)
+	p.vtime = w.tag
+	p.next = now.Add(p.interval)
+	close(w.ready)
+
+	if len(p.queue) > 0 {
+		p.schedule(now)
+	} else {
+		// Without waiters every finish time is behind the virtual time, hence they are no longer needed
+		p.finish = map[string]float64{}
+	}
+}
+
+// usage returns the attempts waiting for their turn & the wait of a new attempt
+func (p *Pacer) usage(now time.Time) (int64, time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var wait time.Duration
+	if p.next.After(now) {
+		wait = p.next.Sub(now)
+	}
+	return int64(len(p.queue)), wait + time.Duration(len(p.queue))*p.interval
+}
+
+// remove drops the waiter from the queue, if it was not released yet. Must be called with the lock held.
+func (p *Pacer) remove(w *paceWaiter) {
+	for i, queued := range p.queue {
+		if queued == w {
+			p.queue = append(p.queue[:i], p.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetPacer spaces every attempt of the request, including retries & parallel calls, as per the shared pacer.
+// Waiting attempts are scheduled fairly across the labels of the requests sharing the pacer.
+func (c Controller) SetPacer(pacer *Pacer, label string) Controller {
+	c.config.pacer = pacer
+	c.config.paceLabel = label
+	return c
+}
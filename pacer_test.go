@@ -0,0 +1,89 @@
+package reqctl_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestPacerFairness(t *testing.T) {
+	pacer := reqctl.NewPacer(5 * time.Millisecond)
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	wait := func(label string) {
+		defer wg.Done()
+		if err := pacer.Wait(context.Background(), label); err != nil {
+			t.Errorf("Obtained error: %v", err)
+			return
+		}
+		mu.Lock()
+		order = append(order, label)
+		mu.Unlock()
+	}
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go wait("noisy")
+	}
+
+	// Let the noisy label queue up before the quiet one arrives
+	time.Sleep(10 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go wait("quiet")
+	}
+	wg.Wait()
+
+	for i, label := range order {
+		if label == "quiet" && i >= 10 {
+			t.Errorf("Expected the quiet label not to wait behind the noisy one, got %v", order)
+			break
+		}
+	}
+}
+
+func TestPacerInterval(t *testing.T) {
+	var times []time.Time
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			times = append(times, time.Now())
+			return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	_, err := reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(0, 2, reqctl.RetryOnStatus(503)).
+		SetPacer(reqctl.NewPacer(20*time.Millisecond), "").
+		SetClient(client).
+		Do()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+
+	for i := 1; i < len(times); i++ {
+		if gap := times[i].Sub(times[i-1]); gap < 15*time.Millisecond {
+			t.Errorf("Expected paced attempts, got a gap of %v", gap)
+		}
+	}
+}
+
+func TestPacerCancel(t *testing.T) {
+	pacer := reqctl.NewPacer(time.Hour)
+	if err := pacer.Wait(context.Background(), "a"); err != nil {
+		t.Errorf("Expected the first attempt to pass, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pacer.Wait(ctx, "a"); err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package reqctl
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// NextPageFunc returns the URL of the page following the response, nil once the last page is reached. It is called
+// once the page is handled, hence cursors found in the body should be captured by the page handler.
+type NextPageFunc func(resp *http.Response) (*url.URL, error)
+
+// LinkNextPage returns the target of the Link header with the next relation as per RFC 5988, relative to the URL
+// of the request
+func LinkNextPage(resp *http.Response) (*url.URL, error) {
+	for _, value := range resp.Header.Values("Link") {
+		for value != "" {
+			start := strings.IndexByte(value, '<')
+			if start < 0 {
+				break
+			}
+			end := strings.IndexByte(value[start:], '>')
+			if end < 0 {
+				break
+			}
+			target := value[start+1 : start+end]
+			value = value[start+end+1:]
+
+			// The parameters of the link extend until the next one
+			params := value
+			if i := strings.IndexByte(value, '<'); i >= 0 {
+				params = value[:i]
+			}
+			value = value[len(params):]
+
+			if hasNextRelation(params) {
+				next, err := url.Parse(target)
+				if err != nil {
+					return nil, err
+				}
+				if resp.Request != nil {
+					next = resp.Request.URL.ResolveReference(next)
+				}
+				return next, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// hasNextRelation reports whether the link parameters include the next relation, among space separated ones
+func hasNextRelation(params string) bool {
+	params = strings.TrimRight(strings.TrimSpace(params), ",")
+	for _, param := range strings.Split(params, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "rel") {
+			continue
+		}
+		for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+			if strings.EqualFold(rel, "next") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DoPaginated executes the request & the requests of its following pages one after the other, each page following
+// the retry policy of the controller & being handled by fn along with its 1 based number. Pages are followed as per
+// next, LinkNextPage if nil, until the last page or the first error. Pages whose status is not 2xx stop
+// the pagination with a *StatusError. The body of every page is closed once handled.
+func DoPaginated(c *Controller, next NextPageFunc, fn func(page int, resp *http.Response) error) error {
+	if next == nil {
+		next = LinkNextPage
+	}
+
+	page := *c
+	visited := map[string]bool{}
+	for n := 1; ; n++ {
+		visited[page.req.URL.String()] = true
+		resp, err := page.Do()
+		if err != nil {
+			return err
+		}
+		if err := checkStatus(resp, nil); err != nil {
+			return err
+		}
+
+		err = fn(n, resp)
+		closeBody(resp)
+		if err != nil {
+			return err
+		}
+
+		u, err := next(resp)
+		if err != nil {
+			return err
+		}
+		// Pages linking back to a visited one would loop forever
+		if u == nil || visited[u.String()] {
+			return nil
+		}
+
+		req := page.req.Clone(page.ctx)
+		req.URL, req.Host = u, ""
+		page.req = req
+	}
+}
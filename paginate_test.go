@@ -0,0 +1,83 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestDoPaginated(t *testing.T) {
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Every page fails once before succeeding
+		if atomic.AddInt64(&calls, 1)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 3 {
+			w.Header().Add("Link", `<https://example.com/first>; rel="first"`)
+			w.Header().Add("Link", `</items?page=`+strconv.Itoa(page+1)+`>; rel="prev next", <https://example.com/last>; rel=last`)
+		}
+		_, _ = w.Write([]byte("page " + strconv.Itoa(page)))
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL+"/items?page=1", nil)
+	c := reqctl.Request(context.Background(), req).
+		SetSimpleRetryWithChecker(time.Millisecond, 1, reqctl.RetryOnStatus(503))
+
+	var pages []string
+	err := reqctl.DoPaginated(&c, nil, func(n int, resp *http.Response) error {
+		body, err := io.ReadAll(resp.Body)
+		pages = append(pages, strconv.Itoa(n)+":"+string(body))
+		return err
+	})
+	if err != nil || len(pages) != 3 || pages[0] != "1:page 1" || pages[2] != "3:page 3" {
+		t.Errorf("Expected 3 pages, got %v, %v", pages, err)
+	}
+	if n := atomic.LoadInt64(&calls); n != 6 {
+		t.Errorf("Expected every page to be retried once, got %d calls", n)
+	}
+}
+
+func TestDoPaginatedCustomNext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cursor") == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte("bad"))
+	}))
+	defer server.Close()
+
+	var cursor string
+	next := func(resp *http.Response) (*url.URL, error) {
+		u := *resp.Request.URL
+		u.RawQuery = "cursor=" + cursor
+		return &u, nil
+	}
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	pages := 0
+	var statusErr *reqctl.StatusError
+	err := reqctl.DoPaginated(reqctl.Request(context.Background(), req), next, func(_ int, resp *http.Response) error {
+		pages++
+		body, _ := io.ReadAll(resp.Body)
+		cursor = string(body)
+		return nil
+	})
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadRequest || pages != 1 {
+		t.Errorf("Expected the second page to fail with its status, got %d pages, %v", pages, err)
+	}
+}
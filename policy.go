@@ -0,0 +1,447 @@
+package reqctl
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Policy is a reusable retry, timeout & parallel call configuration, decoupled from any request.
+// It is safe to copy & share, every method returns a configured copy.
+type Policy struct {
+	template Controller
+}
+
+// NewPolicy creates a policy with the default configuration, which sends a single attempt
+func NewPolicy() Policy {
+	return Policy{
+		template: newController(),
+	}
+}
+
+// Request creates a controller for the request, configured as per the policy
+func (p Policy) Request(ctx context.Context, req *http.Request) *Controller {
+	c := p.template.Clone()
+	c.ctx = ctx
+	c.req = req.Clone(ctx)
+	return &c
+}
+
+// Do executes the request as per the policy
+func (p Policy) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return p.Request(ctx, req).Do()
+}
+
+// WithSimpleRetry configures simple retry with default checker
+func (p Policy) WithSimpleRetry(interval time.Duration, times int) Policy {
+	p.template = p.template.SetSimpleRetry(interval, times)
+	return p
+}
+
+// WithSimpleRetryWithChecker configures simple retry with custom checker
+func (p Policy) WithSimpleRetryWithChecker(interval time.Duration, times int, checker RetryCheckFunc) Policy {
+	p.template = p.template.SetSimpleRetryWithChecker(interval, times, checker)
+	return p
+}
+
+// WithExponentialRetry configures exponential retry with default checker
+func (p Policy) WithExponentialRetry(interval time.Duration, times int) Policy {
+	p.template = p.template.SetExponentialRetry(interval, times)
+	return p
+}
+
+// WithExponentialRetryWithChecker configures exponential retry with custom checker
+func (p Policy) WithExponentialRetryWithChecker(interval time.Duration, times int, checker RetryCheckFunc) Policy {
+	p.template = p.template.SetExponentialRetryWithChecker(interval, times, checker)
+	return p
+}
+
+// WithSimpleRetryWithWeightedChecker configures simple retry with a probabilistic checker
+func (p Policy) WithSimpleRetryWithWeightedChecker(interval time.Duration, times int, checker WeightedRetryCheckFunc) Policy {
+	p.template = p.template.SetSimpleRetryWithWeightedChecker(interval, times, checker)
+	return p
+}
+
+// WithExponentialRetryWithWeightedChecker configures exponential retry with a probabilistic checker
+func (p Policy) WithExponentialRetryWithWeightedChecker(interval time.Duration, times int, checker WeightedRetryCheckFunc) Policy {
+	p.template = p.template.SetExponentialRetryWithWeightedChecker(interval, times, checker)
+	return p
+}
+
+// WithTimeout sets the timeout of every attempt, refer Controller.SetTimeout
+func (p Policy) WithTimeout(timeout time.Duration) Policy {
+	p.template = p.template.SetTimeout(timeout)
+	return p
+}
+
+// WithClient sets the HTTP client used to execute the requests, refer Controller.SetClient
+func (p Policy) WithClient(client *http.Client) Policy {
+	p.template = p.template.SetClient(client)
+	return p
+}
+
+// WithSoftFail enables the soft-fail mode, refer Controller.SetSoftFail
+func (p Policy) WithSoftFail(softFail bool) Policy {
+	p.template = p.template.SetSoftFail(softFail)
+	return p
+}
+
+// WithResponseTee copies the returned response bodies to the writer, refer Controller.SetResponseTee
+func (p Policy) WithResponseTee(w io.Writer) Policy {
+	p.template = p.template.SetResponseTee(w)
+	return p
+}
+
+// WithCorrelationHeaders sets the correlation headers of every attempt, refer Controller.SetCorrelationHeaders
+func (p Policy) WithCorrelationHeaders(idHeader, seqHeader string) Policy {
+	p.template = p.template.SetCorrelationHeaders(idHeader, seqHeader)
+	return p
+}
+
+// WithParallelCallWithDelay configures asynchronous retry, refer Controller.SetParallelCallWithDelay
+func (p Policy) WithParallelCallWithDelay(delay time.Duration) Policy {
+	p.template = p.template.SetParallelCallWithDelay(delay)
+	return p
+}
+
+// WithParallelCallFirstAcceptable configures asynchronous retry with an acceptor,
+// refer Controller.SetParallelCallFirstAcceptable
+func (p Policy) WithParallelCallFirstAcceptable(delay time.Duration, accept func(*http.Response, error) bool) Policy {
+	p.template = p.template.SetParallelCallFirstAcceptable(delay, accept)
+	return p
+}
+
+// WithSampler restricts verbose observability to sampled requests, refer Controller.SetSampler
+func (p Policy) WithSampler(sampler Sampler) Policy {
+	p.template = p.template.SetSampler(sampler)
+	return p
+}
+
+// WithEndpointPinning configures endpoint pinning, refer Controller.SetEndpointPinning
+func (p Policy) WithEndpointPinning(pinning EndpointPinning) Policy {
+	p.template = p.template.SetEndpointPinning(pinning)
+	return p
+}
+
+// WithConnectTimeout bounds the time spent establishing connections, refer Controller.SetConnectTimeout
+func (p Policy) WithConnectTimeout(timeout time.Duration) Policy {
+	p.template = p.template.SetConnectTimeout(timeout)
+	return p
+}
+
+// WithStreamingResponse marks the responses as streams, refer Controller.SetStreamingResponse
+func (p Policy) WithStreamingResponse(streaming bool) Policy {
+	p.template = p.template.SetStreamingResponse(streaming)
+	return p
+}
+
+// WithCircuitBreaker guards every attempt with the breaker, refer Controller.SetCircuitBreaker
+func (p Policy) WithCircuitBreaker(breaker *CircuitBreaker) Policy {
+	p.template = p.template.SetCircuitBreaker(breaker)
+	return p
+}
+
+// WithConnectionGuard suppresses traffic multiplication under connection pressure,
+// refer Controller.SetConnectionGuard
+func (p Policy) WithConnectionGuard(guard ConnectionGuard) Policy {
+	p.template = p.template.SetConnectionGuard(guard)
+	return p
+}
+
+// WithMemoryBudget bounds the buffered bytes with the shared budget, refer Controller.SetMemoryBudget
+func (p Policy) WithMemoryBudget(budget *MemoryBudget) Policy {
+	p.template = p.template.SetMemoryBudget(budget)
+	return p
+}
+
+// WithAttemptContext overrides how the attempt contexts are derived, refer Controller.SetAttemptContext
+func (p Policy) WithAttemptContext(fn AttemptContextFunc) Policy {
+	p.template = p.template.SetAttemptContext(fn)
+	return p
+}
+
+// WithSLO records the outcome of every request in the tracker, refer Controller.SetSLO
+func (p Policy) WithSLO(tracker *SLOTracker) Policy {
+	p.template = p.template.SetSLO(tracker)
+	return p
+}
+
+// WithParallelCallPredicate restricts parallel calls to the matching requests, refer Controller.SetParallelCallPredicate
+func (p Policy) WithParallelCallPredicate(predicate RequestPredicate) Policy {
+	p.template = p.template.SetParallelCallPredicate(predicate)
+	return p
+}
+
+// WithJitter randomizes the backoff waits, refer Controller.SetJitter
+func (p Policy) WithJitter(jitter Jitter) Policy {
+	p.template = p.template.SetJitter(jitter)
+	return p
+}
+
+// WithSimpleRetryWithJitter configures simple retry with randomized waits, refer Controller.SetSimpleRetryWithJitter
+func (p Policy) WithSimpleRetryWithJitter(interval time.Duration, times int, jitter Jitter) Policy {
+	p.template = p.template.SetSimpleRetryWithJitter(interval, times, jitter)
+	return p
+}
+
+// WithExponentialRetryWithJitter configures exponential retry with randomized waits,
+// refer Controller.SetExponentialRetryWithJitter
+func (p Policy) WithExponentialRetryWithJitter(interval time.Duration, times int, jitter Jitter) Policy {
+	p.template = p.template.SetExponentialRetryWithJitter(interval, times, jitter)
+	return p
+}
+
+// WithMaxBackoff caps the computed backoff wait, refer Controller.SetMaxBackoff
+func (p Policy) WithMaxBackoff(max time.Duration) Policy {
+	p.template = p.template.SetMaxBackoff(max)
+	return p
+}
+
+// WithAuthenticator applies the cached auth artifact to every attempt, refer Controller.SetAuthenticator
+func (p Policy) WithAuthenticator(auth *Authenticator) Policy {
+	p.template = p.template.SetAuthenticator(auth)
+	return p
+}
+
+// WithMaxElapsedTime bounds the time spent issuing attempts, refer Controller.SetMaxElapsedTime
+func (p Policy) WithMaxElapsedTime(max time.Duration) Policy {
+	p.template = p.template.SetMaxElapsedTime(max)
+	return p
+}
+
+// WithPreflight issues a preflight before large uploads, refer Controller.SetPreflight
+func (p Policy) WithPreflight(method string, minBodySize int64, accept func(*http.Response) bool) Policy {
+	p.template = p.template.SetPreflight(method, minBodySize, accept)
+	return p
+}
+
+// WithRespectRetryAfter honors the Retry-After header of 429 & 503 responses, refer Controller.SetRespectRetryAfter
+func (p Policy) WithRespectRetryAfter(respect bool, max time.Duration) Policy {
+	p.template = p.template.SetRespectRetryAfter(respect, max)
+	return p
+}
+
+// WithRetryNonIdempotent allows retrying requests which are not idempotent, refer Controller.SetRetryNonIdempotent
+func (p Policy) WithRetryNonIdempotent(allow bool) Policy {
+	p.template = p.template.SetRetryNonIdempotent(allow)
+	return p
+}
+
+// WithPacer spaces the attempts as per the shared pacer under the label, refer Controller.SetPacer
+func (p Policy) WithPacer(pacer *Pacer, label string) Policy {
+	p.template = p.template.SetPacer(pacer, label)
+	return p
+}
+
+// WithRenegotiation adjusts the negotiation of the attempts following a failure, refer Controller.SetRenegotiation
+func (p Policy) WithRenegotiation(renegotiators ...Renegotiator) Policy {
+	p.template = p.template.SetRenegotiation(renegotiators...)
+	return p
+}
+
+// WithBufferRequestBody buffers non replayable request bodies, refer Controller.SetBufferRequestBody
+func (p Policy) WithBufferRequestBody(maxBytes int64) Policy {
+	p.template = p.template.SetBufferRequestBody(maxBytes)
+	return p
+}
+
+// WithParallelCallSchedule fires parallel calls as per the stagger schedule, refer Controller.SetParallelCallSchedule
+func (p Policy) WithParallelCallSchedule(maxFanOut int, schedule ...time.Duration) Policy {
+	p.template = p.template.SetParallelCallSchedule(maxFanOut, schedule...)
+	return p
+}
+
+// WithAdaptiveParallelCall fires the parallel call as per the observed latency, refer Controller.SetAdaptiveParallelCall
+func (p Policy) WithAdaptiveParallelCall(tracker *LatencyTracker, percentile float64, fallback time.Duration) Policy {
+	p.template = p.template.SetAdaptiveParallelCall(tracker, percentile, fallback)
+	return p
+}
+
+// WithParallelEndpoints races the requests across the endpoints, refer Controller.SetParallelEndpoints
+func (p Policy) WithParallelEndpoints(endpoints ...*url.URL) Policy {
+	p.template = p.template.SetParallelEndpoints(endpoints...)
+	return p
+}
+
+// WithShadow mirrors the requests to the shadow endpoint, refer Controller.SetShadow
+func (p Policy) WithShadow(endpoint *url.URL, onShadow ShadowFunc) Policy {
+	p.template = p.template.SetShadow(endpoint, onShadow)
+	return p
+}
+
+// WithFallbackEndpoints replays failed requests against the endpoints in order, refer Controller.SetFallbackEndpoints
+func (p Policy) WithFallbackEndpoints(endpoints ...*url.URL) Policy {
+	p.template = p.template.SetFallbackEndpoints(endpoints...)
+	return p
+}
+
+// WithEndpointPool spreads the attempts across the endpoints of the pool, refer Controller.SetEndpointPool
+func (p Policy) WithEndpointPool(pool *EndpointPool) Policy {
+	p.template = p.template.SetEndpointPool(pool)
+	return p
+}
+
+// WithResolver resolves the endpoints of the request hosts, refer Controller.SetResolver
+func (p Policy) WithResolver(resolver Resolver) Policy {
+	p.template = p.template.SetResolver(resolver)
+	return p
+}
+
+// WithRetryBudget draws the retries from the shared budget, refer Controller.SetRetryBudget
+func (p Policy) WithRetryBudget(budget *RetryBudget) Policy {
+	p.template = p.template.SetRetryBudget(budget)
+	return p
+}
+
+// WithRateLimiter makes every attempt wait for the limiter, refer Controller.SetRateLimiter
+func (p Policy) WithRateLimiter(limiter Limiter) Policy {
+	p.template = p.template.SetRateLimiter(limiter)
+	return p
+}
+
+// WithBulkhead limits the concurrent attempts with the shared bulkhead, refer Controller.SetBulkhead
+func (p Policy) WithBulkhead(bulkhead *Bulkhead) Policy {
+	p.template = p.template.SetBulkhead(bulkhead)
+	return p
+}
+
+// WithHooks observes the attempts with the hooks, refer Controller.SetHooks
+func (p Policy) WithHooks(hooks Hooks) Policy {
+	p.template = p.template.SetHooks(hooks)
+	return p
+}
+
+// Use wraps the attempts with the middlewares, refer Controller.Use
+func (p Policy) Use(middlewares ...Middleware) Policy {
+	p.template = p.template.Use(middlewares...)
+	return p
+}
+
+// WithPerAttemptMutator mutates the request of every attempt, refer Controller.SetPerAttemptMutator
+func (p Policy) WithPerAttemptMutator(mutator func(attempt int, req *http.Request) error) Policy {
+	p.template = p.template.SetPerAttemptMutator(mutator)
+	return p
+}
+
+// WithIdempotencyKey attaches an idempotency key to every attempt, refer Controller.SetIdempotencyKey
+func (p Policy) WithIdempotencyKey(header string) Policy {
+	p.template = p.template.SetIdempotencyKey(header)
+	return p
+}
+
+// OnExhausted defines the outcome once the retries are exhausted, refer Controller.OnExhausted
+func (p Policy) OnExhausted(action ExhaustedAction) Policy {
+	p.template = p.template.OnExhausted(action)
+	return p
+}
+
+// WithTraceTimings captures the timings of every attempt, refer Controller.SetTraceTimings
+func (p Policy) WithTraceTimings(enabled bool) Policy {
+	p.template = p.template.SetTraceTimings(enabled)
+	return p
+}
+
+// WithDebugDump writes the failed attempts to w, refer Controller.SetDebugDump
+func (p Policy) WithDebugDump(w io.Writer, includeBody bool) Policy {
+	p.template = p.template.SetDebugDump(w, includeBody)
+	return p
+}
+
+// WithClock drives the retry waits & parallel call delays by the clock, refer Controller.SetClock
+func (p Policy) WithClock(clock Clock) Policy {
+	p.template = p.template.SetClock(clock)
+	return p
+}
+
+// WithChaos injects faults into every attempt, refer Controller.SetChaos
+func (p Policy) WithChaos(chaos Chaos) Policy {
+	p.template = p.template.SetChaos(chaos)
+	return p
+}
+
+// WithMaxResponseBytes bounds the body of the responses, refer Controller.SetMaxResponseBytes
+func (p Policy) WithMaxResponseBytes(n int64) Policy {
+	p.template = p.template.SetMaxResponseBytes(n)
+	return p
+}
+
+// WithValidator validates the settled responses, refer Controller.SetValidator
+func (p Policy) WithValidator(validator ValidatorFunc) Policy {
+	p.template = p.template.SetValidator(validator)
+	return p
+}
+
+// WithBodyRetry re-runs the requests whose body fails to be read or decoded, refer Controller.SetBodyRetry
+func (p Policy) WithBodyRetry(times int) Policy {
+	p.template = p.template.SetBodyRetry(times)
+	return p
+}
+
+// WithVerifyBody verifies the response bodies against their length & digest, refer Controller.SetVerifyBody
+func (p Policy) WithVerifyBody(verify bool) Policy {
+	p.template = p.template.SetVerifyBody(verify)
+	return p
+}
+
+// WithDecompression decodes the response bodies transparently, refer Controller.SetDecompression
+func (p Policy) WithDecompression(decompressors map[string]Decompressor) Policy {
+	p.template = p.template.SetDecompression(decompressors)
+	return p
+}
+
+// WithConditional makes the GET & HEAD attempts conditional, refer Controller.SetConditional
+func (p Policy) WithConditional(cache *ConditionalCache) Policy {
+	p.template = p.template.SetConditional(cache)
+	return p
+}
+
+// WithCache serves the GET requests from the cache while fresh, refer Controller.SetCache
+func (p Policy) WithCache(cache *Cache) Policy {
+	p.template = p.template.SetCache(cache)
+	return p
+}
+
+// WithCoalescing shares the outcome of the identical requests in flight, refer Controller.SetCoalescing
+func (p Policy) WithCoalescing(coalescer *Coalescer) Policy {
+	p.template = p.template.SetCoalescing(coalescer)
+	return p
+}
+
+// WithUploadProgress reports the progress of the request bodies, refer Controller.SetUploadProgress
+func (p Policy) WithUploadProgress(fn ProgressFunc) Policy {
+	p.template = p.template.SetUploadProgress(fn)
+	return p
+}
+
+// WithThrottle limits the bandwidth of the bodies to the shared throttle, refer Controller.SetThrottle
+func (p Policy) WithThrottle(throttle *Throttle) Policy {
+	p.template = p.template.SetThrottle(throttle)
+	return p
+}
+
+// WithAttemptBandwidth limits the bandwidth of the bodies of each attempt, refer Controller.SetAttemptBandwidth
+func (p Policy) WithAttemptBandwidth(bytesPerSecond int64) Policy {
+	p.template = p.template.SetAttemptBandwidth(bytesPerSecond)
+	return p
+}
+
+// WithCallbackPool executes the requests submitted with a callback on the pool, refer Controller.SetCallbackPool
+func (p Policy) WithCallbackPool(pool *CallbackPool) Policy {
+	p.template = p.template.SetCallbackPool(pool)
+	return p
+}
+
+// WithDeadLetter sends the requests given up to the sink, refer Controller.SetDeadLetter
+func (p Policy) WithDeadLetter(sink DeadLetterSink) Policy {
+	p.template = p.template.SetDeadLetter(sink)
+	return p
+}
+
+// WithConflictRefresh refreshes & resends the conditional writes failing with a conflict,
+// refer Controller.SetConflictRefresh
+func (p Policy) WithConflictRefresh(refresh RefreshFunc, maxRefreshes int) Policy {
+	p.template = p.template.SetConflictRefresh(refresh, maxRefreshes)
+	return p
+}
@@ -0,0 +1,78 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestPolicy(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Every other call fails
+		if atomic.AddInt32(&calls, 1)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	checker := func(resp *http.Response, err error) bool {
+		return err != nil || resp.StatusCode >= 500
+	}
+
+	policy := reqctl.NewPolicy().
+		WithExponentialRetryWithChecker(time.Millisecond, 2, checker).
+		WithTimeout(time.Second)
+
+	for _, path := range []string{"/a", "/b", "/c"} {
+		request, err := http.NewRequest("GET", server.URL+path, nil)
+		if err != nil {
+			t.Errorf("Error creating request: %v", err)
+			return
+		}
+
+		resp, err := policy.Do(context.Background(), request)
+		if err != nil {
+			t.Errorf("Obtained error: %v", err)
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status code 200 for %s, got %d", path, resp.StatusCode)
+		}
+	}
+
+	if calls != 6 {
+		t.Errorf("Expected 6 calls, got %d", calls)
+	}
+}
+
+func TestPolicyRetryWithJitter(t *testing.T) {
+	calls := 0
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			return nil, errors.New("connection refused")
+		}),
+	}
+
+	for _, policy := range []reqctl.Policy{
+		reqctl.NewPolicy().WithSimpleRetryWithJitter(time.Millisecond, 2, reqctl.FullJitter),
+		reqctl.NewPolicy().WithExponentialRetryWithJitter(time.Millisecond, 2, reqctl.EqualJitter),
+	} {
+		calls = 0
+		request, _ := http.NewRequest("GET", "http://localhost", nil)
+		if _, err := policy.WithClient(client).Do(context.Background(), request); err == nil || calls != 3 {
+			t.Errorf("Expected 2 retries, got %d calls with %v", calls, err)
+		}
+	}
+}
@@ -0,0 +1,423 @@
+package reqctl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// PolicySpecVersion is the version of the policy spec produced by this package
+const PolicySpecVersion = 1
+
+// specLatencySamples is the window of the latency tracker of decoded policies with adaptive parallel calls
+const specLatencySamples = 100
+
+// ErrPolicyNotSerializable is returned when serializing a policy configured with functions, eg: a custom checker
+var ErrPolicyNotSerializable = errors.New("reqctl: policy is not serializable")
+
+// Duration is a time.Duration serialized in its string form, eg: "150ms"
+type Duration time.Duration
+
+// MarshalJSON encodes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON decodes the duration from a string, or from a number of nanoseconds
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		n, nErr := strconv.ParseInt(string(data), 10, 64)
+		if nErr != nil {
+			return fmt.Errorf("reqctl: invalid duration %s", data)
+		}
+		*d = Duration(n)
+		return nil
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("reqctl: invalid duration %q: %w", s, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// PolicySpec is the stable, versioned representation of a policy, shared across services by a config service.
+// Only the resilience behavior is represented: process local wiring such as clients, hooks, authenticators,
+// SLO trackers & memory budgets is left out, and is added by every service to the decoded policy.
+type PolicySpec struct {
+	Version            int          `json:"version"`
+	Retry              *RetrySpec   `json:"retry,omitempty"`
+	Timeout            Duration     `json:"timeout,omitempty"`
+	ConnectTimeout     Duration     `json:"connect_timeout,omitempty"`
+	MaxElapsed         Duration     `json:"max_elapsed,omitempty"`
+	ParallelDelay      Duration     `json:"parallel_delay,omitempty"`
+	ParallelSchedule   []Duration   `json:"parallel_schedule,omitempty"`
+	MaxFanOut          int          `json:"max_fan_out,omitempty"`
+	ParallelPercentile float64      `json:"parallel_percentile,omitempty"`
+	ParallelEndpoints  []string     `json:"parallel_endpoints,omitempty"`
+	FallbackEndpoints  []string     `json:"fallback_endpoints,omitempty"`
+	SoftFail           bool         `json:"soft_fail,omitempty"`
+	Streaming          bool         `json:"streaming,omitempty"`
+	RetryNonIdempotent bool         `json:"retry_non_idempotent,omitempty"`
+	EndpointPinning    string       `json:"endpoint_pinning,omitempty"`
+	MaxOpenConns       int64        `json:"max_open_conns,omitempty"`
+	MaxInFlight        int64        `json:"max_in_flight,omitempty"`
+	CorrelationHeader  string       `json:"correlation_header,omitempty"`
+	AttemptHeader      string       `json:"attempt_header,omitempty"`
+	IdempotencyHeader  string       `json:"idempotency_header,omitempty"`
+	BufferRequestBody  int64        `json:"buffer_request_body,omitempty"`
+	MaxResponseBytes   int64        `json:"max_response_bytes,omitempty"`
+	AttemptBandwidth   int64        `json:"attempt_bandwidth,omitempty"`
+	BodyRetries        int          `json:"body_retries,omitempty"`
+	VerifyBody         bool         `json:"verify_body,omitempty"`
+	CircuitBreaker     *BreakerSpec `json:"circuit_breaker,omitempty"`
+	RetryBudget        *BudgetSpec  `json:"retry_budget,omitempty"`
+}
+
+// RetrySpec represents the retry strategy of a policy
+type RetrySpec struct {
+	// Strategy is either "simple" or "exponential"
+	Strategy      string          `json:"strategy"`
+	MaxRetries    int             `json:"max_retries"`
+	Interval      Duration        `json:"interval"`
+	Jitter        Jitter          `json:"jitter,omitempty"`
+	MaxBackoff    Duration        `json:"max_backoff,omitempty"`
+	RetryAfter    bool            `json:"retry_after,omitempty"`
+	MaxRetryAfter Duration        `json:"max_retry_after,omitempty"`
+	OnExhausted   ExhaustedAction `json:"on_exhausted,omitempty"`
+	Checker       *CheckerSpec    `json:"checker,omitempty"`
+}
+
+// CheckerSpec represents a retry checker built from the built-in checkers, retrying when any of them asks for it.
+// An empty spec is the DefaultRetryChecker.
+type CheckerSpec struct {
+	Statuses      []int `json:"statuses,omitempty"`
+	NetworkErrors bool  `json:"network_errors,omitempty"`
+	Timeouts      bool  `json:"timeouts,omitempty"`
+}
+
+// BreakerSpec represents the circuit breaker of a policy, backed by the process wide store once decoded
+type BreakerSpec struct {
+	Name      string   `json:"name"`
+	Threshold int      `json:"threshold"`
+	Window    Duration `json:"window"`
+	Cooldown  Duration `json:"cooldown"`
+}
+
+// BudgetSpec represents the retry budget of a policy, backed by the process wide store once decoded
+type BudgetSpec struct {
+	Name       string   `json:"name"`
+	Ratio      float64  `json:"ratio"`
+	MinRetries int      `json:"min_retries,omitempty"`
+	Window     Duration `json:"window"`
+}
+
+// pinningNames maps the endpoint pinning modes to their spec names
+var pinningNames = map[EndpointPinning]string{
+	PinNone:       "",
+	PinPerRequest: "per_request",
+	PinRotate:     "rotate",
+}
+
+// isEmpty reports whether the spec is the default checker
+func (s CheckerSpec) isEmpty() bool {
+	return len(s.Statuses) == 0 && !s.NetworkErrors && !s.Timeouts
+}
+
+// checker builds the retry checker of the spec
+func (s CheckerSpec) checker() RetryCheckFunc {
+	if s.isEmpty() {
+		return DefaultRetryChecker
+	}
+
+	var checkers []RetryCheckFunc
+	if len(s.Statuses) > 0 {
+		checkers = append(checkers, RetryOnStatus(s.Statuses...))
+	}
+	if s.NetworkErrors {
+		checkers = append(checkers, RetryOnNetworkError())
+	}
+	if s.Timeouts {
+		checkers = append(checkers, RetryOnTimeout())
+	}
+	return Any(checkers...)
+}
+
+// setRetryWithSpec configures the retry strategy with the checker of the spec, keeping the spec for serialization
+func (c Controller) setRetryWithSpec(rt retryType, interval time.Duration, times int, spec CheckerSpec) Controller {
+	c = c.setRetryWithChecker(rt, interval, times, spec.checker())
+	c.config.retryCfg.CheckerSpec = &spec
+	return c
+}
+
+// Spec returns the serializable representation of the policy.
+// It fails with ErrPolicyNotSerializable if its resilience behavior depends on functions.
+func (p Policy) Spec() (PolicySpec, error) {
+	cfg := p.template.config
+	spec := PolicySpec{
+		Version:            PolicySpecVersion,
+		Timeout:            Duration(cfg.timeout),
+		ConnectTimeout:     Duration(cfg.connectTimeout),
+		MaxElapsed:         Duration(cfg.maxElapsed),
+		SoftFail:           cfg.softFail,
+		Streaming:          cfg.streaming,
+		RetryNonIdempotent: cfg.retryNonIdempotent,
+		EndpointPinning:    pinningNames[cfg.pinning],
+		CorrelationHeader:  cfg.correlationHeader,
+		AttemptHeader:      cfg.attemptHeader,
+		IdempotencyHeader:  cfg.idempotencyHeader,
+		BufferRequestBody:  cfg.bufferBody,
+		MaxResponseBytes:   cfg.maxResponseBytes,
+		AttemptBandwidth:   cfg.attemptBandwidth,
+		BodyRetries:        cfg.bodyRetries,
+		VerifyBody:         cfg.verifyBody,
+	}
+
+	if len(cfg.renegotiators) > 0 {
+		return PolicySpec{}, fmt.Errorf("%w: renegotiators", ErrPolicyNotSerializable)
+	}
+	if cfg.validator != nil {
+		return PolicySpec{}, fmt.Errorf("%w: validator", ErrPolicyNotSerializable)
+	}
+	if cfg.conflictCfg != nil {
+		return PolicySpec{}, fmt.Errorf("%w: conflict refresh", ErrPolicyNotSerializable)
+	}
+	if cfg.preflight != nil {
+		return PolicySpec{}, fmt.Errorf("%w: preflight acceptor", ErrPolicyNotSerializable)
+	}
+
+	if retryCfg := cfg.retryCfg; retryCfg.RetryType != noRetry {
+		if retryCfg.CheckerSpec == nil || retryCfg.RetryWeight != nil {
+			return PolicySpec{}, fmt.Errorf("%w: custom retry checker", ErrPolicyNotSerializable)
+		}
+
+		spec.Retry = &RetrySpec{
+			Strategy:      string(retryCfg.RetryType),
+			MaxRetries:    retryCfg.MaxCount,
+			Interval:      Duration(retryCfg.RetryInterval),
+			MaxBackoff:    Duration(cfg.maxBackoff),
+			RetryAfter:    cfg.retryAfter.respect,
+			MaxRetryAfter: Duration(cfg.retryAfter.max),
+			OnExhausted:   cfg.onExhausted,
+		}
+		if cfg.jitter != NoJitter {
+			spec.Retry.Jitter = cfg.jitter
+		}
+		if !retryCfg.CheckerSpec.isEmpty() {
+			checker := *retryCfg.CheckerSpec
+			spec.Retry.Checker = &checker
+		}
+	}
+
+	if cfg.asyncCfg != nil {
+		if cfg.asyncCfg.Accept != nil || cfg.hedgePredicate != nil {
+			return PolicySpec{}, fmt.Errorf("%w: custom parallel call acceptor or predicate", ErrPolicyNotSerializable)
+		}
+		spec.ParallelDelay = Duration(cfg.asyncCfg.Delay)
+		spec.MaxFanOut = cfg.asyncCfg.MaxFanOut
+		if cfg.asyncCfg.Tracker != nil {
+			spec.ParallelPercentile = cfg.asyncCfg.Percentile
+		}
+		for _, endpoint := range cfg.asyncCfg.Endpoints {
+			spec.ParallelEndpoints = append(spec.ParallelEndpoints, endpoint.String())
+		}
+		for _, offset := range cfg.asyncCfg.Schedule {
+			spec.ParallelSchedule = append(spec.ParallelSchedule, Duration(offset))
+		}
+	}
+
+	for _, endpoint := range cfg.fallbacks {
+		spec.FallbackEndpoints = append(spec.FallbackEndpoints, endpoint.String())
+	}
+
+	if guard := cfg.connGuard; guard != nil {
+		spec.MaxOpenConns, spec.MaxInFlight = guard.MaxOpenConns, guard.MaxInFlight
+	}
+
+	if b := cfg.breaker; b != nil {
+		spec.CircuitBreaker = &BreakerSpec{
+			Name:      b.name,
+			Threshold: int(b.threshold),
+			Window:    Duration(b.window),
+			Cooldown:  Duration(b.cooldown),
+		}
+	}
+
+	if b := cfg.retryBudget; b != nil {
+		spec.RetryBudget = &BudgetSpec{
+			Name:       b.name,
+			Ratio:      b.ratio,
+			MinRetries: int(b.minRetries),
+			Window:     Duration(b.window),
+		}
+	}
+	return spec, nil
+}
+
+// Policy builds the policy represented by the spec
+func (s PolicySpec) Policy() (Policy, error) {
+	if s.Version < 1 || s.Version > PolicySpecVersion {
+		return Policy{}, fmt.Errorf("reqctl: unsupported policy spec version %d, supported up to %d",
+			s.Version, PolicySpecVersion)
+	}
+
+	c := newController().
+		SetTimeout(time.Duration(s.Timeout)).
+		SetConnectTimeout(time.Duration(s.ConnectTimeout)).
+		SetMaxElapsedTime(time.Duration(s.MaxElapsed)).
+		SetSoftFail(s.SoftFail).
+		SetStreamingResponse(s.Streaming).
+		SetRetryNonIdempotent(s.RetryNonIdempotent).
+		SetCorrelationHeaders(s.CorrelationHeader, s.AttemptHeader).
+		SetBufferRequestBody(s.BufferRequestBody).
+		SetMaxResponseBytes(s.MaxResponseBytes).
+		SetAttemptBandwidth(s.AttemptBandwidth).
+		SetBodyRetry(s.BodyRetries).
+		SetVerifyBody(s.VerifyBody)
+	if s.IdempotencyHeader != "" {
+		c = c.SetIdempotencyKey(s.IdempotencyHeader)
+	}
+
+	if r := s.Retry; r != nil {
+		var rt retryType
+		switch r.Strategy {
+		case string(simpleRetry):
+			rt = simpleRetry
+		case string(exponentialRetry):
+			rt = exponentialRetry
+		default:
+			return Policy{}, fmt.Errorf("reqctl: unknown retry strategy %q", r.Strategy)
+		}
+
+		switch r.OnExhausted {
+		case "", ReturnLastResponse, ReturnError:
+		default:
+			return Policy{}, fmt.Errorf("reqctl: unknown exhausted action %q", r.OnExhausted)
+		}
+
+		var checker CheckerSpec
+		if r.Checker != nil {
+			checker = *r.Checker
+		}
+
+		c = c.setRetryWithSpec(rt, time.Duration(r.Interval), r.MaxRetries, checker).
+			SetMaxBackoff(time.Duration(r.MaxBackoff)).
+			SetRespectRetryAfter(r.RetryAfter, time.Duration(r.MaxRetryAfter)).
+			OnExhausted(r.OnExhausted)
+		if r.Jitter != "" {
+			c = c.SetJitter(r.Jitter)
+		}
+	}
+
+	if s.ParallelPercentile > 0 {
+		c = c.SetAdaptiveParallelCall(NewLatencyTracker(specLatencySamples), s.ParallelPercentile,
+			time.Duration(s.ParallelDelay))
+	} else if s.ParallelDelay > 0 {
+		c = c.SetParallelCallWithDelay(time.Duration(s.ParallelDelay))
+	}
+	if len(s.ParallelSchedule) > 0 {
+		schedule := make([]time.Duration, 0, len(s.ParallelSchedule))
+		for _, offset := range s.ParallelSchedule {
+			schedule = append(schedule, time.Duration(offset))
+		}
+		c = c.SetParallelCallSchedule(s.MaxFanOut, schedule...)
+	}
+	if len(s.ParallelEndpoints) > 0 {
+		endpoints, err := parseEndpoints(s.ParallelEndpoints)
+		if err != nil {
+			return Policy{}, err
+		}
+		c = c.SetParallelEndpoints(endpoints...)
+	}
+
+	if len(s.FallbackEndpoints) > 0 {
+		endpoints, err := parseEndpoints(s.FallbackEndpoints)
+		if err != nil {
+			return Policy{}, err
+		}
+		c = c.SetFallbackEndpoints(endpoints...)
+	}
+
+	pinningFound := false
+	for pinning, name := range pinningNames {
+		if name == s.EndpointPinning {
+			c, pinningFound = c.SetEndpointPinning(pinning), true
+		}
+	}
+	if !pinningFound {
+		return Policy{}, fmt.Errorf("reqctl: unknown endpoint pinning %q", s.EndpointPinning)
+	}
+
+	if s.MaxOpenConns > 0 || s.MaxInFlight > 0 {
+		c = c.SetConnectionGuard(ConnectionGuard{MaxOpenConns: s.MaxOpenConns, MaxInFlight: s.MaxInFlight})
+	}
+
+	if b := s.CircuitBreaker; b != nil {
+		c = c.SetCircuitBreaker(NewCircuitBreaker(b.Name, b.Threshold, time.Duration(b.Window), time.Duration(b.Cooldown)))
+	}
+	if b := s.RetryBudget; b != nil {
+		c = c.SetRetryBudget(NewRetryBudget(b.Name, b.Ratio, b.MinRetries, time.Duration(b.Window)))
+	}
+
+	return Policy{template: c}, nil
+}
+
+// parseEndpoints parses the endpoint URLs of a spec
+func parseEndpoints(raw []string) ([]*url.URL, error) {
+	endpoints := make([]*url.URL, 0, len(raw))
+	for _, endpoint := range raw {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("reqctl: invalid endpoint %q: %w", endpoint, err)
+		}
+		endpoints = append(endpoints, u)
+	}
+	return endpoints, nil
+}
+
+// PolicyCodec encodes policy specs for distribution, eg: as JSON or as a protobuf message
+type PolicyCodec interface {
+	Encode(spec PolicySpec) ([]byte, error)
+	Decode(data []byte) (PolicySpec, error)
+}
+
+// JSONCodec encodes policy specs as JSON
+var JSONCodec PolicyCodec = jsonCodec{}
+
+// jsonCodec is the JSON policy codec
+type jsonCodec struct{}
+
+// Encode encodes the spec as JSON
+func (jsonCodec) Encode(spec PolicySpec) ([]byte, error) {
+	return json.Marshal(spec)
+}
+
+// Decode decodes the spec from JSON
+func (jsonCodec) Decode(data []byte) (PolicySpec, error) {
+	var spec PolicySpec
+	err := json.Unmarshal(data, &spec)
+	return spec, err
+}
+
+// MarshalPolicy serializes the policy with the codec
+func MarshalPolicy(p Policy, codec PolicyCodec) ([]byte, error) {
+	spec, err := p.Spec()
+	if err != nil {
+		return nil, err
+	}
+	return codec.Encode(spec)
+}
+
+// UnmarshalPolicy deserializes a policy with the codec
+func UnmarshalPolicy(data []byte, codec PolicyCodec) (Policy, error) {
+	spec, err := codec.Decode(data)
+	if err != nil {
+		return Policy{}, err
+	}
+	return spec.Policy()
+}
@@ -0,0 +1,94 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestPolicySerialization(t *testing.T) {
+	data := []byte(`{
+		"version": 1,
+		"retry": {"strategy": "exponential", "max_retries": 2, "interval": "1ms", "jitter": "full",
+			"checker": {"statuses": [503]}},
+		"timeout": "2s",
+		"body_retries": 2,
+		"verify_body": true,
+		"circuit_breaker": {"name": "spec-test", "threshold": 5, "window": "1m", "cooldown": "30s"}
+	}`)
+
+	policy, err := reqctl.UnmarshalPolicy(data, reqctl.JSONCodec)
+	if err != nil {
+		t.Fatalf("Obtained error: %v", err)
+	}
+
+	calls := 0
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	resp, err := policy.WithClient(client).Do(context.Background(), request)
+	if err != nil || resp.StatusCode != 503 || calls != 3 {
+		t.Errorf("Expected the decoded checker to retry 503 twice, got %d calls with %v", calls, err)
+	}
+
+	encoded, err := reqctl.MarshalPolicy(policy, reqctl.JSONCodec)
+	if err != nil {
+		t.Fatalf("Obtained error: %v", err)
+	}
+
+	spec, err := reqctl.JSONCodec.Decode(encoded)
+	if err != nil {
+		t.Fatalf("Obtained error: %v", err)
+	}
+	if spec.Retry == nil || spec.Retry.Strategy != "exponential" || spec.Retry.Checker == nil ||
+		spec.Retry.Checker.Statuses[0] != 503 || spec.Timeout != reqctl.Duration(2*time.Second) ||
+		spec.CircuitBreaker == nil || spec.CircuitBreaker.Cooldown != reqctl.Duration(30*time.Second) ||
+		spec.BodyRetries != 2 || !spec.VerifyBody {
+		t.Errorf("Expected the policy to survive a round trip, got %s", encoded)
+	}
+}
+
+func TestPolicySerializationErrors(t *testing.T) {
+	custom := reqctl.NewPolicy().
+		WithSimpleRetryWithChecker(time.Millisecond, 2, func(resp *http.Response, err error) bool {
+			return err != nil
+		})
+	if _, err := reqctl.MarshalPolicy(custom, reqctl.JSONCodec); !errors.Is(err, reqctl.ErrPolicyNotSerializable) {
+		t.Errorf("Expected reqctl.ErrPolicyNotSerializable for a custom checker, got %v", err)
+	}
+
+	renegotiated := reqctl.NewPolicy().WithSimpleRetry(time.Second, 3).WithRenegotiation(reqctl.IdentityEncoding())
+	if _, err := reqctl.MarshalPolicy(renegotiated, reqctl.JSONCodec); !errors.Is(err, reqctl.ErrPolicyNotSerializable) {
+		t.Errorf("Expected reqctl.ErrPolicyNotSerializable for renegotiators, got %v", err)
+	}
+
+	for name, policy := range map[string]reqctl.Policy{
+		"validator": reqctl.NewPolicy().WithValidator(func(resp *http.Response) error { return nil }),
+		"conflict refresh": reqctl.NewPolicy().WithConflictRefresh(func(ctx context.Context, conflict *http.Response) ([]byte, string, error) {
+			return nil, "", nil
+		}, 1),
+		"preflight": reqctl.NewPolicy().WithPreflight(http.MethodHead, 1024, nil),
+	} {
+		if _, err := reqctl.MarshalPolicy(policy, reqctl.JSONCodec); !errors.Is(err, reqctl.ErrPolicyNotSerializable) {
+			t.Errorf("Expected reqctl.ErrPolicyNotSerializable for a %s, got %v", name, err)
+		}
+	}
+
+	if _, err := reqctl.MarshalPolicy(reqctl.NewPolicy().WithSimpleRetry(time.Second, 3), reqctl.JSONCodec); err != nil {
+		t.Errorf("Expected the default checker to be serializable, got %v", err)
+	}
+
+	_, err := reqctl.UnmarshalPolicy([]byte(`{"version": 2}`), reqctl.JSONCodec)
+	if err == nil || !strings.Contains(err.Error(), "version") {
+		t.Errorf("Expected an unsupported version error, got %v", err)
+	}
+}
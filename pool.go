@@ -0,0 +1,243 @@
+package reqctl
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BalanceStrategy defines how an endpoint pool spreads the attempts across its endpoints
+type BalanceStrategy int
+
+const (
+	// RoundRobin sends the attempts to the endpoints in turn ( default )
+	RoundRobin = BalanceStrategy(iota)
+	// Weighted sends the attempts to the endpoints in proportion to their weights, interleaving them smoothly
+	Weighted
+	// LeastPending sends every attempt to the endpoint with the fewest attempts awaiting response headers
+	LeastPending
+)
+
+// Endpoint is a replica of the upstream, requests are sent to it under its scheme, host & path prefix
+type Endpoint struct {
+	URL *url.URL
+	// Weight is the share of the endpoint under the Weighted strategy, values below 1 are 1
+	Weight int
+}
+
+// endpointWeight returns the effective weight of the endpoint
+func endpointWeight(endpoint Endpoint) int {
+	if endpoint.Weight < 1 {
+		return 1
+	}
+	return endpoint.Weight
+}
+
+// poolEndpoint is an endpoint along with its balancing state
+type poolEndpoint struct {
+	url     *url.URL
+	weight  int
+	current int
+	pending int64
+}
+
+// OutlierDetection defines when an endpoint of a pool is deemed unhealthy & ejected from selection
+type OutlierDetection struct {
+	// FailureRate is the ratio of failed attempts within the window beyond which the endpoint is ejected, eg: 0.5
+	FailureRate float64
+	// MinRequests is the number of attempts within the window below which the endpoint is never ejected
+	MinRequests int
+	// Window is the period over which the failure rate is computed
+	Window time.Duration
+	// EjectionTime is the period during which an ejected endpoint receives no attempts
+	EjectionTime time.Duration
+}
+
+// EndpointPool spreads the attempts of the controllers sharing it, including retries & parallel calls,
+// across a set of replicas instead of a single URL
+type EndpointPool struct {
+	strategy  BalanceStrategy
+	endpoints []*poolEndpoint
+
+	mu        sync.Mutex
+	next      int
+	detection *OutlierDetection
+	store     StateStore
+}
+
+// NewEndpointPool creates a pool balancing the attempts across the endpoints as per the strategy
+func NewEndpointPool(strategy BalanceStrategy, endpoints ...Endpoint) *EndpointPool {
+	pool := &EndpointPool{
+		strategy: strategy,
+	}
+
+	for _, endpoint := range endpoints {
+		pool.endpoints = append(pool.endpoints, &poolEndpoint{url: endpoint.URL, weight: endpointWeight(endpoint)})
+	}
+	return pool
+}
+
+// SetOutlierDetection tracks the failure rate of every endpoint, as classified by the retry checker, and ejects
+// the unhealthy ones from selection for the ejection time. The first attempt sent to an endpoint after its ejection
+// probes it: a failure ejects it again right away. The health is kept in the store, shared by the pools & processes
+// using it, or else in the process wide in-memory store. When every endpoint is ejected, all of them are selected.
+func (p *EndpointPool) SetOutlierDetection(detection OutlierDetection, store StateStore) *EndpointPool {
+	if store == nil {
+		store = defaultStore
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.detection = &detection
+	p.store = store
+	return p
+}
+
+// Ejected returns the endpoints currently ejected from selection
+func (p *EndpointPool) Ejected(ctx context.Context) []*url.URL {
+	ejected := p.ejected(ctx)
+
+	var res []*url.URL
+	for _, ep := range p.endpoints {
+		if ejected[ep] {
+			res = append(res, ep.url)
+		}
+	}
+	return res
+}
+
+// ejected returns the endpoints ejected as per the store, read without the lock held as the store may be remote
+func (p *EndpointPool) ejected(ctx context.Context) map[*poolEndpoint]bool {
+	p.mu.Lock()
+	detection, store := p.detection, p.store
+	p.mu.Unlock()
+	if detection == nil {
+		return nil
+	}
+
+	ejected := map[*poolEndpoint]bool{}
+	for _, ep := range p.endpoints {
+		if n, err := store.Get(ctx, healthKey(ep, "ejected")); err == nil && n > 0 {
+			ejected[ep] = true
+		}
+	}
+	return ejected
+}
+
+// healthKey returns the store key of the endpoint health counter
+func healthKey(ep *poolEndpoint, name string) string {
+	return "reqctl:endpoint:" + ep.url.String() + ":" + name
+}
+
+// healthWindow returns the suffix of the counters of the failure rate window at now. The total & the failures are
+// counted in the same window, aligned on the wall clock so that the processes sharing the store agree on it.
+func healthWindow(detection *OutlierDetection, now time.Time) string {
+	if detection.Window <= 0 {
+		return ""
+	}
+	return ":" + strconv.FormatInt(now.UnixNano()/int64(detection.Window), 10)
+}
+
+// pick selects the endpoint of the next attempt, nil if the pool is empty
+func (p *EndpointPool) pick(ctx context.Context) *poolEndpoint {
+	if len(p.endpoints) == 0 {
+		return nil
+	}
+	ejected := p.ejected(ctx)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	endpoints := p.endpoints
+	if len(ejected) > 0 {
+		healthy := make([]*poolEndpoint, 0, len(p.endpoints))
+		for _, ep := range p.endpoints {
+			if !ejected[ep] {
+				healthy = append(healthy, ep)
+			}
+		}
+		if len(healthy) > 0 {
+			endpoints = healthy
+		}
+	}
+
+	var picked *poolEndpoint
+	switch p.strategy {
+	case Weighted:
+		// Smooth weighted round robin, every endpoint gains its weight & the picked one pays the total
+		total := 0
+		for _, ep := range endpoints {
+			ep.current += ep.weight
+			total += ep.weight
+			if picked == nil || ep.current > picked.current {
+				picked = ep
+			}
+		}
+		picked.current -= total
+	case LeastPending:
+		// Ties are broken in turn, so that idle endpoints share the load
+		n := len(endpoints)
+		for i := 0; i < n; i++ {
+			ep := endpoints[(p.next+i)%n]
+			if picked == nil || atomic.LoadInt64(&ep.pending) < atomic.LoadInt64(&picked.pending) {
+				picked = ep
+			}
+		}
+		p.next = (p.next + 1) % len(p.endpoints)
+	default:
+		picked = endpoints[p.next%len(endpoints)]
+		p.next = (p.next + 1) % len(p.endpoints)
+	}
+
+	atomic.AddInt64(&picked.pending, 1)
+	return picked
+}
+
+// done registers the outcome of an attempt sent to the endpoint, ejecting it once unhealthy. Outcomes not counted,
+// eg: of attempts cancelled or never sent, leave the health of the endpoint as is.
+func (p *EndpointPool) done(ctx context.Context, ep *poolEndpoint, counted, failed bool) {
+	atomic.AddInt64(&ep.pending, -1)
+
+	p.mu.Lock()
+	detection, store := p.detection, p.store
+	p.mu.Unlock()
+	if detection == nil || !counted {
+		return
+	}
+
+	window := healthWindow(detection, time.Now())
+	totalKey, failuresKey := healthKey(ep, "total"+window), healthKey(ep, "failures"+window)
+	total, err := store.Add(ctx, totalKey, 1, 2*detection.Window)
+	if err != nil {
+		return
+	}
+
+	// A success ends the probation of an endpoint back from ejection
+	if !failed {
+		_ = store.Set(ctx, healthKey(ep, "probation"), 0, detection.Window)
+		return
+	}
+
+	failures, err := store.Add(ctx, failuresKey, 1, 2*detection.Window)
+	if err != nil {
+		return
+	}
+	probation, _ := store.Get(ctx, healthKey(ep, "probation"))
+
+	if probation > 0 || (total >= int64(detection.MinRequests) &&
+		float64(failures) >= detection.FailureRate*float64(total)) {
+		_ = store.Set(ctx, healthKey(ep, "ejected"), 1, detection.EjectionTime)
+		_ = store.Set(ctx, healthKey(ep, "probation"), 1, detection.EjectionTime+detection.Window)
+		_ = store.Set(ctx, failuresKey, 0, 2*detection.Window)
+		_ = store.Set(ctx, totalKey, 0, 2*detection.Window)
+	}
+}
+
+// SetEndpointPool sends every attempt of the request to an endpoint of the pool, in place of the request host
+func (c Controller) SetEndpointPool(pool *EndpointPool) Controller {
+	c.config.pool = pool
+	return c
+}
@@ -0,0 +1,178 @@
+package reqctl_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+// hostCounter is a client counting the attempts received by every host
+type hostCounter struct {
+	mu    sync.Mutex
+	hosts map[string]int
+	delay map[string]time.Duration
+}
+
+func (h *hostCounter) client() *http.Client {
+	return &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			h.mu.Lock()
+			h.hosts[r.URL.Host]++
+			delay := h.delay[r.URL.Host]
+			h.mu.Unlock()
+
+			time.Sleep(delay)
+			return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+}
+
+func endpoint(host string, weight int) reqctl.Endpoint {
+	return reqctl.Endpoint{URL: &url.URL{Scheme: "http", Host: host}, Weight: weight}
+}
+
+func TestEndpointPool(t *testing.T) {
+	do := func(pool *reqctl.EndpointPool, counter *hostCounter, retries int) {
+		request, _ := http.NewRequest("GET", "http://primary/items", nil)
+		_, _ = reqctl.Request(context.Background(), request).
+			SetSimpleRetryWithChecker(0, retries, reqctl.RetryOnStatus(503)).
+			SetEndpointPool(pool).
+			SetClient(counter.client()).
+			Do()
+	}
+
+	counter := &hostCounter{hosts: map[string]int{}}
+	do(reqctl.NewEndpointPool(reqctl.RoundRobin, endpoint("a", 0), endpoint("b", 0), endpoint("c", 0)), counter, 5)
+	if counter.hosts["a"] != 2 || counter.hosts["b"] != 2 || counter.hosts["c"] != 2 || counter.hosts["primary"] != 0 {
+		t.Errorf("Expected the attempts to be spread in turn, got %v", counter.hosts)
+	}
+
+	counter = &hostCounter{hosts: map[string]int{}}
+	do(reqctl.NewEndpointPool(reqctl.Weighted, endpoint("a", 3), endpoint("b", 1)), counter, 7)
+	if counter.hosts["a"] != 6 || counter.hosts["b"] != 2 {
+		t.Errorf("Expected the attempts to follow the weights, got %v", counter.hosts)
+	}
+}
+
+func TestEndpointPoolLeastPending(t *testing.T) {
+	counter := &hostCounter{
+		hosts: map[string]int{},
+		delay: map[string]time.Duration{"slow": 100 * time.Millisecond},
+	}
+	pool := reqctl.NewEndpointPool(reqctl.LeastPending, endpoint("slow", 0), endpoint("fast", 0))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			request, _ := http.NewRequest("GET", "http://primary", nil)
+			_, _ = reqctl.Request(context.Background(), request).
+				SetEndpointPool(pool).
+				SetClient(counter.client()).
+				Do()
+		}()
+		time.Sleep(5 * time.Millisecond)
+	}
+	wg.Wait()
+
+	if counter.hosts["slow"] != 1 || counter.hosts["fast"] != 4 {
+		t.Errorf("Expected the busy endpoint to be avoided, got %v", counter.hosts)
+	}
+}
+
+func TestEndpointPoolOutlierDetection(t *testing.T) {
+	counter := &hostCounter{hosts: map[string]int{}}
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			counter.mu.Lock()
+			counter.hosts[r.URL.Host]++
+			counter.mu.Unlock()
+
+			status := http.StatusOK
+			if r.URL.Host == "bad" {
+				status = http.StatusServiceUnavailable
+			}
+			return &http.Response{StatusCode: status, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	pool := reqctl.NewEndpointPool(reqctl.RoundRobin, endpoint("bad", 0), endpoint("good", 0)).
+		SetOutlierDetection(reqctl.OutlierDetection{
+			FailureRate:  0.5,
+			MinRequests:  2,
+			Window:       time.Minute,
+			EjectionTime: 50 * time.Millisecond,
+		}, reqctl.NewMemoryStore())
+	policy := reqctl.NewPolicy().
+		WithSimpleRetryWithChecker(0, 0, reqctl.RetryOnStatus(503)).
+		WithEndpointPool(pool).
+		WithClient(client)
+
+	do := func(n int) {
+		for i := 0; i < n; i++ {
+			request, _ := http.NewRequest("GET", "http://primary", nil)
+			if resp, err := policy.Do(context.Background(), request); err == nil {
+				resp.Body.Close()
+			}
+		}
+	}
+
+	do(10)
+	if counter.hosts["bad"] != 2 || counter.hosts["good"] != 8 {
+		t.Errorf("Expected the failing endpoint to be ejected after 2 attempts, got %v", counter.hosts)
+	}
+	if ejected := pool.Ejected(context.Background()); len(ejected) != 1 || ejected[0].Host != "bad" {
+		t.Errorf("Expected the failing endpoint to be reported as ejected, got %v", ejected)
+	}
+
+	// Once the ejection ends, a single failed probe ejects the endpoint again
+	time.Sleep(60 * time.Millisecond)
+	do(10)
+	if counter.hosts["bad"] != 3 {
+		t.Errorf("Expected a single probe of the failing endpoint, got %v", counter.hosts)
+	}
+}
+
+func TestEndpointPoolOutlierDetectionHedged(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			select {
+			case <-r.Context().Done():
+				return nil, r.Context().Err()
+			case <-time.After(20 * time.Millisecond):
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	pool := reqctl.NewEndpointPool(reqctl.RoundRobin, endpoint("a", 0), endpoint("b", 0)).
+		SetOutlierDetection(reqctl.OutlierDetection{
+			FailureRate:  0.4,
+			MinRequests:  4,
+			Window:       time.Minute,
+			EjectionTime: time.Minute,
+		}, reqctl.NewMemoryStore())
+	policy := reqctl.NewPolicy().
+		WithParallelCallSchedule(0, 0, 5*time.Millisecond).
+		WithEndpointPool(pool).
+		WithClient(client)
+
+	// The losing parallel calls are cancelled by the winner, which says nothing of their endpoint
+	for i := 0; i < 8; i++ {
+		request, _ := http.NewRequest("GET", "http://primary", nil)
+		resp, err := policy.Do(context.Background(), request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if ejected := pool.Ejected(context.Background()); len(ejected) != 0 {
+		t.Errorf("Expected the healthy endpoints to stay in selection, got %v ejected", ejected)
+	}
+}
@@ -0,0 +1,68 @@
+package reqctl
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// PreflightError is returned for an attempt whose upload was skipped, as the preflight indicated
+// that the target is down or would reject it.
+type PreflightError struct {
+	Method     string
+	StatusCode int
+}
+
+// Error describes the rejected preflight
+func (e *PreflightError) Error() string {
+	return fmt.Sprintf("reqctl: %s preflight rejected with status %d, upload skipped", e.Method, e.StatusCode)
+}
+
+// preflightConfig holds the configuration of the preflight issued before uploads
+type preflightConfig struct {
+	method      string
+	minBodySize int64
+	accept      func(*http.Response) bool
+}
+
+// SetPreflight issues a cheap preflight ( HEAD or OPTIONS ) before every attempt uploading at least minBodySize bytes,
+// or a body of unknown size. When the preflight fails or is not accepted, the upload is skipped & the attempt fails
+// with the preflight error or a *PreflightError, saving bandwidth during outages. A nil accept allows any status
+// below 400, along with 405 for targets not supporting the preflight method.
+func (c Controller) SetPreflight(method string, minBodySize int64, accept func(*http.Response) bool) Controller {
+	if accept == nil {
+		accept = func(resp *http.Response) bool {
+			return resp.StatusCode < 400 || resp.StatusCode == http.StatusMethodNotAllowed
+		}
+	}
+
+	c.config.preflight = &preflightConfig{
+		method:      method,
+		minBodySize: minBodySize,
+		accept:      accept,
+	}
+	return c
+}
+
+// doPreflight issues the preflight for the attempt, if it uploads a large body
+func (c *Controller) doPreflight(client *http.Client, req *http.Request) error {
+	cfg := c.config.preflight
+	if cfg == nil || req.Body == nil || req.Body == http.NoBody ||
+		(req.ContentLength >= 0 && req.ContentLength < cfg.minBodySize) {
+		return nil
+	}
+
+	preReq := req.Clone(req.Context())
+	preReq.Method = cfg.method
+	preReq.Body, preReq.GetBody, preReq.ContentLength = nil, nil, 0
+
+	resp, err := client.Do(preReq)
+	if err != nil {
+		return err
+	}
+	closeBody(resp)
+
+	if !cfg.accept(resp) {
+		return &PreflightError{Method: cfg.method, StatusCode: resp.StatusCode}
+	}
+	return nil
+}
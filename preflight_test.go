@@ -0,0 +1,56 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestPreflight(t *testing.T) {
+	var methods []string
+	status := http.StatusOK
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			methods = append(methods, r.Method)
+			if r.Method == http.MethodHead {
+				return &http.Response{StatusCode: status, Body: http.NoBody, Request: r}, nil
+			}
+			return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	do := func(body string) error {
+		methods = nil
+		request, _ := http.NewRequest("PUT", "http://localhost/upload", strings.NewReader(body))
+		resp, err := reqctl.Request(context.Background(), request).
+			SetPreflight(http.MethodHead, 4, nil).
+			SetClient(client).
+			Do()
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	if err := do("ab"); err != nil || strings.Join(methods, ",") != "PUT" {
+		t.Errorf("Expected no preflight for small bodies, got %v with %v", methods, err)
+	}
+
+	if err := do("large body"); err != nil || strings.Join(methods, ",") != "HEAD,PUT" {
+		t.Errorf("Expected preflight before the upload, got %v with %v", methods, err)
+	}
+
+	status = http.StatusServiceUnavailable
+	err := do("large body")
+	var preflightErr *reqctl.PreflightError
+	if !errors.As(err, &preflightErr) || preflightErr.StatusCode != 503 {
+		t.Errorf("Expected preflight error with status 503, got %v", err)
+	}
+	if strings.Join(methods, ",") != "HEAD" {
+		t.Errorf("Expected upload to be skipped, got %v", methods)
+	}
+}
@@ -0,0 +1,173 @@
+package reqctl
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// poolCounters is the process wide connection & attempt accounting
+var poolCounters struct {
+	openConns int64
+	inFlight  int64
+}
+
+// PoolStats is a snapshot of the process wide connection usage
+type PoolStats struct {
+	// OpenConns is the number of open connections dialed via DialContext, which includes the shared transport
+	OpenConns int64
+	// InFlight is the number of attempts awaiting response headers, across all controllers
+	InFlight int64
+}
+
+// TransportStats returns the current connection usage
+func TransportStats() PoolStats {
+	return PoolStats{
+		OpenConns: atomic.LoadInt64(&poolCounters.openConns),
+		InFlight:  atomic.LoadInt64(&poolCounters.inFlight),
+	}
+}
+
+// trackedConn keeps the open connection count up to date
+type trackedConn struct {
+	net.Conn
+	once sync.Once
+}
+
+// newTrackedConn registers a freshly dialed connection
+func newTrackedConn(conn net.Conn) net.Conn {
+	atomic.AddInt64(&poolCounters.openConns, 1)
+	return &trackedConn{Conn: conn}
+}
+
+// Close closes the connection & unregisters it
+func (t *trackedConn) Close() error {
+	t.once.Do(func() {
+		atomic.AddInt64(&poolCounters.openConns, -1)
+	})
+	return t.Conn.Close()
+}
+
+// ConnectionGuard defines the connection usage beyond which the controller stops multiplying traffic.
+// A zero limit is not enforced.
+type ConnectionGuard struct {
+	MaxOpenConns int64
+	MaxInFlight  int64
+}
+
+// exceeded reports whether the current usage is beyond the guard limits
+func (g *ConnectionGuard) exceeded() bool {
+	if g == nil {
+		return false
+	}
+
+	stats := TransportStats()
+	return (g.MaxOpenConns > 0 && stats.OpenConns >= g.MaxOpenConns) ||
+		(g.MaxInFlight > 0 && stats.InFlight >= g.MaxInFlight)
+}
+
+// SetConnectionGuard suppresses parallel calls & further retries while the connection usage exceeds the guard,
+// protecting the process from file descriptor exhaustion during upstream brownouts.
+func (c Controller) SetConnectionGuard(guard ConnectionGuard) Controller {
+	c.config.connGuard = &guard
+	return c
+}
+
+// Pressure is a snapshot of the saturation of the resources a controller depends on, letting callers shed or defer
+// work before constructing requests. Limits which are not configured are reported as 0.
+type Pressure struct {
+	// BreakerOpen reports whether the circuit breaker currently rejects requests
+	BreakerOpen bool
+	// InFlight & MaxInFlight are the attempts awaiting response headers & the connection guard limit
+	InFlight, MaxInFlight int64
+	// OpenConns & MaxOpenConns are the open connections & the connection guard limit
+	OpenConns, MaxOpenConns int64
+	// MemoryInUse & MemoryMax are the bytes buffered under the memory budget & its size
+	MemoryInUse, MemoryMax int64
+	// Concurrent & MaxConcurrent are the attempts holding a bulkhead slot & the bulkhead limit
+	Concurrent, MaxConcurrent int64
+	// Queued & MaxQueued are the attempts waiting for a bulkhead slot & the bulkhead queue size
+	Queued, MaxQueued int64
+	// LimiterTokens & LimiterBurst are the tokens available to the rate limiter & its burst, reported by the limiters
+	// exposing them like *rate.Limiter does
+	LimiterTokens float64
+	LimiterBurst  int64
+	// PacerQueued & PacerWait are the attempts waiting for their turn of the pacer & the wait of a new attempt
+	PacerQueued int64
+	PacerWait   time.Duration
+	// Level is the highest utilization across the configured limits, 1 or above when saturated
+	Level float64
+}
+
+// Saturated reports whether requests are currently rejected or degraded by any of the limits
+func (p Pressure) Saturated() bool {
+	return p.Level >= 1
+}
+
+// utilization updates the level with the usage of a limit, if configured
+func (p *Pressure) utilization(used, limit int64) {
+	if limit <= 0 {
+		return
+	}
+	if level := float64(used) / float64(limit); level > p.Level {
+		p.Level = level
+	}
+}
+
+// Pressure returns the current saturation of the controller. It does not block, except for breakers backed by
+// an external store which is read to obtain the breaker state.
+func (c Controller) Pressure() Pressure {
+	stats := TransportStats()
+	res := Pressure{
+		InFlight:  stats.InFlight,
+		OpenConns: stats.OpenConns,
+	}
+
+	if guard := c.config.connGuard; guard != nil {
+		res.MaxInFlight, res.MaxOpenConns = guard.MaxInFlight, guard.MaxOpenConns
+		res.utilization(res.InFlight, res.MaxInFlight)
+		res.utilization(res.OpenConns, res.MaxOpenConns)
+	}
+
+	if budget := c.config.memBudget; budget != nil {
+		res.MemoryInUse, res.MemoryMax = budget.InUse(), budget.max
+		res.utilization(res.MemoryInUse, res.MemoryMax)
+	}
+
+	if bulkhead := c.config.bulkhead; bulkhead != nil {
+		res.Concurrent, res.Queued = bulkhead.usage()
+		res.MaxConcurrent, res.MaxQueued = int64(bulkhead.Limit("")), int64(bulkhead.maxQueue)
+		if !bulkhead.perHost {
+			res.utilization(res.Concurrent, res.MaxConcurrent)
+			res.utilization(res.Queued, res.MaxQueued)
+		}
+	}
+
+	if limiter, ok := c.config.limiter.(limiterTokens); ok {
+		res.LimiterTokens, res.LimiterBurst = limiter.Tokens(), int64(limiter.Burst())
+		if res.LimiterBurst > 0 {
+			// Attempts wait for the limiter once its tokens are exhausted
+			if level := 1 - res.LimiterTokens/float64(res.LimiterBurst); level > res.Level {
+				res.Level = level
+			}
+		}
+	}
+
+	if pacer := c.config.pacer; pacer != nil {
+		res.PacerQueued, res.PacerWait = pacer.usage(time.Now())
+		res.utilization(int64(res.PacerWait), int64(pacer.interval))
+	}
+
+	if breaker := c.config.breaker; breaker != nil && breaker.State(context.Background()) == BreakerOpen {
+		res.BreakerOpen = true
+		res.Level = 1
+	}
+	return res
+}
+
+// Pressure returns the current saturation of the policy, refer Controller.Pressure
+func (p Policy) Pressure() Pressure {
+	return p.template.Pressure()
+}
@@ -0,0 +1,148 @@
+package reqctl_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestTransportStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	transport := &http.Transport{DialContext: reqctl.DialContext(&net.Dialer{})}
+	client := &http.Client{Transport: transport}
+
+	request, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	before := reqctl.TransportStats().OpenConns
+	resp, err := reqctl.Request(context.Background(), request).SetClient(client).Do()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+	resp.Body.Close()
+
+	if open := reqctl.TransportStats().OpenConns; open != before+1 {
+		t.Errorf("Expected %d open connections, got %d", before+1, open)
+	}
+
+	transport.CloseIdleConnections()
+	if open := reqctl.TransportStats().OpenConns; open != before {
+		t.Errorf("Expected %d open connections after close, got %d", before, open)
+	}
+}
+
+func TestConnectionGuard(t *testing.T) {
+	release := make(chan struct{})
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/blocked" {
+			<-release
+		}
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	// Keep one attempt in flight, which saturates the guard
+	blocked, _ := http.NewRequest("GET", server.URL+"/blocked", nil)
+	go func() {
+		_, _ = reqctl.Request(context.Background(), blocked).Do()
+	}()
+
+	for reqctl.TransportStats().InFlight == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	request, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	checker := func(resp *http.Response, err error) bool {
+		return err != nil || resp.StatusCode >= 500
+	}
+
+	_, err = reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(time.Millisecond, 3, checker).
+		SetConnectionGuard(reqctl.ConnectionGuard{MaxInFlight: 1}).
+		Do()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Expected retries to be suppressed, got %d calls", n)
+	}
+}
+
+func TestPressure(t *testing.T) {
+	budget := reqctl.NewMemoryBudget(100, reqctl.FallbackShed)
+	breaker := reqctl.NewCircuitBreaker("pressure-test", 1, time.Minute, time.Minute)
+	policy := reqctl.NewPolicy().
+		WithMemoryBudget(budget).
+		WithCircuitBreaker(breaker).
+		WithConnectionGuard(reqctl.ConnectionGuard{MaxInFlight: 1000})
+
+	// Attempts of other tests may still be in flight, hence the guard stays far from saturation
+	if p := policy.Pressure(); p.Saturated() || p.Level > 0.1 || p.MaxInFlight != 1000 {
+		t.Errorf("Expected no pressure, got %+v", p)
+	}
+
+	budget.Reserve(50)
+	if p := policy.Pressure(); p.Level != 0.5 || p.MemoryInUse != 50 {
+		t.Errorf("Expected half the memory budget in use, got %+v", p)
+	}
+
+	breaker.Record(context.Background(), true)
+	if p := policy.Pressure(); !p.Saturated() || !p.BreakerOpen {
+		t.Errorf("Expected saturation with open breaker, got %+v", p)
+	}
+}
+
+// tokenLimiter is a limiter reporting its tokens like *rate.Limiter
+type tokenLimiter struct {
+	tokens float64
+}
+
+func (l *tokenLimiter) Wait(ctx context.Context) error { return nil }
+func (l *tokenLimiter) Tokens() float64                { return l.tokens }
+func (l *tokenLimiter) Burst() int                     { return 10 }
+
+func TestPressureRateLimits(t *testing.T) {
+	limiter := &tokenLimiter{tokens: 10}
+	pacer := reqctl.NewPacer(time.Hour)
+	policy := reqctl.NewPolicy().WithRateLimiter(limiter).WithPacer(pacer, "")
+	if p := policy.Pressure(); p.Saturated() || p.LimiterTokens != 10 || p.LimiterBurst != 10 {
+		t.Errorf("Expected no pressure, got %+v", p)
+	}
+
+	limiter.tokens = 0
+	if p := policy.Pressure(); !p.Saturated() {
+		t.Errorf("Expected saturation once the limiter tokens are exhausted, got %+v", p)
+	}
+
+	// The turn of the next attempt is an interval away once an attempt is paced
+	limiter.tokens = 10
+	if err := pacer.Wait(context.Background(), ""); err != nil {
+		t.Fatal(err)
+	}
+	if p := policy.Pressure(); p.PacerWait < 59*time.Minute || p.Level < 0.99 {
+		t.Errorf("Expected the pacer wait to be reported, got %+v", p)
+	}
+}
@@ -0,0 +1,47 @@
+package reqctl
+
+import (
+	"io"
+	"net/http"
+)
+
+// ProgressFunc is notified of the bytes sent out of the total, -1 if the total is unknown
+type ProgressFunc func(sent, total int64)
+
+// progressBody reports the bytes read from the body as they are read
+type progressBody struct {
+	io.ReadCloser
+	sent  int64
+	total int64
+	fn    ProgressFunc
+}
+
+// Read reads the body & reports the progress
+func (b *progressBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.sent += int64(n)
+		b.fn(b.sent, b.total)
+	}
+	return n, err
+}
+
+// SetUploadProgress reports the progress of the request body of every attempt, each attempt reporting from 0
+// as it resends the body. The total is the content length of the request.
+func (c Controller) SetUploadProgress(fn ProgressFunc) Controller {
+	c.config.progress = fn
+	return c
+}
+
+// trackUpload wraps the body of the attempt to report its progress
+func (c *Controller) trackUpload(req *http.Request) {
+	if c.config.progress == nil || req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+
+	total := req.ContentLength
+	if total == 0 {
+		total = -1
+	}
+	req.Body = &progressBody{ReadCloser: req.Body, total: total, fn: c.config.progress}
+}
@@ -0,0 +1,60 @@
+package reqctl_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestUploadProgress(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		if calls++; calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var reports [][2]int64
+	body := bytes.Repeat([]byte("x"), 100_000)
+	resp, err := reqctl.Post(context.Background(), server.URL).
+		WithBody("application/octet-stream", bytes.NewReader(body)).
+		Configure(func(c reqctl.Controller) reqctl.Controller {
+			return c.SetSimpleRetryWithChecker(time.Millisecond, 1, reqctl.RetryOnStatus(503)).
+				SetRetryNonIdempotent(true).
+				SetUploadProgress(func(sent, total int64) {
+					mu.Lock()
+					defer mu.Unlock()
+					reports = append(reports, [2]int64{sent, total})
+				})
+		}).
+		Do()
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the retried upload to succeed, got %v, %v", resp, err)
+	}
+	resp.Body.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	restarts := 0
+	for i, report := range reports {
+		if report[1] != int64(len(body)) {
+			t.Errorf("Expected the total to be the content length, got %d", report[1])
+		}
+		if i > 0 && report[0] <= reports[i-1][0] {
+			restarts++
+		}
+	}
+	if restarts != 1 || reports[len(reports)-1][0] != int64(len(body)) {
+		t.Errorf("Expected each attempt to report its progress in full, got %v", reports)
+	}
+}
@@ -0,0 +1,464 @@
+package reqctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QueuedRequest is a request persisted by a delivery queue, along with the state of its delivery
+type QueuedRequest struct {
+	ID     string      `json:"id"`
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+	// Policy as per which each delivery is executed
+	Policy PolicySpec `json:"policy"`
+	// Deliveries is the number of deliveries made so far, LastError the outcome of the last one
+	Deliveries int       `json:"deliveries"`
+	LastError  string    `json:"last_error,omitempty"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	NextAt     time.Time `json:"next_at"`
+	// Failures are the attempts of the failed deliveries
+	Failures []AttemptFailure `json:"failures,omitempty"`
+}
+
+// QueueStore persists the requests of a delivery queue. The file store keeps them across process restarts,
+// other storages, eg: bbolt or a database table, can be plugged in by implementing this interface.
+type QueueStore interface {
+	// Put stores the request, replacing the one with the same ID
+	Put(ctx context.Context, req QueuedRequest) error
+	// Delete removes the request with the ID, if any
+	Delete(ctx context.Context, id string) error
+	// List returns all the stored requests
+	List(ctx context.Context) ([]QueuedRequest, error)
+}
+
+// MemoryQueueStore is a QueueStore local to the process, losing the requests on restart
+type MemoryQueueStore struct {
+	mu       sync.Mutex
+	requests map[string]QueuedRequest
+}
+
+// NewMemoryQueueStore creates an empty in-memory queue store
+func NewMemoryQueueStore() *MemoryQueueStore {
+	return &MemoryQueueStore{
+		requests: map[string]QueuedRequest{},
+	}
+}
+
+// Put stores the request
+func (m *MemoryQueueStore) Put(_ context.Context, req QueuedRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[req.ID] = req
+	return nil
+}
+
+// Delete removes the request with the ID
+func (m *MemoryQueueStore) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.requests, id)
+	return nil
+}
+
+// List returns all the stored requests
+func (m *MemoryQueueStore) List(_ context.Context) ([]QueuedRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	requests := make([]QueuedRequest, 0, len(m.requests))
+	for _, req := range m.requests {
+		requests = append(requests, req)
+	}
+	return requests, nil
+}
+
+// FileQueueStore is a QueueStore persisting every request as a JSON file of a directory.
+// Files are replaced atomically, so that a crash never leaves a request half written.
+type FileQueueStore struct {
+	dir string
+}
+
+// NewFileQueueStore creates a store in the directory, creating it if needed
+func NewFileQueueStore(dir string) (*FileQueueStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileQueueStore{dir: dir}, nil
+}
+
+// path returns the file of the request with the ID
+func (f *FileQueueStore) path(id string) string {
+	return filepath.Join(f.dir, id+".json")
+}
+
+// Put writes the request to a temporary file, then renames it over the file of the request
+func (f *FileQueueStore) Put(_ context.Context, req QueuedRequest) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(f.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), f.path(req.ID)); err != nil {
+		return err
+	}
+	return syncDir(f.dir)
+}
+
+// syncDir flushes the entries of the directory, so that a file renamed into it survives a crash.
+// Directories cannot be synced on Windows, where renames are durable on their own.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	if err := d.Sync(); err != nil {
+		d.Close()
+		return err
+	}
+	return d.Close()
+}
+
+// Delete removes the file of the request
+func (f *FileQueueStore) Delete(_ context.Context, id string) error {
+	if err := os.Remove(f.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List reads all the request files of the directory
+func (f *FileQueueStore) List(_ context.Context) ([]QueuedRequest, error) {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []QueuedRequest
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(f.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var req QueuedRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			return nil, fmt.Errorf("reqctl: decoding queued request %s: %w", entry.Name(), err)
+		}
+		requests = append(requests, req)
+	}
+	return requests, nil
+}
+
+// Delivery is the terminal outcome of a queued request
+type Delivery struct {
+	Request QueuedRequest
+	// Delivered reports whether the request obtained a 2xx response
+	Delivered  bool
+	StatusCode int
+	// Err is the error of the last delivery, or the reason the request could not be delivered
+	Err error
+}
+
+// Default redelivery settings of a queue
+const (
+	defaultRedeliveryInterval = time.Second
+	defaultMaxRedelivery      = time.Hour
+	defaultMaxDeliveries      = 10
+	defaultQueuePoll          = time.Minute
+)
+
+// DeliveryQueue delivers the queued requests in the background with at-least-once semantics. Each delivery executes
+// the request as per its policy, including its retries, & failed deliveries are redelivered after an exponential
+// backoff until the maximum number of deliveries. Requests are persisted by the store until their terminal outcome,
+// hence a queue over a durable store resumes the deliveries after a process restart.
+type DeliveryQueue struct {
+	store         QueueStore
+	interval      time.Duration
+	maxInterval   time.Duration
+	maxDeliveries int
+	onOutcome     func(Delivery)
+	deadLetter    DeadLetterSink
+	wake          chan struct{}
+}
+
+// NewDeliveryQueue creates a queue over the store, delivering each request up to 10 times with a backoff
+// from 1s up to 1h
+func NewDeliveryQueue(store QueueStore) *DeliveryQueue {
+	return &DeliveryQueue{
+		store:         store,
+		interval:      defaultRedeliveryInterval,
+		maxInterval:   defaultMaxRedelivery,
+		maxDeliveries: defaultMaxDeliveries,
+		wake:          make(chan struct{}, 1),
+	}
+}
+
+// SetRedeliveryBackoff sets the wait before the first redelivery, doubling for every subsequent one up to max,
+// uncapped if not positive
+func (q *DeliveryQueue) SetRedeliveryBackoff(interval, max time.Duration) *DeliveryQueue {
+	q.interval, q.maxInterval = interval, max
+	return q
+}
+
+// SetMaxDeliveries bounds the deliveries of a request, including the first one
+func (q *DeliveryQueue) SetMaxDeliveries(n int) *DeliveryQueue {
+	q.maxDeliveries = n
+	return q
+}
+
+// SetOutcomeHandler reports the terminal outcome of every request to fn, called from the goroutine running the queue
+func (q *DeliveryQueue) SetOutcomeHandler(fn func(Delivery)) *DeliveryQueue {
+	q.onOutcome = fn
+	return q
+}
+
+// SetDeadLetter sends the requests given up, ie: not delivered by their terminal outcome, to the sink along with the
+// attempts of all their deliveries. Should the sink fail, Run returns its error & the request stays queued.
+func (q *DeliveryQueue) SetDeadLetter(sink DeadLetterSink) *DeliveryQueue {
+	q.deadLetter = sink
+	return q
+}
+
+// Enqueue persists the request for delivery as per the policy, returning its ID. The body is read in full & the
+// request context is not retained, its deliveries being bound to the context of Run. Deliveries are sent afresh,
+// servers should deduplicate them by a key set in the header of the request. It fails with ErrPolicyNotSerializable
+// if the policy cannot be persisted.
+func (q *DeliveryQueue) Enqueue(ctx context.Context, req *http.Request, policy Policy) (string, error) {
+	spec, err := policy.Spec()
+	if err != nil {
+		return "", err
+	}
+
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		reader := req.Body
+		if req.GetBody != nil {
+			if reader, err = req.GetBody(); err != nil {
+				return "", err
+			}
+		}
+		body, err = io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	now := time.Now()
+	queued := QueuedRequest{
+		ID:         newUUID(),
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Header:     req.Header.Clone(),
+		Body:       body,
+		Policy:     spec,
+		EnqueuedAt: now,
+		NextAt:     now,
+	}
+	if err := q.store.Put(ctx, queued); err != nil {
+		return "", err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return queued.ID, nil
+}
+
+// Run delivers the queued requests as they are due until ctx is done, returning its error then, or the error of
+// the store. A delivery interrupted by ctx is kept queued. Run is meant to be called by a single goroutine.
+func (q *DeliveryQueue) Run(ctx context.Context) error {
+	for {
+		next, err := q.deliverDue(ctx)
+		if err != nil {
+			return err
+		}
+
+		wait := defaultQueuePoll
+		if !next.IsZero() {
+			wait = time.Until(next)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-q.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// deliverDue delivers the requests due in the order they are due, returning when the next one is due
+func (q *DeliveryQueue) deliverDue(ctx context.Context) (time.Time, error) {
+	requests, err := q.store.List(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].NextAt.Before(requests[j].NextAt)
+	})
+
+	var next time.Time
+	for _, queued := range requests {
+		if time.Now().Before(queued.NextAt) {
+			if next.IsZero() || queued.NextAt.Before(next) {
+				next = queued.NextAt
+			}
+			break
+		}
+
+		redeliverAt, err := q.deliver(ctx, queued)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !redeliverAt.IsZero() && (next.IsZero() || redeliverAt.Before(next)) {
+			next = redeliverAt
+		}
+	}
+	return next, nil
+}
+
+// deliver executes the request once as per its policy, then settles its outcome or schedules its redelivery,
+// returning when it is due
+func (q *DeliveryQueue) deliver(ctx context.Context, queued QueuedRequest) (time.Time, error) {
+	policy, err := queued.Policy.Policy()
+	if err != nil {
+		return time.Time{}, q.settle(ctx, Delivery{Request: queued, Err: err})
+	}
+	req, err := http.NewRequestWithContext(ctx, queued.Method, queued.URL, bytes.NewReader(queued.Body))
+	if err != nil {
+		return time.Time{}, q.settle(ctx, Delivery{Request: queued, Err: err})
+	}
+	req.Header = queued.Header.Clone()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+
+	res := policy.DoResult(ctx, req)
+	closeBody(res.Response)
+	if ctx.Err() != nil {
+		return time.Time{}, ctx.Err()
+	}
+
+	queued.Deliveries++
+	outcome := Delivery{Err: res.Err}
+	if res.Response != nil {
+		outcome.StatusCode = res.Response.StatusCode
+		outcome.Delivered = res.Err == nil && res.Response.StatusCode/100 == 2
+	}
+	if !outcome.Delivered {
+		queued.LastError = describeOutcome(outcome)
+		failures := attemptFailures(res.records, queued.Deliveries)
+		if len(failures) == 0 {
+			// The delivery failed before any attempt, eg: on an open circuit breaker
+			failures = []AttemptFailure{{At: time.Now(), Delivery: queued.Deliveries, Err: queued.LastError}}
+		}
+		queued.Failures = append(queued.Failures, failures...)
+	}
+	outcome.Request = queued
+	if outcome.Delivered || !redeliverable(outcome) || queued.Deliveries >= q.maxDeliveries {
+		return time.Time{}, q.settle(ctx, outcome)
+	}
+
+	queued.NextAt = time.Now().Add(q.redeliveryBackoff(queued.Deliveries))
+	return queued.NextAt, q.store.Put(ctx, queued)
+}
+
+// redeliverable reports whether the failed delivery may succeed later, ie: unless the request was rejected by a 4xx
+// other than 408 Request Timeout & 429 Too Many Requests
+func redeliverable(outcome Delivery) bool {
+	status := outcome.StatusCode
+	return outcome.Err != nil || status/100 != 4 || status == http.StatusRequestTimeout ||
+		status == http.StatusTooManyRequests
+}
+
+// describeOutcome describes the failed delivery
+func describeOutcome(outcome Delivery) string {
+	if outcome.Err != nil {
+		return outcome.Err.Error()
+	}
+	return fmt.Sprintf("status %d", outcome.StatusCode)
+}
+
+// redeliveryBackoff returns the wait before the redelivery following the deliveries made
+func (q *DeliveryQueue) redeliveryBackoff(deliveries int) time.Duration {
+	wait := q.interval
+	for i := 1; i < deliveries && (q.maxInterval <= 0 || wait < q.maxInterval) && wait <= math.MaxInt64/2; i++ {
+		wait *= 2
+	}
+	if q.maxInterval > 0 && wait > q.maxInterval {
+		wait = q.maxInterval
+	}
+	return wait
+}
+
+// settle sends the request given up to the dead letter sink, then removes it from the store & reports its terminal
+// outcome
+func (q *DeliveryQueue) settle(ctx context.Context, outcome Delivery) error {
+	if outcome.Err == nil && !outcome.Delivered {
+		outcome.Err = fmt.Errorf("reqctl: delivery failed with status %d", outcome.StatusCode)
+	}
+	if !outcome.Delivered && q.deadLetter != nil {
+		queued := outcome.Request
+		failures := queued.Failures
+		if len(failures) == 0 {
+			// The request could not be sent at all
+			failures = []AttemptFailure{{At: time.Now(), Delivery: queued.Deliveries, Err: outcome.Err.Error()}}
+		}
+		letter := newDeadLetter(queued.Method, queued.URL, queued.Header, queued.Body, failures)
+		if err := q.deadLetter.DeadLetter(ctx, letter); err != nil {
+			return err
+		}
+	}
+
+	if err := q.store.Delete(ctx, outcome.Request.ID); err != nil {
+		return err
+	}
+	if q.onOutcome != nil {
+		q.onOutcome(outcome)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package reqctl_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+// runQueue runs the queue until the first terminal outcome
+func runQueue(t *testing.T, q *reqctl.DeliveryQueue) reqctl.Delivery {
+	outcomes := make(chan reqctl.Delivery, 1)
+	q.SetOutcomeHandler(func(d reqctl.Delivery) { outcomes <- d })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = q.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	select {
+	case d := <-outcomes:
+		return d
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected a terminal outcome")
+		return reqctl.Delivery{}
+	}
+}
+
+func TestDeliveryQueueAcrossRestarts(t *testing.T) {
+	var calls int64
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		if atomic.AddInt64(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body = string(data)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	store, err := reqctl.NewFileQueueStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("payload"))
+	id, err := reqctl.NewDeliveryQueue(store).Enqueue(context.Background(), req, reqctl.NewPolicy())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The request is delivered by a queue over the same directory, as if the process restarted
+	store, _ = reqctl.NewFileQueueStore(dir)
+	q := reqctl.NewDeliveryQueue(store).SetRedeliveryBackoff(time.Millisecond, 10*time.Millisecond)
+	d := runQueue(t, q)
+	if !d.Delivered || d.Err != nil || d.Request.ID != id || d.Request.Deliveries != 3 {
+		t.Errorf("Expected the request to be delivered on the third delivery, got %+v", d)
+	}
+	if body != "payload" {
+		t.Errorf("Expected the body to be persisted, got %q", body)
+	}
+	if pending, _ := store.List(context.Background()); len(pending) != 0 {
+		t.Errorf("Expected the delivered request to be removed, got %d pending", len(pending))
+	}
+}
+
+func TestDeliveryQueueRejected(t *testing.T) {
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	q := reqctl.NewDeliveryQueue(reqctl.NewMemoryQueueStore()).SetRedeliveryBackoff(time.Millisecond, time.Millisecond)
+	req, _ := http.NewRequest(http.MethodPost, server.URL, nil)
+	if _, err := q.Enqueue(context.Background(), req, reqctl.NewPolicy()); err != nil {
+		t.Fatal(err)
+	}
+
+	d := runQueue(t, q)
+	if d.Delivered || d.Err == nil || d.StatusCode != http.StatusBadRequest || atomic.LoadInt64(&calls) != 1 {
+		t.Errorf("Expected the rejected request not to be redelivered, got %+v after %d calls", d, calls)
+	}
+}
+
+func TestDeliveryQueueMaxDeliveries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	q := reqctl.NewDeliveryQueue(reqctl.NewMemoryQueueStore()).
+		SetRedeliveryBackoff(time.Millisecond, time.Millisecond).
+		SetMaxDeliveries(2)
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if _, err := q.Enqueue(context.Background(), req, reqctl.NewPolicy()); err != nil {
+		t.Fatal(err)
+	}
+
+	d := runQueue(t, q)
+	if d.Delivered || d.Request.Deliveries != 2 || d.Request.LastError != "status 502" {
+		t.Errorf("Expected the deliveries to stop at the maximum, got %+v", d)
+	}
+}
+
+func TestDeliveryQueueUncappedBackoff(t *testing.T) {
+	var times []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	// Without a maximum, the wait doubles on every redelivery
+	q := reqctl.NewDeliveryQueue(reqctl.NewMemoryQueueStore()).
+		SetRedeliveryBackoff(20*time.Millisecond, 0).
+		SetMaxDeliveries(3)
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if _, err := q.Enqueue(context.Background(), req, reqctl.NewPolicy()); err != nil {
+		t.Fatal(err)
+	}
+
+	if d := runQueue(t, q); d.Request.Deliveries != 3 || len(times) != 3 {
+		t.Fatalf("Expected 3 deliveries, got %+v", d)
+	}
+	if gap := times[2].Sub(times[1]); gap < 35*time.Millisecond {
+		t.Errorf("Expected the second redelivery to wait twice as long, got %v", gap)
+	}
+}
@@ -0,0 +1,30 @@
+package reqctl
+
+import "context"
+
+// Limiter admits the attempts under a rate limit, *rate.Limiter of golang.org/x/time/rate satisfies it
+type Limiter interface {
+	// Wait blocks until an attempt is allowed, returning an error if the context is done before
+	Wait(ctx context.Context) error
+}
+
+// limiterTokens is implemented by the limiters reporting their state, eg: *rate.Limiter
+type limiterTokens interface {
+	Tokens() float64
+	Burst() int
+}
+
+// LimiterFunc adapts a function into a Limiter
+type LimiterFunc func(ctx context.Context) error
+
+// Wait calls the function
+func (f LimiterFunc) Wait(ctx context.Context) error {
+	return f(ctx)
+}
+
+// SetRateLimiter makes every attempt of the request, including retries & parallel calls, wait for the limiter
+// before being sent. The limiter is usually shared by the controllers sending to the same upstream quota.
+func (c Controller) SetRateLimiter(limiter Limiter) Controller {
+	c.config.limiter = limiter
+	return c
+}
@@ -0,0 +1,44 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestRateLimiter(t *testing.T) {
+	calls := 0
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	errQuota := errors.New("quota exceeded")
+	tokens := 3
+	limiter := reqctl.LimiterFunc(func(ctx context.Context) error {
+		if tokens == 0 {
+			return errQuota
+		}
+		tokens--
+		return nil
+	})
+
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	_, err := reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(0, 5, reqctl.RetryOnStatus(503)).
+		SetRateLimiter(limiter).
+		SetClient(client).
+		Do()
+
+	if calls != 3 {
+		t.Errorf("Expected the retries to pass through the limiter, got %d calls", calls)
+	}
+	if !errors.Is(err, errQuota) {
+		t.Errorf("Expected the limiter error, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package reqctl
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// RetryReason classifies why an attempt was retried
+type RetryReason string
+
+const (
+	// ReasonNone is used for attempts that were not retried
+	ReasonNone = RetryReason("")
+	// ReasonConnect is used when the connection to the server could not be established
+	ReasonConnect = RetryReason("connect_error")
+	// ReasonTLS is used when the TLS handshake or certificate verification failed
+	ReasonTLS = RetryReason("tls_error")
+	// ReasonTimeout is used when the attempt timed out
+	ReasonTimeout = RetryReason("timeout")
+	// ReasonNetwork is used for any other transport error, eg: connection reset
+	ReasonNetwork = RetryReason("network_error")
+	// ReasonTooManyRequests is used when the server responded with 429
+	ReasonTooManyRequests = RetryReason("too_many_requests")
+	// ReasonServerError is used when the server responded with 5xx
+	ReasonServerError = RetryReason("server_error")
+	// ReasonCustom is used when the retry checker asked for a retry on any other response
+	ReasonCustom = RetryReason("checker_custom")
+)
+
+// ClassifyRetry returns the reason for retrying an attempt with the given outcome
+func ClassifyRetry(resp *http.Response, err error) RetryReason {
+	if err != nil {
+		return classifyError(err)
+	}
+
+	switch {
+	case resp == nil:
+		return ReasonCustom
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return ReasonTooManyRequests
+	case resp.StatusCode >= 500:
+		return ReasonServerError
+	default:
+		return ReasonCustom
+	}
+}
+
+// classifyError maps a transport error to a retry reason
+func classifyError(err error) RetryReason {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ReasonTimeout
+	}
+
+	var recordErr tls.RecordHeaderError
+	var authorityErr x509.UnknownAuthorityError
+	var certErr x509.CertificateInvalidError
+	var hostErr x509.HostnameError
+	if errors.As(err, &recordErr) || errors.As(err, &authorityErr) ||
+		errors.As(err, &certErr) || errors.As(err, &hostErr) {
+		return ReasonTLS
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return ReasonConnect
+	}
+
+	return ReasonNetwork
+}
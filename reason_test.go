@@ -0,0 +1,76 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestClassifyRetry(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+
+	cases := []struct {
+		resp     *http.Response
+		err      error
+		expected reqctl.RetryReason
+	}{
+		{nil, context.DeadlineExceeded, reqctl.ReasonTimeout},
+		{nil, dialErr, reqctl.ReasonConnect},
+		{nil, readErr, reqctl.ReasonNetwork},
+		{&http.Response{StatusCode: 429}, nil, reqctl.ReasonTooManyRequests},
+		{&http.Response{StatusCode: 503}, nil, reqctl.ReasonServerError},
+		{&http.Response{StatusCode: 404}, nil, reqctl.ReasonCustom},
+	}
+
+	for _, c := range cases {
+		if reason := reqctl.ClassifyRetry(c.resp, c.err); reason != c.expected {
+			t.Errorf("Expected reason %q for %v, %v, got %q", c.expected, c.resp, c.err, reason)
+		}
+	}
+}
+
+func TestRetryReasonInContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	var reasons []reqctl.RetryReason
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			attempt, _ := reqctl.AttemptFromContext(r.Context())
+			reasons = append(reasons, attempt.Reason)
+			return http.DefaultTransport.RoundTrip(r)
+		}),
+	}
+
+	checker := func(resp *http.Response, err error) bool {
+		return err != nil || resp.StatusCode >= 500
+	}
+
+	_, err = reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(time.Millisecond, 1, checker).
+		SetClient(client).
+		Do()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+
+	if len(reasons) != 2 || reasons[0] != reqctl.ReasonNone || reasons[1] != reqctl.ReasonServerError {
+		t.Errorf("Expected reasons [%q %q], got %q", reqctl.ReasonNone, reqctl.ReasonServerError, reasons)
+	}
+}
@@ -0,0 +1,67 @@
+package reqctl
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"net/http"
+)
+
+// Renegotiator adjusts the negotiation of the following attempts after an attempt failed, eg: by asking for an
+// uncompressed body or a smaller page. It mutates the given copy of the request, and returns false when the outcome
+// does not call for a renegotiation, leaving the request untouched.
+type Renegotiator func(req *http.Request, resp *http.Response, err error) bool
+
+// SetRenegotiation consults the renegotiators in order after every attempt, until one adjusts the request.
+// An adjusted request is retried as per the retry strategy, irrespective of the retry checker. The renegotiators
+// are consulted as well when consuming the body via DoJSON & the Result helpers fails, eg: on a corrupt compressed
+// body or a *ResponseTooLargeError, the renegotiated request being re-run up to the retry count.
+func (c Controller) SetRenegotiation(renegotiators ...Renegotiator) Controller {
+	c.config.renegotiators = renegotiators
+	return c
+}
+
+// IdentityEncoding renegotiates an uncompressed body ( Accept-Encoding: identity ) once an attempt or the read of
+// its body fails with a decompression error
+func IdentityEncoding() Renegotiator {
+	return func(req *http.Request, _ *http.Response, err error) bool {
+		if !isDecompressionError(err) || req.Header.Get("Accept-Encoding") == "identity" {
+			return false
+		}
+
+		req.Header.Set("Accept-Encoding", "identity")
+		return true
+	}
+}
+
+// isDecompressionError reports whether the error was caused by a corrupt compressed body
+func isDecompressionError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var corruptErr flate.CorruptInputError
+	return errors.Is(err, gzip.ErrHeader) || errors.Is(err, gzip.ErrChecksum) ||
+		errors.Is(err, zlib.ErrHeader) || errors.Is(err, zlib.ErrChecksum) || errors.As(err, &corruptErr)
+}
+
+// retryOutcome decides whether the outcome is retried, returning the controller for the following attempts
+func (c *Controller) retryOutcome(resp *http.Response, err error) (*Controller, bool) {
+	if next, ok := c.renegotiate(resp, err); ok {
+		return next, true
+	}
+	return c, c.shouldRetry(resp, err)
+}
+
+// renegotiate consults the renegotiators on the outcome, returning the controller of the renegotiated request if any
+func (c *Controller) renegotiate(resp *http.Response, err error) (*Controller, bool) {
+	for _, renegotiate := range c.config.renegotiators {
+		next := *c
+		next.req = c.req.Clone(c.ctx)
+		if renegotiate(next.req, resp, err) {
+			return &next, true
+		}
+	}
+	return c, false
+}
@@ -0,0 +1,130 @@
+package reqctl_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestIdentityEncoding(t *testing.T) {
+	var encodings []string
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			encodings = append(encodings, r.Header.Get("Accept-Encoding"))
+			if r.Header.Get("Accept-Encoding") != "identity" {
+				return nil, fmt.Errorf("decoding body: %w", gzip.ErrHeader)
+			}
+			return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+
+	// The checker does not retry errors, the renegotiation does
+	resp, err := reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(0, 3, reqctl.RetryOnStatus(503)).
+		SetRenegotiation(reqctl.IdentityEncoding()).
+		SetClient(client).
+		Do()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+	resp.Body.Close()
+
+	if len(encodings) != 2 || encodings[0] != "gzip" || encodings[1] != "identity" {
+		t.Errorf("Expected a single renegotiated retry, got %v", encodings)
+	}
+
+	if request.Header.Get("Accept-Encoding") != "gzip" {
+		t.Errorf("Expected the original request to be left untouched")
+	}
+}
+
+func TestCustomRenegotiation(t *testing.T) {
+	var limits []string
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			limit := r.URL.Query().Get("limit")
+			limits = append(limits, limit)
+			if n, _ := strconv.Atoi(limit); n > 25 {
+				return &http.Response{StatusCode: 502, Body: http.NoBody, Request: r}, nil
+			}
+			return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	halvePage := func(req *http.Request, resp *http.Response, err error) bool {
+		if err != nil || resp.StatusCode != 502 {
+			return false
+		}
+		query := req.URL.Query()
+		n, _ := strconv.Atoi(query.Get("limit"))
+		query.Set("limit", strconv.Itoa(n/2))
+		req.URL.RawQuery = query.Encode()
+		return true
+	}
+
+	request, _ := http.NewRequest("GET", "http://localhost/items?limit=100", nil)
+	resp, err := reqctl.Request(context.Background(), request).
+		SetSimpleRetry(0, 5).
+		SetRenegotiation(halvePage).
+		SetClient(client).
+		Do()
+	if err != nil || resp.StatusCode != 200 {
+		t.Errorf("Expected success with a smaller page, got %v", err)
+		return
+	}
+
+	if fmt.Sprint(limits) != "[100 50 25]" {
+		t.Errorf("Expected the page to be halved on every retry, got %v", limits)
+	}
+}
+
+func TestIdentityEncodingCorruptBody(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("hello world"))
+	zw.Close()
+	corrupt := buf.Bytes()
+	corrupt[len(corrupt)-8] ^= 0xff // breaks the CRC-32 of the trailer, only detected once the body is read
+
+	var encodings []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Accept-Encoding"))
+		if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(corrupt)
+			return
+		}
+		w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	request, _ := http.NewRequest("GET", server.URL, nil)
+	body, err := reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(0, 3, reqctl.RetryOnStatus(503)).
+		SetRenegotiation(reqctl.IdentityEncoding()).
+		DoResult().
+		String()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+
+	if body != "hello world" {
+		t.Errorf("Expected the uncompressed body, got %q", body)
+	}
+	if len(encodings) != 2 || encodings[1] != "identity" {
+		t.Errorf("Expected a single renegotiated re-run, got %v", encodings)
+	}
+}
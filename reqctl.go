@@ -2,9 +2,13 @@ package reqctl
 
 import (
 	"context"
-	"math"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
-	"sync"
+	"net/url"
+	"strconv"
+	"sync/atomic"
 	"time"
 )
 
@@ -17,6 +21,10 @@ const (
 	exponentialRetry = retryType("exponential")
 )
 
+// ErrBodyNotReplayable is returned when a request body is required to be sent more than once ( retries & parallel calls ),
+// but the request does not provide a GetBody function to recreate it.
+var ErrBodyNotReplayable = errors.New("reqctl: request body is not replayable, set http.Request.GetBody")
+
 // RetryCheckFunc is a function type that determines if a retry should be attempted
 type RetryCheckFunc func(*http.Response, error) bool
 
@@ -26,60 +34,132 @@ type retryConfig struct {
 	RetryType      retryType
 	RetryInterval  time.Duration
 	RetryCheckFunc RetryCheckFunc
+	RetryWeight    WeightedRetryCheckFunc
+	CheckerSpec    *CheckerSpec
 }
 
 // asyncRetryConfig holds the configuration for asynchronous retry
 type asyncRetryConfig struct {
-	Delay time.Duration
+	Delay     time.Duration
+	Accept    func(*http.Response, error) bool
+	Schedule  []time.Duration
+	MaxFanOut int
+
+	Tracker    *LatencyTracker
+	Percentile float64
+	Endpoints  []*url.URL
 }
 
-// ctrl is the internal controller that maintains the state of the request
-type ctrl struct {
+// Controller maintains the configuration & state of a request. It is safe to copy, every setter returns
+// a configured copy, hence a controller can be stored & reused as a template across call sites.
+type Controller struct {
 	ctx    context.Context
 	req    *http.Request
-	config struct {
-		retryCfg *retryConfig
-		asyncCfg *asyncRetryConfig
-		timeout  time.Duration
+	hedged bool
+	// revalidating is set on the background refresh of a stale cached response, which bypasses the cache lookup
+	revalidating bool
+	config       struct {
+		retryCfg           *retryConfig
+		asyncCfg           *asyncRetryConfig
+		timeout            time.Duration
+		client             *http.Client
+		softFail           bool
+		sampler            Sampler
+		pinning            EndpointPinning
+		connectTimeout     time.Duration
+		streaming          bool
+		breaker            *CircuitBreaker
+		connGuard          *ConnectionGuard
+		memBudget          *MemoryBudget
+		tee                io.Writer
+		conflictCfg        *conflictConfig
+		attemptCtx         AttemptContextFunc
+		slo                *SLOTracker
+		hedgePredicate     RequestPredicate
+		jitter             Jitter
+		maxBackoff         time.Duration
+		auth               *Authenticator
+		maxElapsed         time.Duration
+		preflight          *preflightConfig
+		retryAfter         retryAfterConfig
+		retryNonIdempotent bool
+		pacer              *Pacer
+		paceLabel          string
+		renegotiators      []Renegotiator
+		bufferBody         int64
+		shadow             *shadowConfig
+		fallbacks          []*url.URL
+		pool               *EndpointPool
+		resolver           Resolver
+		retryBudget        *RetryBudget
+		limiter            Limiter
+		bulkhead           *Bulkhead
+		hooks              Hooks
+		middlewares        []Middleware
+		mutator            func(attempt int, req *http.Request) error
+		idempotencyHeader  string
+		onExhausted        ExhaustedAction
+		logger             attemptLogger
+		traceTimings       bool
+		dump               *debugDump
+		stats              *statsCollector
+		clock              Clock
+		maxResponseBytes   int64
+		validator          ValidatorFunc
+		bodyRetries        int
+		verifyBody         bool
+		cache              *Cache
+		coalescer          *Coalescer
+		progress           ProgressFunc
+		throttle           *Throttle
+		attemptBandwidth   int64
+		callbackPool       *CallbackPool
+		deadLetter         DeadLetterSink
+		correlationHeader  string
+		attemptHeader      string
 	}
 }
 
-// Request creates a new ctrl instance with the given context and request
-func Request(ctx context.Context, req *http.Request) *ctrl {
-	c := ctrl{
-		ctx: ctx,
-		req: req.Clone(ctx),
-	}
+// Request creates a new Controller with the given context and request
+func Request(ctx context.Context, req *http.Request) *Controller {
+	c := newController()
+	c.ctx = ctx
+	c.req = req.Clone(ctx)
+	return &c
+}
 
+// newController creates a controller with the default configuration, without any request
+func newController() Controller {
+	var c Controller
 	c.config.retryCfg = &retryConfig{
 		RetryType: noRetry,
 	}
-
-	return &c
+	c.config.stats = newStatsCollector()
+	return c
 }
 
 // SetSimpleRetry configures simple retry with default checker
-func (c ctrl) SetSimpleRetry(interval time.Duration, times int) ctrl {
-	return c.setRetryWithChecker(simpleRetry, interval, times, DefaultRetryChecker)
+func (c Controller) SetSimpleRetry(interval time.Duration, times int) Controller {
+	return c.setRetryWithSpec(simpleRetry, interval, times, CheckerSpec{})
 }
 
 // SetSimpleRetryWithChecker configures simple retry with custom checker
-func (c ctrl) SetSimpleRetryWithChecker(interval time.Duration, times int, checker RetryCheckFunc) ctrl {
+func (c Controller) SetSimpleRetryWithChecker(interval time.Duration, times int, checker RetryCheckFunc) Controller {
 	return c.setRetryWithChecker(simpleRetry, interval, times, checker)
 }
 
 // SetExponentialRetry configures exponential retry with default checker
-func (c ctrl) SetExponentialRetry(interval time.Duration, times int) ctrl {
-	return c.setRetryWithChecker(exponentialRetry, interval, times, DefaultRetryChecker)
+func (c Controller) SetExponentialRetry(interval time.Duration, times int) Controller {
+	return c.setRetryWithSpec(exponentialRetry, interval, times, CheckerSpec{})
 }
 
 // SetExponentialRetryWithChecker configures exponential retry with custom checker
-func (c ctrl) SetExponentialRetryWithChecker(interval time.Duration, times int, checker RetryCheckFunc) ctrl {
+func (c Controller) SetExponentialRetryWithChecker(interval time.Duration, times int, checker RetryCheckFunc) Controller {
 	return c.setRetryWithChecker(exponentialRetry, interval, times, checker)
 }
 
 // setRetryWithChecker is a helper function to set retry configuration
-func (c ctrl) setRetryWithChecker(rt retryType, interval time.Duration, times int, checker RetryCheckFunc) ctrl {
+func (c Controller) setRetryWithChecker(rt retryType, interval time.Duration, times int, checker RetryCheckFunc) Controller {
 	cfg := retryConfig{
 		RetryType:      rt,
 		RetryInterval:  interval,
@@ -92,13 +172,68 @@ func (c ctrl) setRetryWithChecker(rt retryType, interval time.Duration, times in
 }
 
 // SetTimeout sets the timeout for the request
-func (c ctrl) SetTimeout(timeout time.Duration) ctrl {
+func (c Controller) SetTimeout(timeout time.Duration) Controller {
 	c.config.timeout = timeout
 	return c
 }
 
+// AttemptContextFunc derives the context of an attempt from the parent context, which carries the attempt metadata.
+// The returned cancel function is invoked once the attempt is discarded or its response body is closed.
+type AttemptContextFunc func(parent context.Context, attempt int) (context.Context, context.CancelFunc)
+
+// SetAttemptContext overrides how the context of every attempt is derived, replacing the timeout set via SetTimeout.
+// It allows full control over the values, deadlines & cancellation of the attempts.
+func (c Controller) SetAttemptContext(fn AttemptContextFunc) Controller {
+	c.config.attemptCtx = fn
+	return c
+}
+
+// SetMaxElapsedTime stops issuing further attempts once the time spent on the request, including backoff waits,
+// would exceed the budget, independent of the retry count. The outcome of the last attempt is returned.
+func (c Controller) SetMaxElapsedTime(max time.Duration) Controller {
+	c.config.maxElapsed = max
+	return c
+}
+
+// SetClient sets the HTTP client used by Do, which otherwise falls back to http.DefaultClient
+func (c Controller) SetClient(client *http.Client) Controller {
+	c.config.client = client
+	return c
+}
+
+// SetSoftFail makes the controller return the last obtained HTTP response with a nil error,
+// when retries are exhausted with an error but an earlier attempt did produce a response.
+func (c Controller) SetSoftFail(softFail bool) Controller {
+	c.config.softFail = softFail
+	return c
+}
+
+// SetResponseTee copies the body of the returned response to the writer as the caller reads it,
+// eg: for on-disk caching, auditing or checksums. Bodies of discarded attempts are not copied.
+func (c Controller) SetResponseTee(w io.Writer) Controller {
+	c.config.tee = w
+	return c
+}
+
+// SetCorrelationHeaders makes every attempt carry the correlation ID and attempt sequence
+// number as request headers, so server-side logs can be joined with the client's.
+// An empty header name disables that header.
+func (c Controller) SetCorrelationHeaders(idHeader, seqHeader string) Controller {
+	c.config.correlationHeader = idHeader
+	c.config.attemptHeader = seqHeader
+	return c
+}
+
+// SetPerAttemptMutator invokes the mutator on the request of every attempt before it is authenticated & sent,
+// eg: to rotate API keys or regenerate nonces & timestamps of signed requests. The attempt is the 1 based sequence
+// number, and errors fail the attempt without sending it.
+func (c Controller) SetPerAttemptMutator(mutator func(attempt int, req *http.Request) error) Controller {
+	c.config.mutator = mutator
+	return c
+}
+
 // SetParallelCallWithDelay configures asynchronous retry
-func (c ctrl) SetParallelCallWithDelay(delay time.Duration) ctrl {
+func (c Controller) SetParallelCallWithDelay(delay time.Duration) Controller {
 	c.config.asyncCfg = &asyncRetryConfig{
 		Delay: delay,
 	}
@@ -106,129 +241,557 @@ func (c ctrl) SetParallelCallWithDelay(delay time.Duration) ctrl {
 	return c
 }
 
-// Do executes the request with the default HTTP client
-func (c ctrl) Do() (*http.Response, error) {
-	return c.do(http.DefaultClient)
+// SetParallelCallFirstAcceptable configures asynchronous retry, where the winner is declared as soon as
+// a call receives response headers accepted by the acceptor, cancelling its sibling right away.
+// Rejected responses are closed, and returned only when no other call is pending.
+func (c Controller) SetParallelCallFirstAcceptable(delay time.Duration, accept func(*http.Response, error) bool) Controller {
+	c.config.asyncCfg = &asyncRetryConfig{
+		Delay:  delay,
+		Accept: accept,
+	}
+
+	return c
+}
+
+// Do executes the request with the configured HTTP client, or the default HTTP client if none is set.
+// When dial level features are enabled without a client, a shared transport honoring them is used.
+func (c Controller) Do() (*http.Response, error) {
+	return c.do(c.client())
 }
 
-// DoWithClient executes the request with the provided HTTP client
-func (c *ctrl) DoWithClient(client *http.Client) (*http.Response, error) {
+// DoWithClient executes the request with the provided HTTP client, overriding the one set via SetClient
+func (c *Controller) DoWithClient(client *http.Client) (*http.Response, error) {
 	return c.do(client)
 }
 
+// client returns the HTTP client used by Do
+func (c *Controller) client() *http.Client {
+	if c.config.client != nil {
+		return c.config.client
+	} else if c.usesDialSettings() {
+		return managedClient
+	}
+	return http.DefaultClient
+}
+
 // DefaultRetryChecker is the default retry function that retries on network errors
 func DefaultRetryChecker(resp *http.Response, err error) bool {
 	return err != nil
 }
 
-// Clone creates a deep copy of the ctrl instance
-func (c *ctrl) Clone() ctrl {
-	retryCfg := *c.config.retryCfg
-	asyncCfg := *c.config.asyncCfg
-
+// Clone creates a deep copy of the Controller
+func (c *Controller) Clone() Controller {
 	res := *c
-	res.config.retryCfg = &retryCfg
-	res.config.asyncCfg = &asyncCfg
+	if c.config.retryCfg != nil {
+		retryCfg := *c.config.retryCfg
+		res.config.retryCfg = &retryCfg
+	}
+	if c.config.asyncCfg != nil {
+		asyncCfg := *c.config.asyncCfg
+		res.config.asyncCfg = &asyncCfg
+	}
 	return res
 }
 
 // do is the main function that handles the request execution
-func (c *ctrl) do(client *http.Client) (*http.Response, error) {
-	if c.config.asyncCfg != nil {
-		return c.doAsync(client)
-	} else {
-		return c.doRetry(client)
+func (c *Controller) do(client *http.Client) (*http.Response, error) {
+	res := c.run(client)
+	return res.Response, res.Err
+}
+
+// run executes the logical request, returning its outcome along with the attempt records
+func (c *Controller) run(client *http.Client) Result {
+	exec := newExecution(c.sample(), c.clock().Now())
+	if resp, ok := c.cachedResponse(); ok {
+		resp, err := c.settle(resp, nil)
+		res := c.newResult(client, exec, resp, err)
+		res.FromCache = true
+		return res
 	}
+
+	c, resp, shared, err := c.coalesce(client, exec)
+	resp, stale, err := c.cacheResponse(resp, err)
+	resp, err = c.settle(resp, err)
+	res := c.newResult(client, exec, resp, err)
+	res.FromCache, res.Shared = stale, shared
+	c.giveUp(res)
+	return res
 }
 
-// doAsync handles asynchronous retry
-func (c *ctrl) doAsync(client *http.Client) (*http.Response, error) {
-	var result *http.Response
-	var resErr error
+// send runs the attempts of the logical request, returning the controller as configured for the execution
+func (c *Controller) send(client *http.Client, exec *execution) (*Controller, *http.Response, error) {
+	exec.addrs = c.resolve()
+
+	endpoints, err := c.resolveEndpoints()
+	if err != nil {
+		return c, nil, err
+	}
+	exec.endpoints, exec.resolvedHost = endpoints, c.req.URL.Host
+	c.depositRetryBudget()
+	c = c.withIdempotencyKey(exec)
+
+	buffered, release, err := c.bufferBody()
+	if err != nil {
+		return c, nil, err
+	}
+	defer release()
+
+	shadow := buffered.startShadow(client)
+	start := time.Now()
+	resp, err := buffered.executeWithFallback(client, exec)
+	shadow(resp, err)
+	c.throttleResponse(resp)
+	if c.config.slo != nil {
+		c.config.slo.Record(resp, err, time.Since(start))
+	}
+	failed, elapsed := c.failed(resp, err), time.Since(start)
+	c.config.stats.recordRequest(resp, err, failed, elapsed)
+	processStats.recordRequest(resp, err, failed, elapsed)
+	return c, resp, err
+}
 
-	once := sync.Once{}
-	doneCh := make(chan struct{})
+// settle validates the outcome of the logical request & wraps the body of its response as configured
+func (c *Controller) settle(resp *http.Response, err error) (*http.Response, error) {
+	resp, err = c.validate(resp, err)
 
-	aCtx, cancel := context.WithCancel(c.ctx)
-	defer cancel()
+	c.verifyBody(resp)
+	c.limitBody(resp)
+	if err == nil && resp != nil && c.config.tee != nil {
+		resp.Body = &teeBody{Reader: io.TeeReader(resp.Body, c.config.tee), Closer: resp.Body}
+	}
+	return resp, err
+}
 
-	runFunc := func(timeout time.Duration) {
+// execute runs the attempts of the logical request as per the configured strategy
+func (c *Controller) execute(client *http.Client, exec *execution) (*http.Response, error) {
+	hedge := c.config.asyncCfg != nil && (c.config.hedgePredicate == nil || c.config.hedgePredicate(c.req))
+	if hedge {
+		// Parallel calls would share a single body reader, hence the body must be recreatable
+		if !isReplayable(c.req) {
+			return nil, ErrBodyNotReplayable
+		}
+		if c.config.streaming {
+			return nil, ErrStreamingParallelCall
+		}
+		return c.doAsync(client, exec)
+	} else {
+		// Retries would resend the already consumed body, hence it must be recreatable as well
+		if c.willRetry() && !isReplayable(c.req) {
+			return nil, ErrBodyNotReplayable
+		}
+		return c.doRetry(client, exec)
+	}
+}
 
-		asyncCtrl := c.Clone()
-		asyncCtrl.ctx = aCtx
+// asyncResult is the outcome of one of the parallel calls
+type asyncResult struct {
+	idx     int
+	resp    *http.Response
+	err     error
+	skipped bool
+}
 
-		if timeout > 0 {
-			select {
-			// Either wait till one of the routine is closed or until timeout
-			case <-doneCh:
+// doAsync handles asynchronous retry
+func (c *Controller) doAsync(client *http.Client, exec *execution) (*http.Response, error) {
+	delays := c.config.asyncCfg.delays(c.req.URL.Host)
+	maxFanOut := int32(c.config.asyncCfg.MaxFanOut)
+
+	resultCh := make(chan asyncResult, len(delays))
+	var inFlight int32
+
+	// Every call has its own context, so that the losers can be cancelled without affecting the winner
+	cancels := make([]context.CancelFunc, len(delays))
+	for i, delay := range delays {
+		aCtx, cancel := context.WithCancel(c.ctx)
+		cancels[i] = cancel
+
+		go func(idx int, delay time.Duration, aCtx context.Context) {
+			if delay > 0 {
+				// The wait is cut short once the call is cancelled, as another call won
+				if err := c.clock().Sleep(aCtx, delay); err != nil {
+					resultCh <- asyncResult{idx: idx, skipped: true}
+					return
+				}
+			}
+
+			// No parallel call is fired when the connection usage is beyond the guard, or the fan out is reached
+			n := atomic.AddInt32(&inFlight, 1)
+			if idx > 0 && (c.config.connGuard.exceeded() || (maxFanOut > 0 && n > maxFanOut)) {
+				atomic.AddInt32(&inFlight, -1)
+				resultCh <- asyncResult{idx: idx, skipped: true}
 				return
-			case <-time.After(timeout):
 			}
+
+			asyncCtrl := c.Clone()
+			asyncCtrl.ctx = aCtx
+			asyncCtrl.hedged = idx > 0
+			if endpoints := c.config.asyncCfg.Endpoints; len(endpoints) > 0 {
+				asyncCtrl.req = rebase(c.req, endpoints[idx%len(endpoints)])
+			}
+
+			res, err := asyncCtrl.doRetry(client, exec)
+			atomic.AddInt32(&inFlight, -1)
+			resultCh <- asyncResult{idx: idx, resp: res, err: err}
+		}(i, delay, aCtx)
+	}
+
+	// Without an acceptor the fastest call wins, else the latest rejected call wins only if no call is accepted
+	accept := c.config.asyncCfg.Accept
+	var winner *asyncResult
+	pending := len(delays)
+	for pending > 0 {
+		res := <-resultCh
+		pending--
+		if res.skipped {
+			continue
 		}
 
-		// Validate if the context is still active
-		if aCtx.Err() == nil {
-			res, err := asyncCtrl.doRetry(client)
-			once.Do(func() {
-				result = res
-				resErr = err
-				close(doneCh)
-			})
+		if winner != nil {
+			closeBody(winner.resp)
+			cancels[winner.idx]()
+		}
+		winner = &res
+		if accept == nil || accept(res.resp, res.err) {
+			break
 		}
 	}
 
-	go runFunc(0)                       // The first request
-	go runFunc(c.config.asyncCfg.Delay) // Delayed request
+	if winner == nil {
+		// Every call was skipped, as the context ended before any of them was fired
+		for _, cancel := range cancels {
+			cancel()
+		}
+		if err := c.ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, ErrNoParallelCall
+	}
 
-	<-doneCh
+	for i, cancel := range cancels {
+		if i != winner.idx {
+			cancel()
+		}
+	}
+
+	// The cancelled losers may still complete with a response, which is drained & closed in the background
+	go func(pending int) {
+		for ; pending > 0; pending-- {
+			if res := <-resultCh; !res.skipped {
+				closeBody(res.resp)
+			}
+		}
+	}(pending)
 
-	return result, resErr
+	if winner.err != nil {
+		cancels[winner.idx]()
+	} else {
+		winner.resp = withCancel(winner.resp, cancels[winner.idx])
+	}
+
+	return winner.resp, winner.err
 }
 
 // doRequest executes a single HTTP request
-func (c *ctrl) doRequest(client *http.Client) (*http.Response, error) {
-	req := c.req.Clone(c.ctx)
-	if c.config.timeout > 0 {
-		ctx, cancel := context.WithTimeout(c.ctx, c.config.timeout)
-		defer cancel()
-		req = req.WithContext(ctx)
+func (c *Controller) doRequest(client *http.Client, exec *execution, reason RetryReason) (*http.Response, error) {
+	attempt := exec.nextAttempt(reason, c.hedged)
+	ctx := withMemoryBudget(withClock(withAttempt(c.ctx, attempt), c.config.clock), c.config.memBudget)
+	ctx = c.withDialSettings(ctx, exec, attempt)
+
+	if c.config.breaker != nil {
+		if err := c.config.breaker.Allow(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.config.pacer != nil {
+		if err := c.config.pacer.Wait(ctx, c.config.paceLabel); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.config.limiter != nil {
+		if err := c.config.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// Attempts not completing with an outcome are deemed failed, yet only the attempts sent are accounted
+	failed, counted := true, false
+	base := c.req
+	if pool := c.config.pool; pool != nil {
+		if endpoint := pool.pick(ctx); endpoint != nil {
+			base = rebase(c.req, endpoint.url)
+			defer func() { pool.done(ctx, endpoint, counted, failed) }()
+		}
+	} else if n := len(exec.endpoints); n > 0 && c.req.URL.Host == exec.resolvedHost {
+		base = rebase(c.req, exec.endpoints[(attempt.Seq-1)%n].URL)
+	}
+
+	req := base.Clone(ctx)
+	if req.GetBody != nil {
+		// Each attempt gets its own copy of the body, as the original may already be consumed
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
+	c.trackUpload(req)
+	c.throttleRequest(req)
+
+	if c.config.correlationHeader != "" {
+		req.Header.Set(c.config.correlationHeader, attempt.CorrelationID)
+	}
+	if c.config.attemptHeader != "" {
+		req.Header.Set(c.config.attemptHeader, strconv.Itoa(attempt.Seq))
+	}
+
+	// The timeout context is released only when the response body is closed, so that the body stays readable
+	cancel := context.CancelFunc(func() {})
+	var timer *time.Timer
+	if c.config.attemptCtx != nil {
+		var aCtx context.Context
+		aCtx, cancel = c.config.attemptCtx(ctx, attempt.Seq)
+		req = req.WithContext(aCtx)
+	} else if c.config.timeout > 0 {
+		var tCtx context.Context
+		if c.config.streaming {
+			// Streams are bounded by the timeout only until the response headers are obtained
+			tCtx, cancel = context.WithCancel(ctx)
+			timer = time.AfterFunc(c.config.timeout, cancel)
+		} else {
+			tCtx, cancel = context.WithTimeout(ctx, c.config.timeout)
+		}
+		req = req.WithContext(tCtx)
+	}
+
+	if c.config.bulkhead != nil {
+		release, err := c.config.bulkhead.Acquire(ctx, req.URL.Host)
+		if err != nil {
+			cancel()
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, err
+		}
+
+		// The slot is held until the response body is closed, like the timeout context
+		inner := cancel
+		cancel = func() {
+			inner()
+			release()
+		}
+	}
+
+	if c.config.mutator != nil {
+		if err := c.config.mutator(attempt.Seq, req); err != nil {
+			cancel()
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, err
+		}
+	}
+
+	var onAuthResponse func(*http.Response)
+	if c.config.auth != nil {
+		var err error
+		if onAuthResponse, err = c.config.auth.authenticate(req); err != nil {
+			cancel()
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, err
+		}
+	}
+
+	c.attemptStarted(attempt, req)
+	req, tracer := c.traceAttempt(req)
+	start := time.Now()
+	if err := c.doPreflight(client, req); err != nil {
+		// The upload is skipped, yet the attempt is accounted like any other failure
+		counted = countable(ctx, err)
+		cancel()
+		req.Body.Close()
+		if counted {
+			c.recordOutcome(ctx, true)
+		}
+		c.dumpAttempt(attempt, req, nil, err)
+		recordHost(attempt, req, nil, err, true, time.Since(start))
+		c.attemptDone(exec, newAttemptRecord(attempt, req, start, nil, err, tracer))
+		return nil, err
+	}
+
+	atomic.AddInt64(&poolCounters.inFlight, 1)
+	resp, err := c.handler(client)(req)
+	atomic.AddInt64(&poolCounters.inFlight, -1)
+
+	if c.config.bulkhead != nil {
+		c.config.bulkhead.Record(req.URL.Host, resp, err)
+	}
+
+	if asyncCfg := c.config.asyncCfg; asyncCfg != nil && asyncCfg.Tracker != nil && (err == nil || censored(req.Context(), err)) {
+		asyncCfg.Tracker.Record(req.URL.Host, time.Since(start))
 	}
 
-	return client.Do(req)
+	if onAuthResponse != nil {
+		onAuthResponse(resp)
+	}
+
+	if timer != nil && !timer.Stop() && err != nil {
+		err = fmt.Errorf("%w: %v", context.DeadlineExceeded, err)
+	}
+
+	if err != nil {
+		cancel()
+	} else {
+		resp = withCancel(resp, cancel)
+	}
+
+	failed, counted = c.failed(resp, err), countable(ctx, err)
+	if failed {
+		c.dumpAttempt(attempt, req, resp, err)
+	}
+	if counted {
+		c.recordOutcome(ctx, failed)
+	}
+	recordHost(attempt, req, resp, err, failed, time.Since(start))
+	c.attemptDone(exec, newAttemptRecord(attempt, req, start, resp, err, tracer))
+	return resp, err
+}
+
+// countable reports whether the outcome of an attempt sent tells of the health of the upstream, ie: unless it failed
+// as its context was cancelled, by the caller or by the winner of the parallel calls
+func countable(ctx context.Context, err error) bool {
+	return err == nil || ctx.Err() == nil
+}
+
+// isTerminal reports whether the error shall stop further attempts, irrespective of the retry checker
+func isTerminal(err error) bool {
+	return errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrBulkheadFull)
+}
+
+// sleep waits for the duration, aborting with the context error as soon as the context is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// willRetry reports whether failed attempts of the request may be retried
+func (c *Controller) willRetry() bool {
+	return c.config.retryCfg.RetryType != noRetry && c.config.retryCfg.MaxCount > 0 && c.retryAllowed()
+}
+
+// isReplayable reports whether the request body can be sent more than once
+func isReplayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
 }
 
 // doRetry handles the retry logic
-func (c *ctrl) doRetry(client *http.Client) (*http.Response, error) {
+func (c *Controller) doRetry(client *http.Client, exec *execution) (*http.Response, error) {
 	retryCfg := c.config.retryCfg
 
 	var resultErr error
-	var resultResp *http.Response
+	var resultResp, lastResp *http.Response
+	var waitDuration time.Duration
+	var retry bool
 
 	// Check if the first request succeeds
-	if resultResp, resultErr = c.doRequest(client); retryCfg.RetryType == noRetry ||
-		!retryCfg.RetryCheckFunc(resultResp, resultErr) {
+	if resultResp, resultErr = c.doRequest(client, exec, ReasonNone); retryCfg.RetryType == noRetry ||
+		!c.retryAllowed() || isTerminal(resultErr) {
+		return resultResp, resultErr
+	}
+	if c, retry = c.retryOutcome(resultResp, resultErr); !retry {
 		return resultResp, resultErr
 	}
 
+	// Responses of retried attempts are discarded, except the latest one kept for the soft fail fallback
+	if c.config.softFail {
+		lastResp = resultResp
+	}
+	finish := func(resp *http.Response, err error) (*http.Response, error) {
+		if lastResp != resp {
+			closeBody(lastResp)
+		}
+		return resp, err
+	}
+	attempts := 1
+	giveUp := func(resp *http.Response, err error) (*http.Response, error) {
+		c.gaveUp(attempts, resp, err)
+		return finish(resp, err)
+	}
+
 	// Initiate retry logic with delay
 	for i := 0; i < retryCfg.MaxCount; i++ {
 
-		// Calculate waiting duration for next execution
-		var waitDuration time.Duration
-		if retryCfg.RetryType == simpleRetry {
-			waitDuration = retryCfg.RetryInterval
-		} else if retryCfg.RetryType == exponentialRetry {
-			waitDuration = retryCfg.RetryInterval * time.Duration(math.Exp2(float64(i)))
+		// Stop multiplying traffic when the connection usage is beyond the guard
+		if c.config.connGuard.exceeded() {
+			break
 		}
 
-		if waitDuration > 0 {
-			time.Sleep(waitDuration)
+		// Calculate waiting duration for next execution
+		waitDuration = c.backoff(i, waitDuration)
+		if wait, ok := c.retryAfter(resultResp); ok {
+			waitDuration = wait
 		}
 
-		if resultResp, resultErr = c.doRequest(client); !retryCfg.RetryCheckFunc(resultResp, resultErr) {
+		// No attempt is issued which would begin beyond the elapsed time budget
+		if max := c.config.maxElapsed; max > 0 && c.clock().Now().Sub(exec.start)+waitDuration > max {
 			break
 		}
+
+		if err := c.withdrawRetryBudget(); err != nil {
+			if resultResp != lastResp {
+				closeBody(resultResp)
+			}
+			return giveUp(nil, err)
+		}
+		reason := ClassifyRetry(resultResp, resultErr)
+		c.retryScheduled(i+1, reason, waitDuration, resultResp, resultErr)
+
+		// The connection of the discarded response is released to the pool before waiting
+		if resultResp != lastResp {
+			closeBody(resultResp)
+		}
+
+		if err := c.clock().Sleep(c.ctx, waitDuration); err != nil {
+			return giveUp(nil, err)
+		}
+
+		attempts++
+		resultResp, resultErr = c.doRequest(client, exec, reason)
+		if c.config.softFail && resultResp != nil {
+			closeBody(lastResp)
+			lastResp = resultResp
+		}
+
+		if isTerminal(resultErr) {
+			return giveUp(resultResp, resultErr)
+		}
+		if c, retry = c.retryOutcome(resultResp, resultErr); !retry {
+			return finish(resultResp, resultErr)
+		}
 	}
 
-	return resultResp, resultErr
+	// Fallback to the best effort response, when retries are exhausted with an error
+	if c.config.softFail && resultErr != nil && lastResp != nil {
+		return giveUp(lastResp, nil)
+	}
+
+	resp, err := c.exhausted(attempts, resultResp, resultErr)
+	if resp == nil && resultResp != lastResp {
+		closeBody(resultResp)
+	}
+	return giveUp(resp, err)
 }
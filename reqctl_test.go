@@ -1,18 +1,30 @@
 package reqctl_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/RohanPoojary/reqctl"
+	"github.com/RohanPoojary/reqctl/reqctltest"
 )
 
 func TestSuccessCall(t *testing.T) {
-	failureURL := "https://httpbin.org/status/200"
-	request, err := http.NewRequest("GET", failureURL, nil)
+	server := reqctltest.NewServer()
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL, nil)
 	if err != nil {
 		t.Errorf("Error creating request: %v", err)
 		return
@@ -31,14 +43,16 @@ func TestSuccessCall(t *testing.T) {
 }
 
 func TestSimpleRetry(t *testing.T) {
-	failureURL := "https://httpbin.org/delay/1"
-	request, err := http.NewRequest("GET", failureURL, nil)
+	server := reqctltest.NewServer(reqctltest.Step{Delay: time.Second})
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL, nil)
 	if err != nil {
 		t.Errorf("Error creating request: %v", err)
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
 	defer cancel()
 
 	ctlr := reqctl.Request(ctx, request).
@@ -51,8 +65,10 @@ func TestSimpleRetry(t *testing.T) {
 }
 
 func TestRetryWithCustomFunc(t *testing.T) {
-	failureURL := "https://httpbin.org/status/500"
-	request, err := http.NewRequest("GET", failureURL, nil)
+	server := reqctltest.NewServer(reqctltest.Status(500)...)
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL, nil)
 	if err != nil {
 		t.Errorf("Error creating request: %v", err)
 		return
@@ -75,8 +91,10 @@ func TestRetryWithCustomFunc(t *testing.T) {
 }
 
 func TestExponentialRetry(t *testing.T) {
-	failureURL := "https://httpbin.org/status/500"
-	request, err := http.NewRequest("GET", failureURL, nil)
+	server := reqctltest.NewServer(reqctltest.Status(500)...)
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL, nil)
 	if err != nil {
 		t.Errorf("Error creating request: %v", err)
 		return
@@ -88,7 +106,7 @@ func TestExponentialRetry(t *testing.T) {
 
 	start := time.Now()
 	resp, err := reqctl.Request(context.Background(), request).
-		SetExponentialRetryWithChecker(500*time.Millisecond, 3, customChecker).
+		SetExponentialRetryWithChecker(50*time.Millisecond, 3, customChecker).
 		Do()
 
 	if err != nil {
@@ -101,15 +119,18 @@ func TestExponentialRetry(t *testing.T) {
 		return
 	}
 
-	if time.Since(start) < 3500*time.Millisecond {
-		t.Errorf("Expected atleast 3.5s of total delay, got %v", time.Since(start))
+	if time.Since(start) < 350*time.Millisecond {
+		t.Errorf("Expected atleast 350ms of total delay, got %v", time.Since(start))
 	}
+	server.AssertAttempts(t, 4)
 
 }
 
 func TestTimeout(t *testing.T) {
-	delayURL := "https://httpbin.org/delay/1"
-	request, err := http.NewRequest("GET", delayURL, nil)
+	server := reqctltest.NewServer(reqctltest.Step{Delay: time.Second})
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL, nil)
 	if err != nil {
 		t.Errorf("Error creating request: %v", err)
 		return
@@ -126,8 +147,10 @@ func TestTimeout(t *testing.T) {
 }
 
 func TestParallelCallWithDelay(t *testing.T) {
-	delayURL := "https://httpbin.org/status/200"
-	request, err := http.NewRequest("GET", delayURL, nil)
+	server := reqctltest.NewServer()
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL, nil)
 	if err != nil {
 		t.Errorf("Error creating request: %v", err)
 		return
@@ -141,3 +164,536 @@ func TestParallelCallWithDelay(t *testing.T) {
 		t.Errorf("Obtained error: %v", err)
 	}
 }
+
+func TestParallelCallReplaysBody(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	request, err := http.NewRequest("POST", server.URL, strings.NewReader("payload"))
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	_, err = reqctl.Request(request.Context(), request).
+		SetParallelCallWithDelay(10 * time.Millisecond).
+		Do()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+
+	// Wait for the slower parallel call to reach the server
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 2 {
+		t.Errorf("Expected 2 parallel calls, got %d", len(bodies))
+	}
+
+	for _, body := range bodies {
+		if body != "payload" {
+			t.Errorf("Expected body %q on every call, got %q", "payload", body)
+		}
+	}
+}
+
+func TestParallelCallNonReplayableBody(t *testing.T) {
+	body := io.MultiReader(strings.NewReader("payload"))
+	request, err := http.NewRequest("POST", "http://localhost", body)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	_, err = reqctl.Request(request.Context(), request).
+		SetParallelCallWithDelay(10 * time.Millisecond).
+		Do()
+	if !errors.Is(err, reqctl.ErrBodyNotReplayable) {
+		t.Errorf("Expected reqctl.ErrBodyNotReplayable, got %v", err)
+	}
+}
+
+func TestSetClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	used := false
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			used = true
+			return http.DefaultTransport.RoundTrip(r)
+		}),
+	}
+
+	_, err = reqctl.Request(request.Context(), request).
+		SetClient(client).
+		Do()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+
+	if !used {
+		t.Errorf("Expected the configured client to be used")
+	}
+}
+
+func TestSoftFail(t *testing.T) {
+	calls := 0
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			// Only the first attempt obtains a response
+			calls++
+			if calls == 1 {
+				return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+			}
+			return nil, errors.New("connection reset")
+		}),
+	}
+
+	request, err := http.NewRequest("GET", "http://localhost", nil)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	checker := func(resp *http.Response, err error) bool {
+		return err != nil || resp.StatusCode >= 500
+	}
+
+	resp, err := reqctl.Request(request.Context(), request).
+		SetSimpleRetryWithChecker(time.Millisecond, 2, checker).
+		SetClient(client).
+		SetSoftFail(true).
+		Do()
+	if err != nil {
+		t.Errorf("Expected nil error with soft fail, got %v", err)
+		return
+	}
+
+	if resp.StatusCode != 503 {
+		t.Errorf("Expected status code 503, got %d", resp.StatusCode)
+	}
+
+	if calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestTimeoutBodyReadable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("payload"))
+	}))
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	resp, err := reqctl.Request(request.Context(), request).
+		SetTimeout(time.Second).
+		Do()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || string(body) != "payload" {
+		t.Errorf("Expected body %q after Do returned, got %q, error: %v", "payload", body, err)
+	}
+}
+
+func TestParallelCallFirstAcceptable(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		first := calls == 1
+		mu.Unlock()
+
+		// The first call fails slowly, the parallel call succeeds
+		if first {
+			time.Sleep(20 * time.Millisecond)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	accept := func(resp *http.Response, err error) bool {
+		return err == nil && resp.StatusCode < 500
+	}
+
+	resp, err := reqctl.Request(request.Context(), request).
+		SetParallelCallFirstAcceptable(5*time.Millisecond, accept).
+		Do()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status code 200, got %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || string(body) != "ok" {
+		t.Errorf("Expected winner body %q, got %q, error: %v", "ok", body, err)
+	}
+}
+
+func TestControllerReuse(t *testing.T) {
+	var paths []string
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			paths = append(paths, r.URL.Path)
+			return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	request, err := http.NewRequest("GET", "http://localhost/path", nil)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	// A configured controller can be stored & passed around
+	var template reqctl.Controller = reqctl.Request(context.Background(), request).
+		SetSimpleRetry(time.Millisecond, 2).
+		SetClient(client)
+
+	do := func(c reqctl.Controller) error {
+		_, err := c.Do()
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := do(template.Clone()); err != nil {
+			t.Errorf("Obtained error: %v", err)
+		}
+	}
+
+	if len(paths) != 2 {
+		t.Errorf("Expected 2 calls, got %d", len(paths))
+	}
+}
+
+func TestResponseTee(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("payload"))
+	}))
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	var tee bytes.Buffer
+	resp, err := reqctl.Request(request.Context(), request).
+		SetResponseTee(&tee).
+		Do()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "payload" || tee.String() != "payload" {
+		t.Errorf("Expected body & tee %q, got %q & %q", "payload", body, tee.String())
+	}
+}
+
+func TestAttemptContext(t *testing.T) {
+	type key struct{}
+
+	var values []interface{}
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			values = append(values, r.Context().Value(key{}))
+			return nil, errors.New("connection reset")
+		}),
+	}
+
+	request, err := http.NewRequest("GET", "http://localhost", nil)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	cancelled := 0
+	attemptCtx := func(parent context.Context, attempt int) (context.Context, context.CancelFunc) {
+		ctx, cancel := context.WithCancel(context.WithValue(parent, key{}, attempt))
+		return ctx, func() {
+			cancelled++
+			cancel()
+		}
+	}
+
+	_, err = reqctl.Request(context.Background(), request).
+		SetSimpleRetry(time.Millisecond, 1).
+		SetAttemptContext(attemptCtx).
+		SetClient(client).
+		Do()
+	if err == nil {
+		t.Errorf("Request should have failed")
+	}
+
+	if len(values) != 2 || values[0] != 1 || values[1] != 2 || cancelled != 2 {
+		t.Errorf("Expected attempt values [1 2] with 2 cancellations, got %v with %d", values, cancelled)
+	}
+}
+
+func TestRetryWaitCancelled(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		}),
+	}
+
+	request, err := http.NewRequest("GET", "http://localhost", nil)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = reqctl.Request(ctx, request).
+		SetExponentialRetry(time.Second, 3).
+		SetClient(client).
+		Do()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Expected the backoff wait to be aborted, took %v", time.Since(start))
+	}
+}
+
+func TestRetryReplaysBody(t *testing.T) {
+	var bodies []string
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	request, err := http.NewRequest("PUT", "http://localhost", strings.NewReader("payload"))
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	_, err = reqctl.Request(request.Context(), request).
+		SetSimpleRetryWithChecker(time.Millisecond, 2, reqctl.RetryOnStatus(503)).
+		SetClient(client).
+		Do()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+
+	if len(bodies) != 3 {
+		t.Errorf("Expected 3 attempts, got %d", len(bodies))
+	}
+
+	for _, body := range bodies {
+		if body != "payload" {
+			t.Errorf("Expected body %q on every attempt, got %q", "payload", body)
+		}
+	}
+}
+
+func TestRetryNonReplayableBody(t *testing.T) {
+	body := io.MultiReader(strings.NewReader("payload"))
+	request, err := http.NewRequest("PUT", "http://localhost", body)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	_, err = reqctl.Request(request.Context(), request).
+		SetSimpleRetry(time.Millisecond, 2).
+		Do()
+	if !errors.Is(err, reqctl.ErrBodyNotReplayable) {
+		t.Errorf("Expected reqctl.ErrBodyNotReplayable, got %v", err)
+	}
+}
+
+func TestRetryReusesConnection(t *testing.T) {
+	var mu sync.Mutex
+	conns := 0
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("upstream unavailable"))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			conns++
+			mu.Unlock()
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	resp, err := reqctl.Request(request.Context(), request).
+		SetSimpleRetryWithChecker(time.Millisecond, 3, reqctl.RetryOnStatus(503)).
+		SetClient(server.Client()).
+		Do()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+
+	// The final response stays readable
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "upstream unavailable" {
+		t.Errorf("Expected the body of the last attempt, got %q", body)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if conns != 1 {
+		t.Errorf("Expected the discarded responses to release their connection, got %d connections", conns)
+	}
+}
+
+// trackedBody records whether the response body was closed
+type trackedBody struct {
+	io.Reader
+	closed int32
+}
+
+func (b *trackedBody) Close() error {
+	atomic.StoreInt32(&b.closed, 1)
+	return nil
+}
+
+func TestParallelCallClosesLoser(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []*trackedBody
+	var calls int32
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			// The first call completes after the parallel call, ignoring its cancellation
+			body := &trackedBody{Reader: strings.NewReader("response")}
+			mu.Lock()
+			bodies = append(bodies, body)
+			mu.Unlock()
+			if atomic.AddInt32(&calls, 1) == 1 {
+				time.Sleep(30 * time.Millisecond)
+			}
+			return &http.Response{StatusCode: 200, Body: body, Request: r}, nil
+		}),
+	}
+
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	resp, err := reqctl.Request(request.Context(), request).
+		SetParallelCallWithDelay(time.Millisecond).
+		SetClient(client).
+		Do()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	// Wait for the losing call to complete
+	time.Sleep(60 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 2 {
+		t.Fatalf("Expected 2 calls, got %d", len(bodies))
+	}
+	if atomic.LoadInt32(&bodies[0].closed) != 1 {
+		t.Errorf("Expected the losing response to be closed")
+	}
+	if atomic.LoadInt32(&bodies[1].closed) != 0 {
+		t.Errorf("Expected the winning response to stay open")
+	}
+}
+
+func TestPerAttemptMutator(t *testing.T) {
+	var keys []string
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			keys = append(keys, r.Header.Get("X-Api-Key"))
+			return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	errRotation := errors.New("no more keys")
+	mutator := func(attempt int, req *http.Request) error {
+		if attempt > 2 {
+			return errRotation
+		}
+		req.Header.Set("X-Api-Key", fmt.Sprintf("key-%d", attempt))
+		return nil
+	}
+
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	_, err := reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(0, 2, reqctl.RetryOnStatus(503)).
+		SetPerAttemptMutator(mutator).
+		SetClient(client).
+		Do()
+
+	if !reflect.DeepEqual(keys, []string{"key-1", "key-2"}) {
+		t.Errorf("Expected every attempt to be mutated, got %v", keys)
+	}
+	if !errors.Is(err, errRotation) {
+		t.Errorf("Expected the mutator error, got %v", err)
+	}
+	if request.Header.Get("X-Api-Key") != "" {
+		t.Errorf("Expected the original request to be left untouched")
+	}
+}
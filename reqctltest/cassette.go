@@ -0,0 +1,279 @@
+package reqctltest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+// Mode defines whether a cassette records or replays the interactions
+type Mode string
+
+const (
+	// ModeAuto replays the cassette if its file exists, else records it
+	ModeAuto = Mode("auto")
+	// ModeRecord sends the requests & records the interactions, overwriting the file on Stop
+	ModeRecord = Mode("record")
+	// ModeReplay replays the recorded interactions, failing the requests without one
+	ModeReplay = Mode("replay")
+)
+
+// ErrorKind classifies a recorded error, so that its replay satisfies the same checks, eg: errors.Is or net.Error
+type ErrorKind string
+
+const (
+	// ErrorCanceled is an error matching context.Canceled
+	ErrorCanceled = ErrorKind("canceled")
+	// ErrorDeadline is an error matching context.DeadlineExceeded
+	ErrorDeadline = ErrorKind("deadline")
+	// ErrorTimeout is a net.Error timing out
+	ErrorTimeout = ErrorKind("timeout")
+	// ErrorNet is any other net.Error
+	ErrorNet = ErrorKind("net")
+)
+
+// Interaction is a request & its outcome, as stored in a cassette. Bodies are stored as base64 in JSON.
+type Interaction struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   []byte `json:"body,omitempty"`
+	// Attempt is the sequence number of the attempt within its logical request, 0 outside of reqctl
+	Attempt int `json:"attempt,omitempty"`
+
+	Status int         `json:"status,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	// ResponseBody is the body of the response, Err the message of the error replayed if no response was obtained
+	ResponseBody []byte `json:"response_body,omitempty"`
+	Err          string `json:"error,omitempty"`
+	// ErrKind is the kind of the error, ErrOp the operation of its *url.Error if it was one
+	ErrKind ErrorKind `json:"error_kind,omitempty"`
+	ErrOp   string    `json:"error_op,omitempty"`
+}
+
+// Cassette is a transport recording the interactions to a file on the first run and replaying them on the
+// subsequent runs. Interactions are matched by method, URL, body & attempt sequence number, so that the retries
+// of a logical request replay their own outcome. The sequence numbers of parallel calls depend on the calls
+// skipped, eg: as per the fan out, hence a request without an interaction of its sequence number replays the
+// first unused interaction matching otherwise. Request headers are not recorded.
+type Cassette struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewCassette opens the cassette stored at path. Recorded requests are sent via the transport,
+// http.DefaultTransport if nil.
+func NewCassette(path string, mode Mode, transport http.RoundTripper) (*Cassette, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	c := &Cassette{path: path, mode: mode, transport: transport}
+
+	if mode == ModeAuto {
+		c.mode = ModeRecord
+		if _, err := os.Stat(path); err == nil {
+			c.mode = ModeReplay
+		}
+	}
+	if c.mode != ModeReplay {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &c.interactions); err != nil {
+		return nil, fmt.Errorf("reqctltest: decoding cassette %s: %w", path, err)
+	}
+	c.used = make([]bool, len(c.interactions))
+	return c, nil
+}
+
+// Mode returns whether the cassette records or replays
+func (c *Cassette) Mode() Mode {
+	return c.mode
+}
+
+// Client returns a client sending via the cassette
+func (c *Cassette) Client() *http.Client {
+	return &http.Client{Transport: c}
+}
+
+// RoundTrip records or replays the interaction of the request
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+
+	attempt, _ := reqctl.AttemptFromContext(req.Context())
+	key := Interaction{Method: req.Method, URL: req.URL.String(), Body: body, Attempt: attempt.Seq}
+
+	if c.mode == ModeReplay {
+		return c.replay(req, key)
+	}
+
+	sent := req.Clone(req.Context())
+	sent.Body = io.NopCloser(bytes.NewReader(body))
+	resp, err := c.transport.RoundTrip(sent)
+	if err != nil {
+		key.Err, key.ErrKind, key.ErrOp = describeError(err)
+		c.record(key)
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	key.Status, key.Header, key.ResponseBody = resp.StatusCode, resp.Header, respBody
+	c.record(key)
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	return resp, nil
+}
+
+// record appends the interaction
+func (c *Cassette) record(interaction Interaction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions = append(c.interactions, interaction)
+}
+
+// replay returns the outcome of the first unused interaction matching the request, preferably of its attempt
+func (c *Cassette) replay(req *http.Request, key Interaction) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	match := -1
+	for i, it := range c.interactions {
+		if c.used[i] || it.Method != key.Method || it.URL != key.URL || !bytes.Equal(it.Body, key.Body) {
+			continue
+		}
+		if it.Attempt == key.Attempt {
+			match = i
+			break
+		}
+		if match < 0 {
+			match = i
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("reqctltest: no interaction recorded for %s %s attempt %d", key.Method, key.URL, key.Attempt)
+	}
+
+	it := c.interactions[match]
+	c.used[match] = true
+	if it.Err != "" {
+		return nil, it.replayError()
+	}
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", it.Status, http.StatusText(it.Status)),
+		StatusCode: it.Status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     it.Header.Clone(),
+		Body:       io.NopCloser(bytes.NewReader(it.ResponseBody)),
+		Request:    req,
+	}, nil
+}
+
+// describeError returns the message, kind & *url.Error operation of the error to record
+func describeError(err error) (string, ErrorKind, string) {
+	var op string
+	if urlErr, ok := err.(*url.Error); ok {
+		op, err = urlErr.Op, urlErr.Err
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return err.Error(), ErrorCanceled, op
+	case errors.Is(err, context.DeadlineExceeded):
+		return err.Error(), ErrorDeadline, op
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return err.Error(), ErrorTimeout, op
+	case errors.As(err, &netErr):
+		return err.Error(), ErrorNet, op
+	}
+	return err.Error(), "", op
+}
+
+// replayError rebuilds the recorded error, of the same kind
+func (it Interaction) replayError() error {
+	var err error = replayedError{message: it.Err, kind: it.ErrKind}
+	if it.ErrKind == ErrorTimeout || it.ErrKind == ErrorNet || it.ErrKind == ErrorDeadline {
+		err = replayedNetError{replayedError{message: it.Err, kind: it.ErrKind}}
+	}
+	if it.ErrOp != "" {
+		err = &url.Error{Op: it.ErrOp, URL: it.URL, Err: err}
+	}
+	return err
+}
+
+// replayedError is a recorded error, matching the context errors of its kind
+type replayedError struct {
+	message string
+	kind    ErrorKind
+}
+
+func (e replayedError) Error() string {
+	return e.message
+}
+
+// Is reports whether the error matches the target context error
+func (e replayedError) Is(target error) bool {
+	return (e.kind == ErrorCanceled && target == context.Canceled) ||
+		(e.kind == ErrorDeadline && target == context.DeadlineExceeded)
+}
+
+// replayedNetError is a recorded net.Error
+type replayedNetError struct {
+	replayedError
+}
+
+// Timeout reports whether the recorded error timed out
+func (e replayedNetError) Timeout() bool {
+	return e.kind == ErrorTimeout || e.kind == ErrorDeadline
+}
+
+// Temporary reports whether the recorded error is temporary, ie: timed out
+func (e replayedNetError) Temporary() bool {
+	return e.Timeout()
+}
+
+// Stop saves the recorded interactions to the cassette file, it is a no-op when replaying
+func (c *Cassette) Stop() error {
+	if c.mode == ModeReplay {
+		return nil
+	}
+
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0o644)
+}
@@ -0,0 +1,120 @@
+package reqctltest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+	"github.com/RohanPoojary/reqctl/reqctltest"
+)
+
+func TestCassette(t *testing.T) {
+	server := reqctltest.NewServer(
+		reqctltest.Step{Status: http.StatusServiceUnavailable},
+		reqctltest.Step{Status: http.StatusOK, Body: "recorded"},
+	)
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	do := func(cassette *reqctltest.Cassette) (int, string) {
+		request, _ := http.NewRequest("GET", server.URL+"/items", nil)
+		resp, err := reqctl.Request(context.Background(), request).
+			SetSimpleRetryWithChecker(0, 2, reqctl.RetryOnStatus(503)).
+			SetClient(cassette.Client()).
+			Do()
+		if err != nil {
+			t.Fatalf("Obtained error: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	cassette, err := reqctltest.NewCassette(path, reqctltest.ModeAuto, nil)
+	if err != nil || cassette.Mode() != reqctltest.ModeRecord {
+		t.Fatalf("Expected a new cassette to record, got %v", err)
+	}
+	if status, body := do(cassette); status != http.StatusOK || body != "recorded" {
+		t.Errorf("Expected the recorded response, got %d %q", status, body)
+	}
+	if err := cassette.Stop(); err != nil {
+		t.Fatalf("Obtained error saving the cassette: %v", err)
+	}
+	server.AssertAttempts(t, 2)
+
+	// The upstream is no longer needed once recorded
+	server.Close()
+	cassette, err = reqctltest.NewCassette(path, reqctltest.ModeAuto, nil)
+	if err != nil || cassette.Mode() != reqctltest.ModeReplay {
+		t.Fatalf("Expected an existing cassette to replay, got %v", err)
+	}
+	if status, body := do(cassette); status != http.StatusOK || body != "recorded" {
+		t.Errorf("Expected the replayed response, got %d %q", status, body)
+	}
+}
+
+// roundTripFunc adapts a function to a transport
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestCassetteReplay(t *testing.T) {
+	binary := []byte{0x00, 0xff, 0xfe, 0x80}
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		switch r.URL.Path {
+		case "/timeout":
+			return nil, &url.Error{Op: "Get", URL: r.URL.String(), Err: &net.DNSError{Err: "i/o timeout", IsTimeout: true}}
+		case "/deadline":
+			return nil, context.DeadlineExceeded
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(binary)), Request: r}, nil
+	})
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	do := func(cassette *reqctltest.Cassette, path string) (*http.Response, error) {
+		request, _ := http.NewRequest("POST", "http://upstream"+path, bytes.NewReader(binary))
+		return cassette.RoundTrip(request)
+	}
+
+	cassette, _ := reqctltest.NewCassette(path, reqctltest.ModeRecord, transport)
+	for _, p := range []string{"/binary", "/timeout", "/deadline"} {
+		if resp, err := do(cassette, p); err == nil {
+			resp.Body.Close()
+		}
+	}
+	if err := cassette.Stop(); err != nil {
+		t.Fatalf("Obtained error saving the cassette: %v", err)
+	}
+
+	cassette, err := reqctltest.NewCassette(path, reqctltest.ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("Obtained error opening the cassette: %v", err)
+	}
+	resp, err := do(cassette, "/binary")
+	if err != nil {
+		t.Fatalf("Obtained error: %v", err)
+	}
+	if body, _ := io.ReadAll(resp.Body); !bytes.Equal(body, binary) {
+		t.Errorf("Expected the binary body to be replayed as is, got %v", body)
+	}
+
+	var urlErr *url.Error
+	_, err = do(cassette, "/timeout")
+	if !errors.As(err, &urlErr) || urlErr.Op != "Get" || !urlErr.Timeout() {
+		t.Errorf("Expected a *url.Error timing out, got %#v", err)
+	}
+
+	var netErr net.Error
+	_, err = do(cassette, "/deadline")
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("Expected an error matching context.DeadlineExceeded, got %#v", err)
+	}
+}
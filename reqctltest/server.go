@@ -0,0 +1,154 @@
+// Package reqctltest provides a scriptable test server to exercise request controllers
+// against flaky upstreams, without depending on the network.
+package reqctltest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Step scripts the response to a single attempt
+type Step struct {
+	// Status of the response, 200 if not set
+	Status int
+	// Delay before responding, cut short if the attempt is cancelled
+	Delay time.Duration
+	// Header & Body of the response
+	Header http.Header
+	Body   string
+	// Drop closes the connection without responding, failing the attempt with a network error
+	Drop bool
+}
+
+// Status returns the steps responding with the status codes in order
+func Status(codes ...int) []Step {
+	steps := make([]Step, 0, len(codes))
+	for _, code := range codes {
+		steps = append(steps, Step{Status: code})
+	}
+	return steps
+}
+
+// FailThenSucceed returns the steps responding n times with the status, then with 200
+func FailThenSucceed(n, status int) []Step {
+	steps := make([]Step, 0, n+1)
+	for i := 0; i < n; i++ {
+		steps = append(steps, Step{Status: status})
+	}
+	return append(steps, Step{Status: http.StatusOK})
+}
+
+// Request is an attempt received by the server
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   string
+	At     time.Time
+}
+
+// Server is an httptest server responding to the attempts as per the script, the last step being repeated once
+// the script is exhausted. An empty script responds with 200.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	steps    []Step
+	requests []Request
+}
+
+// NewServer starts a server following the script, it must be closed after use
+func NewServer(steps ...Step) *Server {
+	s := &Server{steps: steps}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// serve responds to the attempt as per its step
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	seq := len(s.requests)
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Header: r.Header.Clone(),
+		Body:   string(body),
+		At:     time.Now(),
+	})
+	var step Step
+	if n := len(s.steps); n > 0 {
+		if seq >= n {
+			seq = n - 1
+		}
+		step = s.steps[seq]
+	}
+	s.mu.Unlock()
+
+	if step.Delay > 0 {
+		timer := time.NewTimer(step.Delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if step.Drop {
+		if hijacker, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hijacker.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+		panic(http.ErrAbortHandler)
+	}
+
+	for name, values := range step.Header {
+		w.Header()[name] = values
+	}
+	status := step.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, step.Body)
+}
+
+// Script replaces the script of the server & resets the received attempts
+func (s *Server) Script(steps ...Step) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.steps = steps
+	s.requests = nil
+}
+
+// Attempts returns the number of attempts received
+func (s *Server) Attempts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+// Requests returns the attempts received, in the order of their arrival
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := make([]Request, len(s.requests))
+	copy(res, s.requests)
+	return res
+}
+
+// AssertAttempts fails the test unless the server received exactly n attempts
+func (s *Server) AssertAttempts(t testing.TB, n int) {
+	t.Helper()
+	if got := s.Attempts(); got != n {
+		t.Errorf("reqctltest: expected %d attempts, got %d", n, got)
+	}
+}
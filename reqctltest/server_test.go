@@ -0,0 +1,60 @@
+package reqctltest_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+	"github.com/RohanPoojary/reqctl/reqctltest"
+)
+
+func TestServer(t *testing.T) {
+	server := reqctltest.NewServer(reqctltest.FailThenSucceed(2, http.StatusServiceUnavailable)...)
+	defer server.Close()
+
+	request, _ := http.NewRequest("PUT", server.URL+"/items", strings.NewReader("payload"))
+	resp, err := reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(time.Millisecond, 5, reqctl.RetryOnStatus(503)).
+		Do()
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected success after the failures, got %v, %v", resp, err)
+	}
+	resp.Body.Close()
+
+	server.AssertAttempts(t, 3)
+	for _, req := range server.Requests() {
+		if req.Method != "PUT" || req.Path != "/items" || req.Body != "payload" {
+			t.Errorf("Expected every attempt to be recorded, got %+v", req)
+		}
+	}
+}
+
+func TestServerScript(t *testing.T) {
+	server := reqctltest.NewServer()
+	defer server.Close()
+
+	do := func() (*http.Response, error) {
+		request, _ := http.NewRequest("GET", server.URL, nil)
+		return reqctl.Request(context.Background(), request).
+			SetSimpleRetryWithChecker(0, 2, reqctl.RetryOnTimeout()).
+			SetTimeout(20 * time.Millisecond).
+			Do()
+	}
+
+	server.Script(reqctltest.Step{Delay: 200 * time.Millisecond}, reqctltest.Step{Status: http.StatusTeapot, Body: "tea"})
+	resp, err := do()
+	if err != nil || resp.StatusCode != http.StatusTeapot {
+		t.Errorf("Expected the delayed attempt to be retried, got %v, %v", resp, err)
+	} else {
+		resp.Body.Close()
+	}
+	server.AssertAttempts(t, 2)
+
+	server.Script(reqctltest.Step{Drop: true})
+	if _, err := do(); err == nil {
+		t.Errorf("Expected the dropped connection to fail the attempt")
+	}
+}
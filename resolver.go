@@ -0,0 +1,69 @@
+package reqctl
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+)
+
+// Resolver discovers the endpoints serving a host, eg: from Consul, Kubernetes or static configuration
+type Resolver interface {
+	Endpoints(ctx context.Context, host string) ([]Endpoint, error)
+}
+
+// ResolverFunc adapts a function into a Resolver
+type ResolverFunc func(ctx context.Context, host string) ([]Endpoint, error)
+
+// Endpoints calls the function
+func (f ResolverFunc) Endpoints(ctx context.Context, host string) ([]Endpoint, error) {
+	return f(ctx, host)
+}
+
+// StaticResolver resolves the hosts from a fixed mapping, other hosts are sent to as is
+type StaticResolver map[string][]Endpoint
+
+// Endpoints returns the endpoints mapped to the host
+func (s StaticResolver) Endpoints(_ context.Context, host string) ([]Endpoint, error) {
+	return s[host], nil
+}
+
+// SetResolver resolves the endpoints of the request host once per request, sending its attempts to them in turn
+// starting from one picked as per the weights. The request URL is used as is when no endpoint is resolved,
+// and resolution errors fail the request. The resolver is not used along with an endpoint pool, and requests
+// rebased to parallel or fallback endpoints are sent to them as is.
+func (c Controller) SetResolver(resolver Resolver) Controller {
+	c.config.resolver = resolver
+	return c
+}
+
+// resolveEndpoints looks up the endpoints of the request host, ordered from the one receiving the first attempt
+func (c *Controller) resolveEndpoints() ([]Endpoint, error) {
+	if c.config.resolver == nil || c.config.pool != nil {
+		return nil, nil
+	}
+
+	endpoints, err := c.config.resolver.Endpoints(c.ctx, c.req.URL.Host)
+	if err != nil {
+		return nil, fmt.Errorf("reqctl: resolving %s: %w", c.req.URL.Host, err)
+	}
+	if len(endpoints) == 0 {
+		return nil, nil
+	}
+
+	total := 0
+	for _, endpoint := range endpoints {
+		total += endpointWeight(endpoint)
+	}
+
+	first, n := 0, rand.Intn(total)
+	for i, endpoint := range endpoints {
+		if n -= endpointWeight(endpoint); n < 0 {
+			first = i
+			break
+		}
+	}
+
+	ordered := make([]Endpoint, 0, len(endpoints))
+	ordered = append(ordered, endpoints[first:]...)
+	return append(ordered, endpoints[:first]...), nil
+}
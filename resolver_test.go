@@ -0,0 +1,57 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestResolver(t *testing.T) {
+	resolver := reqctl.StaticResolver{
+		"primary": {endpoint("a", 0), endpoint("b", 0)},
+	}
+	do := func(rawURL string, counter *hostCounter) {
+		request, _ := http.NewRequest("GET", rawURL, nil)
+		_, _ = reqctl.Request(context.Background(), request).
+			SetSimpleRetryWithChecker(0, 3, reqctl.RetryOnStatus(503)).
+			SetResolver(resolver).
+			SetClient(counter.client()).
+			Do()
+	}
+
+	counter := &hostCounter{hosts: map[string]int{}}
+	do("http://primary/items", counter)
+	if counter.hosts["a"] != 2 || counter.hosts["b"] != 2 || counter.hosts["primary"] != 0 {
+		t.Errorf("Expected the attempts to alternate between the resolved endpoints, got %v", counter.hosts)
+	}
+
+	counter = &hostCounter{hosts: map[string]int{}}
+	do("http://other/items", counter)
+	if counter.hosts["other"] != 4 {
+		t.Errorf("Expected unresolved hosts to be sent to as is, got %v", counter.hosts)
+	}
+}
+
+func TestResolverError(t *testing.T) {
+	errDiscovery := errors.New("discovery unavailable")
+	resolver := reqctl.ResolverFunc(func(ctx context.Context, host string) ([]reqctl.Endpoint, error) {
+		return nil, errDiscovery
+	})
+
+	counter := &hostCounter{hosts: map[string]int{}}
+	request, _ := http.NewRequest("GET", "http://primary/items", nil)
+	_, err := reqctl.Request(context.Background(), request).
+		SetResolver(resolver).
+		SetClient(counter.client()).
+		Do()
+
+	if !errors.Is(err, errDiscovery) {
+		t.Errorf("Expected the resolution error, got %v", err)
+	}
+	if len(counter.hosts) != 0 {
+		t.Errorf("Expected no attempts on resolution failure, got %v", counter.hosts)
+	}
+}
@@ -0,0 +1,155 @@
+package reqctl
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultMaxBodyBytes bounds the body read by the Result helpers
+const DefaultMaxBodyBytes = 32 << 20
+
+// ErrResponseTooLarge is returned when the response body exceeds the size limit
+var ErrResponseTooLarge = errors.New("reqctl: response body too large")
+
+// ResponseTooLargeError is returned while reading a response body exceeding the limit, it matches ErrResponseTooLarge
+type ResponseTooLargeError struct {
+	Limit int64
+}
+
+// Error describes the exceeded limit
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("reqctl: response body exceeds %d bytes", e.Limit)
+}
+
+// Is reports whether the target is ErrResponseTooLarge
+func (e *ResponseTooLargeError) Is(target error) bool {
+	return target == ErrResponseTooLarge
+}
+
+// limitedBody fails the reads with a *ResponseTooLargeError once the body exceeds the limit
+type limitedBody struct {
+	io.ReadCloser
+	limit     int64
+	remaining int64
+	err       error
+}
+
+// Read reads the body as long as it is within the limit
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	// A single byte beyond the limit is read to tell an exceeding body from one matching the limit
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) <= b.remaining {
+		b.remaining -= int64(n)
+		return n, err
+	}
+
+	n, b.remaining = int(b.remaining), 0
+	b.err = &ResponseTooLargeError{Limit: b.limit}
+	return n, b.err
+}
+
+// readBody reads the whole body up to the limit & closes it
+func readBody(resp *http.Response, limit int64) ([]byte, error) {
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, &ResponseTooLargeError{Limit: limit}
+	}
+	return data, nil
+}
+
+// SetMaxResponseBytes bounds the body of the response to n bytes, reads beyond failing with a *ResponseTooLargeError.
+// It protects the callers from unexpectedly huge payloads, whichever attempt obtained them. 0 disables the limit.
+func (c Controller) SetMaxResponseBytes(n int64) Controller {
+	c.config.maxResponseBytes = n
+	return c
+}
+
+// limitBody applies the response size limit to the body
+func (c *Controller) limitBody(resp *http.Response) {
+	if n := c.config.maxResponseBytes; n > 0 && resp != nil && resp.Body != nil {
+		resp.Body = &limitedBody{ReadCloser: resp.Body, limit: n, remaining: n}
+	}
+}
+
+// settled returns the error of the request, or an error if no response was obtained
+func (r Result) settled() error {
+	if r.Err != nil {
+		closeBody(r.Response)
+		return r.Err
+	} else if r.Response == nil {
+		return errors.New("reqctl: no response obtained")
+	}
+	return nil
+}
+
+// Bytes reads the whole response body, up to DefaultMaxBodyBytes, & closes it.
+// The error of the request is returned as is, the body is returned whatever the status of the response.
+func (r Result) Bytes() ([]byte, error) {
+	var data []byte
+	_, err := r.consume(func(resp *http.Response) (err error) {
+		data, err = readBody(resp, DefaultMaxBodyBytes)
+		return err
+	})
+	return data, err
+}
+
+// String reads the whole response body as a string, refer Result.Bytes
+func (r Result) String() (string, error) {
+	data, err := r.Bytes()
+	return string(data), err
+}
+
+// DecodeJSON decodes the JSON response body into v & closes it.
+// Responses whose status is not 2xx are not decoded, returning a *StatusError instead.
+func (r Result) DecodeJSON(v any) error {
+	return r.decode(v, json.Unmarshal)
+}
+
+// DecodeXML decodes the XML response body into v & closes it, refer Result.DecodeJSON
+func (r Result) DecodeXML(v any) error {
+	return r.decode(v, xml.Unmarshal)
+}
+
+// decode checks the status of the response & unmarshals its body into v
+func (r Result) decode(v any, unmarshal func([]byte, any) error) error {
+	_, err := r.consume(func(resp *http.Response) error {
+		if err := checkStatus(resp, nil); err != nil {
+			return err
+		}
+
+		data, err := readBody(resp, DefaultMaxBodyBytes)
+		if err != nil {
+			return err
+		}
+		if err := unmarshal(data, v); err != nil {
+			return fmt.Errorf("reqctl: decoding response: %w", err)
+		}
+		return nil
+	})
+	return err
+}
+
+// DoResult builds & executes the request as per the policy, refer Controller.DoResult
+func (b *Builder) DoResult() Result {
+	c, err := b.Controller()
+	if err != nil {
+		return Result{Err: err}
+	}
+	return c.DoResult()
+}
@@ -0,0 +1,91 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestResultBodyHelpers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			_, _ = w.Write([]byte(`{"id": 7}`))
+		case "/xml":
+			_, _ = w.Write([]byte(`<item><id>8</id></item>`))
+		case "/large":
+			_, _ = w.Write([]byte(strings.Repeat("x", reqctl.DefaultMaxBodyBytes+1)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("missing"))
+		}
+	}))
+	defer server.Close()
+
+	get := func(path string) reqctl.Result {
+		return reqctl.Get(context.Background(), server.URL+path).DoResult()
+	}
+
+	var item struct {
+		ID int `json:"id" xml:"id"`
+	}
+	if err := get("/json").DecodeJSON(&item); err != nil || item.ID != 7 {
+		t.Errorf("Expected the JSON body to be decoded, got %+v, %v", item, err)
+	}
+	if err := get("/xml").DecodeXML(&item); err != nil || item.ID != 8 {
+		t.Errorf("Expected the XML body to be decoded, got %+v, %v", item, err)
+	}
+
+	var statusErr *reqctl.StatusError
+	if err := get("/missing").DecodeJSON(&item); !errors.As(err, &statusErr) || statusErr.StatusCode != 404 {
+		t.Errorf("Expected a status error, got %v", err)
+	}
+	if body, err := get("/missing").String(); err != nil || body != "missing" {
+		t.Errorf("Expected the body whatever the status, got %q, %v", body, err)
+	}
+
+	if _, err := get("/large").Bytes(); !errors.Is(err, reqctl.ErrResponseTooLarge) {
+		t.Errorf("Expected the size limit to be enforced, got %v", err)
+	}
+
+	if _, err := reqctl.Get(context.Background(), "://bad").DoResult().Bytes(); err == nil {
+		t.Errorf("Expected the request error to be returned")
+	}
+}
+
+func TestMaxResponseBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", 64)))
+	}))
+	defer server.Close()
+
+	read := func(limit int64) (string, error) {
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		resp, err := reqctl.Request(context.Background(), req).SetMaxResponseBytes(limit).Do()
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	if body, err := read(64); err != nil || len(body) != 64 {
+		t.Errorf("Expected a body matching the limit to be read, got %d bytes, %v", len(body), err)
+	}
+
+	body, err := read(10)
+	var tooLarge *reqctl.ResponseTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Limit != 10 || !errors.Is(err, reqctl.ErrResponseTooLarge) {
+		t.Errorf("Expected a response too large error, got %v", err)
+	}
+	if len(body) != 10 {
+		t.Errorf("Expected the body to be read up to the limit, got %d bytes", len(body))
+	}
+}
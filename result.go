@@ -0,0 +1,154 @@
+package reqctl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// redactedValue replaces the values of sensitive headers in the attempt records
+const redactedValue = "[REDACTED]"
+
+// redactedHeaders are the headers whose values are never retained in the attempt records
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-Api-Key"}
+
+// AttemptRecord describes a completed attempt of the logical request
+type AttemptRecord struct {
+	Attempt
+	// Request is the attempt as sent without its body, sensitive header values are redacted
+	Request *http.Request
+	// StatusCode of the response, 0 if no response was obtained
+	StatusCode int
+	// Err returned by the attempt
+	Err      error
+	Start    time.Time
+	Duration time.Duration
+	// Timings of the attempt phases, captured only when enabled via Controller.SetTraceTimings
+	Timings *Timings
+}
+
+// newAttemptRecord captures the attempt, redacting the sensitive headers of the request
+func newAttemptRecord(attempt Attempt, req *http.Request, start time.Time, resp *http.Response, err error,
+	tracer *attemptTracer) AttemptRecord {
+	sent := req.Clone(context.Background())
+	sent.Body, sent.GetBody = nil, nil
+	for _, name := range redactedHeaders {
+		if sent.Header.Get(name) != "" {
+			sent.Header.Set(name, redactedValue)
+		}
+	}
+
+	rec := AttemptRecord{
+		Attempt:  attempt,
+		Request:  sent,
+		Err:      err,
+		Start:    start,
+		Duration: time.Since(start),
+		Timings:  tracer.result(),
+	}
+	if resp != nil {
+		rec.StatusCode = resp.StatusCode
+	}
+	return rec
+}
+
+// Result is the outcome of a logical request along with the record of its attempts
+type Result struct {
+	Response *http.Response
+	Err      error
+	// Attempts are ordered by their sequence number. They are recorded for sampled & failed requests only.
+	Attempts []AttemptRecord
+	// AttemptCount is the number of attempts completed, across retries & parallel calls
+	AttemptCount int
+	// Durations of the completed attempts ordered by their sequence number, recorded for every request
+	Durations []time.Duration
+	// Elapsed is the time taken by the logical request until the response headers or the error, as per its clock
+	Elapsed time.Duration
+	// Hedged reports whether the response was obtained by a parallel call fired after the first one
+	Hedged bool
+	// FromCache reports whether the response was served by the cache, without any attempt
+	FromCache bool
+	// Shared reports whether the response is a copy of the one obtained by an identical request in flight
+	Shared bool
+
+	ctrl    *Controller
+	client  *http.Client
+	records []AttemptRecord
+}
+
+// newResult builds the result of the execution
+func (c *Controller) newResult(client *http.Client, exec *execution, resp *http.Response, err error) Result {
+	records := exec.attemptRecords()
+	res := Result{
+		Response:     resp,
+		Err:          err,
+		AttemptCount: len(records),
+		Durations:    make([]time.Duration, 0, len(records)),
+		Elapsed:      c.clock().Now().Sub(exec.start),
+		ctrl:         c,
+		client:       client,
+		records:      records,
+	}
+
+	for _, rec := range records {
+		res.Durations = append(res.Durations, rec.Duration)
+	}
+	if resp != nil && resp.Request != nil {
+		attempt, _ := AttemptFromContext(resp.Request.Context())
+		res.Hedged = attempt.Hedged
+	}
+
+	if exec.sampled || err != nil {
+		res.Attempts = records
+	}
+	return res
+}
+
+// DoResult executes the request like Do, returning the outcome along with the record of its attempts
+func (c Controller) DoResult() Result {
+	return c.run(c.client())
+}
+
+// DoResult executes the request as per the policy, refer Controller.DoResult
+func (p Policy) DoResult(ctx context.Context, req *http.Request) Result {
+	return p.Request(ctx, req).DoResult()
+}
+
+// DoResult executes the request as per the client policy, refer Controller.DoResult
+func (c *Client) DoResult(ctx context.Context, req *http.Request) Result {
+	return c.policy.DoResult(ctx, req)
+}
+
+// ReplayAttempt re-sends the recorded attempt once, with the same client, URL, host & headers as it was sent.
+// Redacted header values & the body are restored from the original request, the redacted headers it lacks,
+// eg: set by an authenticator, being left out.
+func (r Result) ReplayAttempt(ctx context.Context, i int) (*http.Response, error) {
+	if i < 0 || i >= len(r.Attempts) {
+		return nil, fmt.Errorf("reqctl: no attempt %d recorded, %d available", i, len(r.Attempts))
+	}
+
+	rec := r.Attempts[i]
+	orig := r.ctrl.req
+	req := rec.Request.Clone(withAttempt(ctx, rec.Attempt))
+	for name, values := range req.Header {
+		if len(values) != 1 || values[0] != redactedValue {
+			continue
+		}
+		if values, ok := orig.Header[name]; ok {
+			req.Header[name] = append([]string(nil), values...)
+		} else {
+			req.Header.Del(name)
+		}
+	}
+
+	if orig.GetBody != nil {
+		body, err := orig.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req.Body, req.GetBody, req.ContentLength = body, orig.GetBody, orig.ContentLength
+	}
+
+	return r.client.Do(req)
+}
@@ -0,0 +1,141 @@
+package reqctl_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestReplayAttempt(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, r.Header.Get("Authorization")+"|"+r.Header.Get("X-Attempt")+"|"+string(body))
+		mu.Unlock()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	request, err := http.NewRequest("POST", server.URL, strings.NewReader("payload"))
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+	request.Header.Set("Authorization", "Bearer secret")
+
+	checker := func(resp *http.Response, err error) bool {
+		return err != nil || resp.StatusCode >= 500
+	}
+
+	res := reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(time.Millisecond, 1, checker).
+		SetRetryNonIdempotent(true).
+		SetCorrelationHeaders("", "X-Attempt").
+		DoResult()
+	if res.Err != nil {
+		t.Errorf("Obtained error: %v", res.Err)
+		return
+	}
+	res.Response.Body.Close()
+
+	if len(res.Attempts) != 2 || res.Attempts[0].StatusCode != 503 || res.Attempts[1].Seq != 2 {
+		t.Errorf("Expected 2 recorded attempts, got %+v", res.Attempts)
+		return
+	}
+
+	if auth := res.Attempts[0].Request.Header.Get("Authorization"); auth == "Bearer secret" {
+		t.Errorf("Expected the authorization header to be redacted in the record")
+	}
+
+	resp, err := res.ReplayAttempt(context.Background(), 0)
+	if err != nil {
+		t.Errorf("Obtained error on replay: %v", err)
+		return
+	}
+	resp.Body.Close()
+
+	expected := []string{"Bearer secret|1|payload", "Bearer secret|2|payload", "Bearer secret|1|payload"}
+	if strings.Join(received, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected calls %v, got %v", expected, received)
+	}
+}
+
+func TestReplayAttemptFallback(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	handler := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			received = append(received, name+"|"+string(body))
+			mu.Unlock()
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+	}
+	primary := httptest.NewServer(handler("primary"))
+	defer primary.Close()
+	fallback := httptest.NewServer(handler("fallback"))
+	defer fallback.Close()
+
+	endpoint, _ := url.Parse(fallback.URL)
+	request, _ := http.NewRequest("PUT", primary.URL, strings.NewReader("payload"))
+	res := reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(0, 0, reqctl.RetryOnStatus(503)).
+		SetFallbackEndpoints(endpoint).
+		DoResult()
+	if len(res.Attempts) != 2 {
+		t.Fatalf("Expected 2 recorded attempts, got %+v", res.Attempts)
+	}
+	res.Response.Body.Close()
+
+	// The attempt is replayed against the endpoint it was sent to
+	resp, err := res.ReplayAttempt(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Obtained error on replay: %v", err)
+	}
+	resp.Body.Close()
+
+	expected := "primary|payload,fallback|payload,fallback|payload"
+	if got := strings.Join(received, ","); got != expected {
+		t.Errorf("Expected calls %v, got %v", expected, got)
+	}
+}
+
+func TestResultMetadata(t *testing.T) {
+	calls := 0
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			if calls++; calls < 3 {
+				return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+			}
+			return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	var doer reqctl.ResultDoer = reqctl.NewClient(client, reqctl.NewPolicy().
+		WithSimpleRetryWithChecker(time.Millisecond, 5, reqctl.RetryOnStatus(503)))
+
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	res := doer.DoResult(context.Background(), request)
+	if res.Err != nil {
+		t.Fatalf("Obtained error: %v", res.Err)
+	}
+	res.Response.Body.Close()
+
+	if res.AttemptCount != 3 || len(res.Durations) != 3 || res.Hedged {
+		t.Errorf("Expected 3 attempts without hedging, got %+v", res)
+	}
+	if res.Elapsed < 2*time.Millisecond {
+		t.Errorf("Expected the elapsed time to include the retry waits, got %s", res.Elapsed)
+	}
+}
@@ -0,0 +1,72 @@
+package reqctl
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// retryAfterConfig holds whether & up to when the Retry-After header is honored
+type retryAfterConfig struct {
+	respect bool
+	max     time.Duration
+}
+
+// SetRespectRetryAfter makes the retries of 429 & 503 responses wait as long as their Retry-After header asks
+// ( in seconds or as an HTTP date ), instead of the configured backoff. The wait is capped at max, unless max is 0.
+func (c Controller) SetRespectRetryAfter(respect bool, max time.Duration) Controller {
+	c.config.retryAfter = retryAfterConfig{
+		respect: respect,
+		max:     max,
+	}
+	return c
+}
+
+// retryAfter returns the wait asked by the response, if it shall be honored
+func (c *Controller) retryAfter(resp *http.Response) (time.Duration, bool) {
+	cfg := c.config.retryAfter
+	if !cfg.respect || resp == nil ||
+		(resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+
+	wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if !ok {
+		return 0, false
+	}
+
+	if cfg.max > 0 && wait > cfg.max {
+		wait = cfg.max
+	}
+	return wait, true
+}
+
+// parseRetryAfter parses the Retry-After header value relative to now, dates in the past yield no wait
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		if seconds > math.MaxInt64/int64(time.Second) {
+			return time.Duration(math.MaxInt64), true
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	if wait := date.Sub(now); wait > 0 {
+		return wait, true
+	}
+	return 0, true
+}
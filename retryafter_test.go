@@ -0,0 +1,52 @@
+package reqctl_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestRespectRetryAfter(t *testing.T) {
+	var times []time.Time
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			times = append(times, time.Now())
+			header := http.Header{}
+			header.Set("Retry-After", "1")
+			return &http.Response{StatusCode: 429, Header: header, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	do := func(ctlr reqctl.Controller) time.Duration {
+		times = nil
+		resp, err := ctlr.SetClient(client).Do()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body.Close()
+
+		if len(times) != 2 {
+			t.Fatalf("Expected 2 attempts, got %d", len(times))
+		}
+		return times[1].Sub(times[0])
+	}
+
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	checker := func(resp *http.Response, err error) bool {
+		return err != nil || resp.StatusCode == 429
+	}
+
+	if gap := do(reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(5*time.Millisecond, 1, checker)); gap > 500*time.Millisecond {
+		t.Errorf("Expected Retry-After to be ignored by default, waited %v", gap)
+	}
+
+	if gap := do(reqctl.Request(context.Background(), request).
+		SetSimpleRetryWithChecker(5*time.Millisecond, 1, checker).
+		SetRespectRetryAfter(true, 50*time.Millisecond)); gap < 50*time.Millisecond || gap > 500*time.Millisecond {
+		t.Errorf("Expected Retry-After to be capped at 50ms, waited %v", gap)
+	}
+}
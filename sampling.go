@@ -0,0 +1,32 @@
+package reqctl
+
+import (
+	"math/rand"
+	"net/http"
+)
+
+// Sampler decides whether the verbose observability data of a logical request shall be recorded.
+// Failed requests are always recorded, irrespective of the sampling decision.
+type Sampler func(req *http.Request) bool
+
+// SampleRate returns a sampler which samples the given fraction of requests, eg: 0.01 for 1%
+func SampleRate(rate float64) Sampler {
+	return func(*http.Request) bool {
+		return rate >= 1 || (rate > 0 && rand.Float64() < rate)
+	}
+}
+
+// SetSampler restricts verbose observability ( attempt timelines, dumps & traces ) to the sampled requests
+// and to failures. Without a sampler every request is recorded.
+func (c Controller) SetSampler(sampler Sampler) Controller {
+	c.config.sampler = sampler
+	return c
+}
+
+// sample makes the sampling decision for a logical request
+func (c *Controller) sample() bool {
+	if c.config.sampler == nil {
+		return true
+	}
+	return c.config.sampler(c.req)
+}
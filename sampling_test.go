@@ -0,0 +1,42 @@
+package reqctl_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestSampler(t *testing.T) {
+	request, err := http.NewRequest("GET", "http://localhost", nil)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	var sampled []bool
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			attempt, _ := reqctl.AttemptFromContext(r.Context())
+			sampled = append(sampled, attempt.Sampled)
+			return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	ctlr := reqctl.Request(request.Context(), request).SetClient(client)
+	if _, err = ctlr.Do(); err != nil {
+		t.Errorf("Obtained error: %v", err)
+	}
+
+	if _, err = ctlr.SetSampler(reqctl.SampleRate(0)).Do(); err != nil {
+		t.Errorf("Obtained error: %v", err)
+	}
+
+	if _, err = ctlr.SetSampler(reqctl.SampleRate(1)).Do(); err != nil {
+		t.Errorf("Obtained error: %v", err)
+	}
+
+	if len(sampled) != 3 || !sampled[0] || sampled[1] || !sampled[2] {
+		t.Errorf("Expected sampling decisions [true false true], got %v", sampled)
+	}
+}
@@ -0,0 +1,115 @@
+package reqctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrUnacceptable is the error of a scattered request whose response was not accepted
+	ErrUnacceptable = errors.New("reqctl: response not accepted")
+	// ErrNotGathered is the error of a scattered request not completed in time
+	ErrNotGathered = errors.New("reqctl: request not completed before the gather deadline")
+)
+
+// scattered is the result of a scattered controller
+type scattered struct {
+	index int
+	res   Result
+}
+
+// scatter executes every controller at once, bound to ctx while in flight
+func scatter(ctx context.Context, ctrls []*Controller) <-chan scattered {
+	results := make(chan scattered, len(ctrls))
+	for i, c := range ctrls {
+		go func(i int, c Controller) {
+			results <- scattered{index: i, res: runBound(ctx, c)}
+		}(i, *c)
+	}
+	return results
+}
+
+// discard closes the responses of the remaining results as they complete
+func discard(results <-chan scattered, remaining int) {
+	if remaining == 0 {
+		return
+	}
+	go func() {
+		for i := 0; i < remaining; i++ {
+			closeBody((<-results).res.Response)
+		}
+	}()
+}
+
+// ScatterFirst executes the heterogeneous requests at once, eg: to the shards holding a replica, returning the index
+// & result of the first one accepted. The others are cancelled & their responses closed. A nil accept accepts the
+// outcomes not deemed failed by the retry checker of their controller. If none is accepted, the index is -1 & the
+// error of the result is a *BatchError detailing every request.
+func ScatterFirst(ctx context.Context, ctrls []*Controller, accept func(Result) bool) (int, Result) {
+	sCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := scatter(sCtx, ctrls)
+	failures := make([]BatchFailure, len(ctrls))
+	for n := 0; n < len(ctrls); n++ {
+		s := <-results
+		accepted := s.res.Err == nil && !ctrls[s.index].failed(s.res.Response, nil)
+		if accept != nil {
+			accepted = accept(s.res)
+		}
+		if accepted {
+			discard(results, len(ctrls)-n-1)
+			return s.index, s.res
+		}
+
+		err := s.res.Err
+		if err == nil {
+			err = fmt.Errorf("%w: status %d", ErrUnacceptable, s.res.Response.StatusCode)
+		}
+		closeBody(s.res.Response)
+		failures[s.index] = BatchFailure{Index: s.index, Err: err}
+	}
+
+	if len(failures) == 0 {
+		return -1, Result{Err: fmt.Errorf("%w: no request scattered", ErrUnacceptable)}
+	}
+	return -1, Result{Err: &BatchError{Failures: failures}}
+}
+
+// ScatterAll executes the heterogeneous requests at once, gathering their results in the order of the controllers
+// until they all complete or within the duration, unbounded if not positive. Results not gathered in time fail
+// with ErrNotGathered, the requests being cancelled, so that the partial results can be used.
+func ScatterAll(ctx context.Context, ctrls []*Controller, within time.Duration) []Result {
+	sCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var deadline <-chan time.Time
+	if within > 0 {
+		timer := time.NewTimer(within)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	gathered := make([]Result, len(ctrls))
+	done := make([]bool, len(ctrls))
+	results := scatter(sCtx, ctrls)
+gather:
+	for n := 0; n < len(ctrls); n++ {
+		select {
+		case s := <-results:
+			gathered[s.index], done[s.index] = s.res, true
+		case <-deadline:
+			discard(results, len(ctrls)-n)
+			break gather
+		}
+	}
+
+	for i := range gathered {
+		if !done[i] {
+			gathered[i] = Result{Err: ErrNotGathered}
+		}
+	}
+	return gathered
+}
@@ -0,0 +1,85 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+// shard answers with the status after the delay
+type shard struct {
+	status int
+	delay  time.Duration
+}
+
+// shards starts a server per shard, returning the controllers of their requests
+func shards(t *testing.T, steps ...shard) []*reqctl.Controller {
+	ctrls := make([]*reqctl.Controller, 0, len(steps))
+	for _, step := range steps {
+		step := step
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(step.delay):
+			case <-r.Context().Done():
+				return
+			}
+			w.WriteHeader(step.status)
+			_, _ = w.Write([]byte(http.StatusText(step.status)))
+		}))
+		t.Cleanup(server.Close)
+
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		c := reqctl.Request(context.Background(), req).
+			SetSimpleRetryWithChecker(time.Millisecond, 0, reqctl.RetryOnStatus(503))
+		ctrls = append(ctrls, &c)
+	}
+	return ctrls
+}
+
+func TestScatterFirst(t *testing.T) {
+	ctrls := shards(t, shard{503, 0}, shard{200, 20 * time.Millisecond}, shard{200, time.Second})
+
+	start := time.Now()
+	idx, res := reqctl.ScatterFirst(context.Background(), ctrls, nil)
+	if idx != 1 {
+		t.Fatalf("Expected the first acceptable shard, got %d, %v", idx, res.Err)
+	}
+	if body, err := res.String(); err != nil || body != "OK" {
+		t.Errorf("Expected the body of the accepted response, got %q, %v", body, err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the slow shard to be cancelled, took %v", elapsed)
+	}
+
+	ctrls = shards(t, shard{503, 0}, shard{404, 0})
+	idx, res = reqctl.ScatterFirst(context.Background(), ctrls, func(r reqctl.Result) bool {
+		return r.Err == nil && r.Response.StatusCode == 200
+	})
+	var batchErr *reqctl.BatchError
+	if idx != -1 || !errors.As(res.Err, &batchErr) || len(batchErr.Failures) != 2 ||
+		!errors.Is(batchErr.Failures[1].Err, reqctl.ErrUnacceptable) {
+		t.Errorf("Expected every shard to be rejected, got %d, %v", idx, res.Err)
+	}
+}
+
+func TestScatterAll(t *testing.T) {
+	ctrls := shards(t, shard{200, 0}, shard{404, 10 * time.Millisecond}, shard{200, time.Second})
+
+	results := reqctl.ScatterAll(context.Background(), ctrls, 200*time.Millisecond)
+	if body, err := results[0].String(); err != nil || body != "OK" {
+		t.Errorf("Expected the first shard to be gathered, got %q, %v", body, err)
+	}
+	if results[1].Err != nil || results[1].Response.StatusCode != 404 {
+		t.Errorf("Expected the second shard to be gathered, got %v", results[1].Err)
+	} else {
+		results[1].Response.Body.Close()
+	}
+	if !errors.Is(results[2].Err, reqctl.ErrNotGathered) {
+		t.Errorf("Expected the slow shard not to be gathered, got %v", results[2].Err)
+	}
+}
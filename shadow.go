@@ -0,0 +1,175 @@
+package reqctl
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultShadowTimeout bounds the shadow requests of controllers without a timeout
+	defaultShadowTimeout = 30 * time.Second
+	// maxShadows bounds the shadow requests in flight per SetShadow, the requests beyond not being mirrored
+	maxShadows = 64
+)
+
+// ShadowFunc receives the outcome of the shadow request along with the primary response, eg: for diffing them.
+// The primary response is a copy whose body is the primary body as read by the caller, up to 1MB, passed once the
+// caller read it whole or closed it. It is nil when the primary call failed, or its body was not consumed in time.
+type ShadowFunc func(primary *http.Response, shadow *http.Response, err error)
+
+// shadowConfig holds the shadow endpoint & the consumer of its responses
+type shadowConfig struct {
+	endpoint *url.URL
+	onShadow ShadowFunc
+	slots    chan struct{}
+}
+
+// SetShadow mirrors every request to the shadow endpoint, eg: a new backend being validated before cutover.
+// The shadow request is sent once in the background, bounded by the timeout or else 30s, and never affects the
+// result or latency of the primary call. Its response is passed to onShadow, if not nil, then drained & closed.
+// Requests whose body cannot be replayed, or made while 64 shadow requests are in flight, are not mirrored.
+func (c Controller) SetShadow(endpoint *url.URL, onShadow ShadowFunc) Controller {
+	c.config.shadow = &shadowConfig{
+		endpoint: endpoint,
+		onShadow: onShadow,
+		slots:    make(chan struct{}, maxShadows),
+	}
+	return c
+}
+
+// shadowBody copies the primary body as it is read, handing the copy over once it is read whole or closed
+type shadowBody struct {
+	io.ReadCloser
+	limit   int
+	deliver func(body []byte)
+
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	once sync.Once
+}
+
+// Read reads the primary body, copying it up to the limit
+func (s *shadowBody) Read(p []byte) (int, error) {
+	n, err := s.ReadCloser.Read(p)
+	s.mu.Lock()
+	if room := s.limit - s.buf.Len(); room > 0 {
+		if n < room {
+			room = n
+		}
+		s.buf.Write(p[:room])
+	}
+	s.mu.Unlock()
+	if err != nil {
+		s.done()
+	}
+	return n, err
+}
+
+// Close closes the primary body, handing the copy over if not yet
+func (s *shadowBody) Close() error {
+	err := s.ReadCloser.Close()
+	s.done()
+	return err
+}
+
+// done hands the copy of the body over, once
+func (s *shadowBody) done() {
+	s.once.Do(func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.deliver(append([]byte(nil), s.buf.Bytes()...))
+	})
+}
+
+// detachedContext keeps the values of its parent, without its deadline & cancellation
+type detachedContext struct {
+	context.Context
+}
+
+// Deadline returns no deadline
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// Done returns a channel which is never closed
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+// Err returns nil as the context is never cancelled
+func (detachedContext) Err() error {
+	return nil
+}
+
+// startShadow sends the shadow request, returning the function to call with the primary outcome
+func (c *Controller) startShadow(client *http.Client) func(*http.Response, error) {
+	cfg := c.config.shadow
+	if cfg == nil || !isReplayable(c.req) {
+		return func(*http.Response, error) {}
+	}
+	select {
+	case cfg.slots <- struct{}{}:
+	default:
+		return func(*http.Response, error) {}
+	}
+
+	// The shadow outlives the primary call, hence it is detached from the cancellation of the request
+	timeout := c.config.timeout
+	if timeout <= 0 {
+		timeout = defaultShadowTimeout
+	}
+	ctx, cancel := context.WithTimeout(detachedContext{c.ctx}, timeout)
+	release := func() {
+		cancel()
+		<-cfg.slots
+	}
+
+	req := rebase(c.req, cfg.endpoint).WithContext(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			release()
+			return func(*http.Response, error) {}
+		}
+		req.Body = body
+	}
+
+	primaryCh := make(chan *http.Response, 1)
+	go func() {
+		defer release()
+		resp, err := client.Do(req)
+		if cfg.onShadow != nil {
+			var primary *http.Response
+			select {
+			case primary = <-primaryCh:
+			case <-ctx.Done():
+			}
+			cfg.onShadow(primary, resp, err)
+		}
+		closeBody(resp)
+	}()
+
+	return func(resp *http.Response, err error) {
+		if resp == nil || err != nil {
+			primaryCh <- nil
+			return
+		}
+
+		primary := *resp
+		primary.Header = resp.Header.Clone()
+		primary.Body = http.NoBody
+		if cfg.onShadow == nil || resp.Body == nil || resp.Body == http.NoBody {
+			primaryCh <- &primary
+			return
+		}
+		resp.Body = &shadowBody{ReadCloser: resp.Body, limit: defaultCachedBodyBytes, deliver: func(body []byte) {
+			primary.Body = io.NopCloser(bytes.NewReader(body))
+			primaryCh <- &primary
+		}}
+	}
+}
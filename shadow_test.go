@@ -0,0 +1,75 @@
+package reqctl_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestShadow(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("primary"))
+	}))
+	defer primary.Close()
+
+	bodies := make(chan string, 1)
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+
+		// A slow shadow must not delay the primary call
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer shadow.Close()
+
+	type diff struct {
+		primary, shadow int
+		body            string
+	}
+	diffs := make(chan diff, 1)
+	onShadow := func(p *http.Response, s *http.Response, err error) {
+		if err != nil || p == nil {
+			t.Errorf("Expected both calls to complete, got %v", err)
+			diffs <- diff{}
+			return
+		}
+		primaryBody, _ := io.ReadAll(p.Body)
+		diffs <- diff{p.StatusCode, s.StatusCode, string(primaryBody)}
+	}
+
+	shadowURL, _ := url.Parse(shadow.URL)
+	request, _ := http.NewRequest("PUT", primary.URL, strings.NewReader("payload"))
+	ctx, cancel := context.WithCancel(context.Background())
+
+	start := time.Now()
+	resp, err := reqctl.Request(ctx, request).
+		SetShadow(shadowURL, onShadow).
+		SetTimeout(time.Second).
+		Do()
+	if err != nil {
+		t.Fatalf("Obtained error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "primary" || time.Since(start) > 80*time.Millisecond {
+		t.Errorf("Expected the primary response without waiting for the shadow, got %q", body)
+	}
+
+	// Cancelling the request does not abort the shadow
+	cancel()
+
+	if got := <-bodies; got != "payload" {
+		t.Errorf("Expected the shadow to receive the body, got %q", got)
+	}
+	if d := <-diffs; d.primary != 200 || d.shadow != 500 || d.body != "primary" {
+		t.Errorf("Expected the shadow outcome to be compared with the primary, got %+v", d)
+	}
+}
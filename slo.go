@@ -0,0 +1,143 @@
+package reqctl
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// sloBuckets is the number of buckets the SLO window is split into
+const sloBuckets = 10
+
+// BurnAlert describes an SLO whose error budget is burning faster than the threshold
+type BurnAlert struct {
+	Name     string
+	BurnRate float64
+	Total    int64
+	Bad      int64
+	Window   time.Duration
+}
+
+// SLOConfig defines the objective tracked for the requests of a policy or controller
+type SLOConfig struct {
+	// Name identifies the objective within alerts
+	Name string
+	// Target is the fraction of good requests, eg: 0.999
+	Target float64
+	// Latency marks successful requests slower than it as bad, zero ignores latency
+	Latency time.Duration
+	// Window is the rolling window over which the burn rate is computed
+	Window time.Duration
+	// BurnRateThreshold is the burn rate beyond which OnBurn is invoked, eg: 14.4
+	BurnRateThreshold float64
+	// MinEvents is the number of requests required within the window before alerting
+	MinEvents int64
+	// OnBurn is invoked when the burn rate crosses the threshold, & again only once it recovers
+	OnBurn func(BurnAlert)
+}
+
+// sloBucket holds the request counts of a slice of the window
+type sloBucket struct {
+	start time.Time
+	total int64
+	bad   int64
+}
+
+// SLOTracker tracks the outcome of requests against an objective, alerting on fast error budget burn.
+// The logical request is good when it ends without error, below 500 & within the latency objective.
+type SLOTracker struct {
+	cfg SLOConfig
+
+	mu       sync.Mutex
+	buckets  [sloBuckets]sloBucket
+	alerting bool
+}
+
+// NewSLOTracker creates a tracker for the objective
+func NewSLOTracker(cfg SLOConfig) *SLOTracker {
+	return &SLOTracker{
+		cfg: cfg,
+	}
+}
+
+// Record registers the outcome of a logical request
+func (t *SLOTracker) Record(resp *http.Response, err error, latency time.Duration) {
+	good := err == nil && resp != nil && resp.StatusCode < 500 &&
+		(t.cfg.Latency <= 0 || latency <= t.cfg.Latency)
+
+	t.mu.Lock()
+	now := time.Now()
+	bucket := t.bucket(now)
+	bucket.total++
+	if !good {
+		bucket.bad++
+	}
+
+	total, bad := t.counts(now)
+	rate := t.burnRate(total, bad)
+	burning := total >= t.cfg.MinEvents && rate > t.cfg.BurnRateThreshold
+
+	fire := burning && !t.alerting
+	t.alerting = burning
+	t.mu.Unlock()
+
+	if fire && t.cfg.OnBurn != nil {
+		t.cfg.OnBurn(BurnAlert{
+			Name:     t.cfg.Name,
+			BurnRate: rate,
+			Total:    total,
+			Bad:      bad,
+			Window:   t.cfg.Window,
+		})
+	}
+}
+
+// BurnRate returns the current burn rate, ie: the observed error rate relative to the error budget
+func (t *SLOTracker) BurnRate() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.burnRate(t.counts(time.Now()))
+}
+
+// bucket returns the bucket for the instant, resetting it if it belongs to an elapsed window.
+// Must be called with the lock held.
+func (t *SLOTracker) bucket(now time.Time) *sloBucket {
+	width := t.cfg.Window / sloBuckets
+	if width <= 0 {
+		width = time.Nanosecond
+	}
+
+	start := now.Truncate(width)
+	bucket := &t.buckets[(start.UnixNano()/int64(width))%sloBuckets]
+	if !bucket.start.Equal(start) {
+		*bucket = sloBucket{start: start}
+	}
+	return bucket
+}
+
+// counts sums the buckets within the window. Must be called with the lock held.
+func (t *SLOTracker) counts(now time.Time) (total, bad int64) {
+	for _, bucket := range t.buckets {
+		if now.Sub(bucket.start) < t.cfg.Window {
+			total += bucket.total
+			bad += bucket.bad
+		}
+	}
+	return total, bad
+}
+
+// burnRate computes the burn rate of the counts
+func (t *SLOTracker) burnRate(total, bad int64) float64 {
+	budget := 1 - t.cfg.Target
+	if total == 0 || budget <= 0 {
+		return 0
+	}
+	return (float64(bad) / float64(total)) / budget
+}
+
+// SetSLO records the outcome & latency of every logical request in the tracker
+func (c Controller) SetSLO(tracker *SLOTracker) Controller {
+	c.config.slo = tracker
+	return c
+}
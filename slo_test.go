@@ -0,0 +1,62 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestSLOBurnAlert(t *testing.T) {
+	var alerts []reqctl.BurnAlert
+	tracker := reqctl.NewSLOTracker(reqctl.SLOConfig{
+		Name:              "upstream",
+		Target:            0.9,
+		Window:            time.Minute,
+		BurnRateThreshold: 2.5,
+		MinEvents:         4,
+		OnBurn: func(alert reqctl.BurnAlert) {
+			alerts = append(alerts, alert)
+		},
+	})
+
+	failing := false
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			if failing {
+				return nil, errors.New("connection refused")
+			}
+			return &http.Response{StatusCode: 200, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	policy := reqctl.NewPolicy().WithClient(client).WithSLO(tracker)
+	do := func() {
+		request, _ := http.NewRequest("GET", "http://localhost", nil)
+		if resp, err := policy.Do(context.Background(), request); err == nil {
+			resp.Body.Close()
+		}
+	}
+
+	for i := 0; i < 8; i++ {
+		do()
+	}
+
+	// 2 failures out of 10 burn the 10% budget at a rate of 2, which is not beyond the threshold
+	failing = true
+	do()
+	do()
+	if len(alerts) != 0 {
+		t.Errorf("Expected no alerts at burn rate %v, got %v", tracker.BurnRate(), alerts)
+	}
+
+	// The alert fires once, when crossing the threshold
+	do()
+	do()
+	if len(alerts) != 1 || alerts[0].Name != "upstream" || alerts[0].Bad != 3 {
+		t.Errorf("Expected a single alert after 3 failures, got %+v", alerts)
+	}
+}
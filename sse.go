@@ -0,0 +1,188 @@
+package reqctl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxEventBytes bounds the lines of an event stream
+const maxEventBytes = 1 << 20
+
+// Event is a server-sent event, or the error of a failed connection to the event stream
+type Event struct {
+	// ID is the last event ID of the stream, resent on reconnection
+	ID    string
+	Event string
+	Data  string
+	// Err is set on the events reporting a failed connection, delivered before reconnecting
+	Err error
+}
+
+// Subscribe connects to the event stream of the request & delivers its events on the channel, reconnecting once
+// the stream ends or fails with the Last-Event-ID of the latest event. Reconnections wait the retry delay sent by
+// the server, or else 1s, backing off as per the retry configuration of the controller on consecutive failures,
+// up to 32 of them.
+// The timeout of the controller bounds each connection until the response headers only. The channel is closed
+// once ctx is done, or when the server answers 204 No Content.
+func Subscribe(ctx context.Context, c *Controller) <-chan Event {
+	events := make(chan Event)
+	stream := *c
+	stream.ctx = ctx
+	stream.config.streaming = true
+
+	go func() {
+		defer close(events)
+		clock := c.clock()
+		var lastID string
+		reconnect := minPollBackoff
+
+		var failures int
+		var backoff time.Duration
+		for {
+			req := c.req.Clone(ctx)
+			req.Header.Set("Accept", "text/event-stream")
+			req.Header.Set("Cache-Control", "no-cache")
+			if lastID != "" {
+				req.Header.Set("Last-Event-ID", lastID)
+			}
+			stream.req = req
+
+			resp, err := stream.Do()
+			if err == nil && resp.StatusCode == http.StatusNoContent {
+				closeBody(resp)
+				return
+			}
+			if err == nil {
+				err = checkEventStream(resp)
+			}
+
+			if err == nil {
+				failures, backoff = 0, 0
+				err = readEvents(ctx, resp, events, &lastID, &reconnect)
+				resp.Body.Close()
+			} else {
+				closeBody(resp)
+				if failures < maxPollFailures {
+					failures++
+				}
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				select {
+				case events <- Event{ID: lastID, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			delay := reconnect
+			if failures > 0 {
+				if backoff = stream.backoff(failures-1, backoff); backoff > delay {
+					delay = backoff
+				}
+			}
+			if err := clock.Sleep(ctx, delay); err != nil {
+				return
+			}
+		}
+	}()
+	return events
+}
+
+// checkEventStream fails the responses which are not an event stream
+func checkEventStream(resp *http.Response) error {
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("reqctl: event stream failed with status %d", resp.StatusCode)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+		return fmt.Errorf("reqctl: unexpected event stream content type %q", resp.Header.Get("Content-Type"))
+	}
+	return nil
+}
+
+// readEvents parses the event stream & delivers its events until it ends, recording the last event ID & the
+// reconnection delay sent by the server
+func readEvents(ctx context.Context, resp *http.Response, events chan<- Event, lastID *string,
+	reconnect *time.Duration) error {
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 4096), maxEventBytes)
+	scanner.Split(scanEventLines)
+
+	// The ID is committed once its event is dispatched, an incomplete event leaving the last event ID as is
+	id := *lastID
+	var event Event
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			// A blank line dispatches the event, unless it has no data
+			*lastID = id
+			if data.Len() > 0 {
+				event.ID, event.Data = *lastID, strings.TrimSuffix(data.String(), "\n")
+				if event.Event == "" {
+					event.Event = "message"
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			event = Event{}
+			data.Reset()
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				id = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseInt(value, 10, 64); err == nil && ms >= 0 {
+				*reconnect = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// scanEventLines splits the stream into lines ended by CRLF, LF or CR
+func scanEventLines(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		// A CR at the end of the buffer may be followed by a LF not read yet
+		if i+1 == len(data) && !atEOF {
+			return 0, nil, nil
+		}
+		if i+1 < len(data) && data[i+1] == '\n' {
+			return i + 2, data[:i], nil
+		}
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
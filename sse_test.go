@@ -0,0 +1,75 @@
+package reqctl_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestSubscribe(t *testing.T) {
+	var connections int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt64(&connections, 1) {
+		case 1:
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte(": comment\r\nretry: 2500\r\nid: 1\r\nevent: greeting\r\ndata: hello\r\ndata: world\r\n\r\n"))
+			_, _ = w.Write([]byte("id: 2\ndata: second\n\nid: 3\ndata: incomplete"))
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 3:
+			if r.Header.Get("Last-Event-ID") != "2" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+			_, _ = w.Write([]byte("data: resumed\r\r"))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	c := reqctl.Request(context.Background(), req).
+		SetExponentialRetry(time.Second, 0).
+		SetTimeout(time.Second).
+		SetClock(clock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var events []reqctl.Event
+	for event := range reqctl.Subscribe(ctx, &c) {
+		events = append(events, event)
+	}
+
+	if len(events) != 4 {
+		t.Fatalf("Expected 3 events & a failure, got %+v", events)
+	}
+	if e := events[0]; e.ID != "1" || e.Event != "greeting" || e.Data != "hello\nworld" {
+		t.Errorf("Expected the first event, got %+v", e)
+	}
+	if e := events[1]; e.ID != "2" || e.Event != "message" || e.Data != "second" {
+		t.Errorf("Expected the second event, got %+v", e)
+	}
+	if e := events[2]; e.Err == nil || e.ID != "2" {
+		t.Errorf("Expected the failed reconnection, got %+v", e)
+	}
+	if e := events[3]; e.ID != "2" || e.Data != "resumed" {
+		t.Errorf("Expected the resumed event, got %+v", e)
+	}
+
+	// The reconnection delay sent by the server is the least wait, failures backing off beyond
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	delay := 2500 * time.Millisecond
+	if !reflect.DeepEqual(clock.slept, []time.Duration{delay, delay, delay}) {
+		t.Errorf("Expected the reconnection delays, got %v", clock.slept)
+	}
+}
@@ -0,0 +1,102 @@
+package reqctl
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SetStaleWhileRevalidate serves the stale responses up to d past their freshness right away, while refreshing them
+// in the background. The stale-while-revalidate directive of a response takes precedence over d.
+func (c *Cache) SetStaleWhileRevalidate(d time.Duration) *Cache {
+	c.staleWhileRevalidate = d
+	return c
+}
+
+// SetStaleIfError serves the stale responses up to d past their freshness when the request fails, ie: with an
+// error or a 500, 502, 503 or 504 once the retries are exhausted. The stale-if-error directive of a response takes
+// precedence over d.
+func (c *Cache) SetStaleIfError(d time.Duration) *Cache {
+	c.staleIfError = d
+	return c
+}
+
+// servesStale reports whether the stale entry may be served, as per the window of the directive or else the default
+func (c *Cache) servesStale(entry CacheEntry, directive string, window time.Duration, now time.Time) bool {
+	directives := cacheControl(entry.Header)
+	if _, ok := directives["must-revalidate"]; ok {
+		return false
+	}
+	if _, ok := directives["no-cache"]; ok {
+		return false
+	}
+	if arg, ok := directives[directive]; ok {
+		if seconds, err := strconv.ParseInt(arg, 10, 64); err == nil && seconds >= 0 {
+			window = time.Duration(seconds) * time.Second
+		}
+	}
+
+	lifetime, _ := freshnessLifetime(entry.Header)
+	return entry.age(now) < lifetime+window
+}
+
+// startRefresh reports whether the refresh of the key shall start, ie: none is in progress
+func (c *Cache) startRefresh(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.refreshing[key] {
+		return false
+	}
+	c.refreshing[key] = true
+	return true
+}
+
+// doneRefresh marks the refresh of the key as completed
+func (c *Cache) doneRefresh(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.refreshing, key)
+}
+
+// revalidate refreshes the cached response of the request in the background, unless a refresh is in progress.
+// The refresh outlives the request, hence it is detached from its cancellation.
+func (c *Controller) revalidate() {
+	cache, key := c.config.cache, cacheKey(c.req)
+	if !cache.startRefresh(key) {
+		return
+	}
+
+	refresh := c.Clone()
+	refresh.ctx = detachedContext{c.ctx}
+	refresh.req = c.req.Clone(refresh.ctx)
+	refresh.revalidating = true
+	go func() {
+		defer cache.doneRefresh(key)
+		resp, _ := refresh.do(refresh.client())
+		closeBody(resp)
+	}()
+}
+
+// serverError reports whether the response denotes an unavailable origin, as per RFC 5861
+func serverError(resp *http.Response) bool {
+	if resp == nil {
+		return false
+	}
+	switch resp.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// staleResponse returns the stored response of the request if it may replace a failed outcome
+func (c *Controller) staleResponse() (*http.Response, bool) {
+	cache := c.config.cache
+	now := c.clock().Now()
+	entry, ok := cache.lookup(c.req)
+	if !ok || !cache.servesStale(entry, "stale-if-error", cache.staleIfError, now) {
+		return nil, false
+	}
+	return entry.response(c.req, now), true
+}
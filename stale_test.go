@@ -0,0 +1,93 @@
+package reqctl_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&calls, 1)
+		w.Header().Set("Cache-Control", "max-age=10, stale-while-revalidate=30")
+		_, _ = w.Write([]byte(strconv.FormatInt(n, 10)))
+	}))
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	policy := reqctl.NewPolicy().WithCache(reqctl.NewCache(nil)).WithClock(clock)
+	get := func() (string, bool) {
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		res := policy.DoResult(context.Background(), req)
+		body, err := res.String()
+		if err != nil {
+			t.Fatalf("Expected the request to succeed, got %v", err)
+		}
+		return body, res.FromCache
+	}
+
+	get()
+	clock.Sleep(context.Background(), 20*time.Second)
+	if body, cached := get(); body != "1" || !cached {
+		t.Errorf("Expected the stale response to be served right away, got %q", body)
+	}
+
+	// The stale response is served until the background refresh is stored
+	body, cached := get()
+	for deadline := time.Now().Add(time.Second); body == "1" && time.Now().Before(deadline); body, cached = get() {
+		time.Sleep(time.Millisecond)
+	}
+	if body != "2" || !cached || atomic.LoadInt64(&calls) != 2 {
+		t.Errorf("Expected the response refreshed once in the background, got %q after %d calls", body, atomic.LoadInt64(&calls))
+	}
+
+	clock.Sleep(context.Background(), time.Minute)
+	if body, cached := get(); body != "3" || cached {
+		t.Errorf("Expected a response stale beyond the window to be fetched, got %q", body)
+	}
+}
+
+func TestStaleIfError(t *testing.T) {
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&calls, 1) > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Cache-Control", "max-age=10")
+		_, _ = w.Write([]byte("cached"))
+	}))
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	policy := reqctl.NewPolicy().
+		WithCache(reqctl.NewCache(nil).SetStaleIfError(time.Minute)).
+		WithSimpleRetryWithChecker(time.Second, 2, reqctl.RetryOnStatus(503)).
+		WithClock(clock)
+	get := func() reqctl.Result {
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		return policy.DoResult(context.Background(), req)
+	}
+
+	_, _ = get().Bytes()
+	clock.Sleep(context.Background(), 30*time.Second)
+	res := get()
+	if body, err := res.String(); err != nil || body != "cached" || !res.FromCache {
+		t.Errorf("Expected the stale response once the retries failed, got %q, %v", body, err)
+	}
+	if n := atomic.LoadInt64(&calls); n != 4 {
+		t.Errorf("Expected the retries to be exhausted before serving stale, got %d calls", n)
+	}
+
+	clock.Sleep(context.Background(), time.Minute)
+	if res := get(); res.Err != nil || res.Response.StatusCode != http.StatusServiceUnavailable || res.FromCache {
+		t.Errorf("Expected the failure beyond the window, got %v", res.Err)
+	}
+}
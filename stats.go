@@ -0,0 +1,149 @@
+package reqctl
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// statsLatencySamples is the number of the latest request durations kept for the latency percentiles
+const statsLatencySamples = 1024
+
+// Stats are the cumulative counts of the requests sent via a policy, a client or a reusable controller
+type Stats struct {
+	// Requests is the number of logical requests completed
+	Requests int64 `json:"requests"`
+	// Attempts is the number of attempts completed, across retries & parallel calls
+	Attempts int64 `json:"attempts"`
+	// Retries is the number of attempts which retried a previous attempt
+	Retries int64 `json:"retries"`
+	// RetriesByReason counts the retries by why the previous attempt was retried
+	RetriesByReason map[RetryReason]int64 `json:"retries_by_reason,omitempty"`
+	// Hedges is the number of attempts belonging to parallel calls fired after the first one
+	Hedges int64 `json:"hedges"`
+	// Successes is the number of logical requests whose outcome is not deemed failed by the retry checker
+	Successes int64 `json:"successes"`
+	// Failures counts the failed logical requests by the class of their outcome
+	Failures map[RetryReason]int64 `json:"failures,omitempty"`
+	// Latency summarizes the durations of the logical requests
+	Latency LatencySummary `json:"latency"`
+}
+
+// LatencySummary summarizes durations, the percentiles being computed over the latest 1024 samples
+type LatencySummary struct {
+	Mean time.Duration `json:"mean_ns"`
+	Max  time.Duration `json:"max_ns"`
+	P50  time.Duration `json:"p50_ns"`
+	P90  time.Duration `json:"p90_ns"`
+	P99  time.Duration `json:"p99_ns"`
+}
+
+// statsCollector accumulates the stats, shared by the copies of a policy or controller
+type statsCollector struct {
+	mu      sync.Mutex
+	stats   Stats
+	total   time.Duration
+	latency *LatencyTracker
+}
+
+// newStatsCollector creates an empty collector
+func newStatsCollector() *statsCollector {
+	return &statsCollector{
+		stats:   Stats{RetriesByReason: map[RetryReason]int64{}, Failures: map[RetryReason]int64{}},
+		latency: NewLatencyTracker(statsLatencySamples),
+	}
+}
+
+// recordAttempt accounts a completed attempt
+func (s *statsCollector) recordAttempt(attempt Attempt) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Attempts++
+	if attempt.Retried() {
+		s.stats.Retries++
+		s.stats.RetriesByReason[attempt.Reason]++
+	}
+	if attempt.Hedged {
+		s.stats.Hedges++
+	}
+}
+
+// recordRequest accounts the outcome of a logical request
+func (s *statsCollector) recordRequest(resp *http.Response, err error, failed bool, elapsed time.Duration) {
+	if s == nil {
+		return
+	}
+
+	s.latency.Record("", elapsed)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Requests++
+	if failed {
+		s.stats.Failures[ClassifyRetry(resp, err)]++
+	} else {
+		s.stats.Successes++
+	}
+
+	s.total += elapsed
+	if elapsed > s.stats.Latency.Max {
+		s.stats.Latency.Max = elapsed
+	}
+}
+
+// snapshot returns a copy of the stats
+func (s *statsCollector) snapshot() Stats {
+	if s == nil {
+		return Stats{}
+	}
+
+	s.mu.Lock()
+	res := s.stats
+	res.RetriesByReason = copyReasons(s.stats.RetriesByReason)
+	res.Failures = copyReasons(s.stats.Failures)
+	if res.Requests > 0 {
+		res.Latency.Mean = s.total / time.Duration(res.Requests)
+	}
+	s.mu.Unlock()
+
+	res.Latency.P50 = s.percentile(50)
+	res.Latency.P90 = s.percentile(90)
+	res.Latency.P99 = s.percentile(99)
+	return res
+}
+
+// copyReasons returns a copy of the counts by reason
+func copyReasons(counts map[RetryReason]int64) map[RetryReason]int64 {
+	res := make(map[RetryReason]int64, len(counts))
+	for reason, n := range counts {
+		res[reason] = n
+	}
+	return res
+}
+
+// percentile returns the percentile of the latest durations, 0 until there are enough samples
+func (s *statsCollector) percentile(p float64) time.Duration {
+	d, _ := s.latency.Percentile("", p)
+	return d
+}
+
+// Stats returns the cumulative stats of the requests sent via the controller & the copies sharing its lineage,
+// eg: the controllers created by the same policy
+func (c Controller) Stats() Stats {
+	return c.config.stats.snapshot()
+}
+
+// Stats returns the cumulative stats of the requests sent via the policy & the policies derived from it,
+// refer Controller.Stats
+func (p Policy) Stats() Stats {
+	return p.template.Stats()
+}
+
+// Stats returns the cumulative stats of the requests sent via the client
+func (c *Client) Stats() Stats {
+	return c.policy.Stats()
+}
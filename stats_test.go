@@ -0,0 +1,50 @@
+package reqctl_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestStats(t *testing.T) {
+	calls := 0
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			status := 503
+			if r.URL.Path == "/ok" && calls%2 == 0 {
+				status = 200
+			}
+			return &http.Response{StatusCode: status, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	c := reqctl.NewClient(client, reqctl.NewPolicy().
+		WithSimpleRetryWithChecker(0, 1, reqctl.RetryOnStatus(503)))
+	for _, path := range []string{"/ok", "/ok", "/down"} {
+		resp, err := c.Get(context.Background(), "http://localhost"+path)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}
+
+	stats := c.Stats()
+	if stats.Requests != 3 || stats.Attempts != 6 || stats.Retries != 3 || stats.Hedges != 0 {
+		t.Errorf("Expected 3 requests with 6 attempts, got %+v", stats)
+	}
+	if n := stats.RetriesByReason[reqctl.ReasonServerError]; n != 3 || len(stats.RetriesByReason) != 1 {
+		t.Errorf("Expected 3 retries of server errors, got %v", stats.RetriesByReason)
+	}
+	if stats.Successes != 2 || stats.Failures[reqctl.ReasonServerError] != 1 {
+		t.Errorf("Expected 2 successes & a server error, got %+v", stats)
+	}
+	if stats.Latency.Max <= 0 || stats.Latency.Mean <= 0 || stats.Latency.Mean > stats.Latency.Max {
+		t.Errorf("Expected the latency summary, got %+v", stats.Latency)
+	}
+
+	if other := reqctl.NewPolicy().Stats(); other.Requests != 0 {
+		t.Errorf("Expected separate policies not to share stats, got %+v", other)
+	}
+}
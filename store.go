@@ -0,0 +1,90 @@
+package reqctl
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// StateStore persists the shared resilience state ( circuit breakers, retry budgets & endpoint health ) as counters.
+// The in-memory store is the default, external stores like Redis can be plugged in by implementing this interface
+// over atomic increments with expiry ( INCRBY & PEXPIRE ), so that a fleet of processes shares the same view.
+type StateStore interface {
+	// Add atomically adds delta to the counter under key & returns the new value.
+	// A positive ttl sets the expiry of a counter which did not exist before.
+	Add(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error)
+	// Get returns the value of the counter under key, 0 if it does not exist or has expired
+	Get(ctx context.Context, key string) (int64, error)
+	// Set overwrites the counter under key, a positive ttl sets its expiry
+	Set(ctx context.Context, key string, value int64, ttl time.Duration) error
+}
+
+// memoryEntry is a counter held by the memory store
+type memoryEntry struct {
+	value    int64
+	expireAt time.Time
+}
+
+// MemoryStore is a StateStore local to the process
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+// NewMemoryStore creates an empty in-memory state store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: map[string]memoryEntry{},
+	}
+}
+
+// defaultStore is used by the stateful components when no store is provided
+var defaultStore = NewMemoryStore()
+
+// Add atomically adds delta to the counter under key
+func (m *MemoryStore) Add(_ context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.load(key)
+	if !ok && ttl > 0 {
+		entry.expireAt = time.Now().Add(ttl)
+	}
+
+	entry.value += delta
+	m.entries[key] = entry
+	return entry.value, nil
+}
+
+// Get returns the value of the counter under key
+func (m *MemoryStore) Get(_ context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, _ := m.load(key)
+	return entry.value, nil
+}
+
+// Set overwrites the counter under key
+func (m *MemoryStore) Set(_ context.Context, key string, value int64, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := memoryEntry{value: value}
+	if ttl > 0 {
+		entry.expireAt = time.Now().Add(ttl)
+	}
+
+	m.entries[key] = entry
+	return nil
+}
+
+// load returns the live entry under key, dropping it if expired. Must be called with the lock held.
+func (m *MemoryStore) load(key string) (memoryEntry, bool) {
+	entry, ok := m.entries[key]
+	if ok && !entry.expireAt.IsZero() && !time.Now().Before(entry.expireAt) {
+		delete(m.entries, key)
+		return memoryEntry{}, false
+	}
+	return entry, ok
+}
@@ -0,0 +1,33 @@
+package reqctl_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := reqctl.NewMemoryStore()
+
+	if v, _ := store.Add(ctx, "counter", 2, 20*time.Millisecond); v != 2 {
+		t.Errorf("Expected counter 2, got %d", v)
+	}
+
+	if v, _ := store.Add(ctx, "counter", 3, time.Hour); v != 5 {
+		t.Errorf("Expected counter 5, got %d", v)
+	}
+
+	// Expiry is set by the first Add only
+	time.Sleep(30 * time.Millisecond)
+	if v, _ := store.Get(ctx, "counter"); v != 0 {
+		t.Errorf("Expected expired counter 0, got %d", v)
+	}
+
+	_ = store.Set(ctx, "counter", 7, 0)
+	if v, _ := store.Get(ctx, "counter"); v != 7 {
+		t.Errorf("Expected counter 7, got %d", v)
+	}
+}
@@ -0,0 +1,18 @@
+package reqctl
+
+import "errors"
+
+// ErrStreamingParallelCall is returned when parallel calls are configured for a streaming response,
+// as racing two streams would make the consumed data depend on which call wins.
+var ErrStreamingParallelCall = errors.New("reqctl: parallel calls are not supported for streaming responses")
+
+// SetStreamingResponse marks the response as a stream consumed incrementally by the caller, eg: chunked transfers.
+// In streaming mode
+//   - the timeout bounds each attempt only until the response headers are obtained,
+//   - retries never happen once the body is handed over to the caller, the retry checker sees only the headers,
+//   - the response body is never buffered or read by the controller,
+//   - parallel calls are rejected with ErrStreamingParallelCall.
+func (c Controller) SetStreamingResponse(streaming bool) Controller {
+	c.config.streaming = streaming
+	return c
+}
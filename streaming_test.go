@@ -0,0 +1,66 @@
+package reqctl_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestStreamingResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("first,"))
+		w.(http.Flusher).Flush()
+
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte("second"))
+	}))
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	// The stream outlives the timeout, which only bounds the response headers
+	resp, err := reqctl.Request(request.Context(), request).
+		SetTimeout(50 * time.Millisecond).
+		SetStreamingResponse(true).
+		Do()
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Errorf("Error reading stream: %v", err)
+	}
+
+	if string(body) != "first,second" {
+		t.Errorf("Expected body %q, got %q", "first,second", body)
+	}
+}
+
+func TestStreamingParallelCall(t *testing.T) {
+	request, err := http.NewRequest("GET", "http://localhost", nil)
+	if err != nil {
+		t.Errorf("Error creating request: %v", err)
+		return
+	}
+
+	_, err = reqctl.Request(request.Context(), request).
+		SetStreamingResponse(true).
+		SetParallelCallWithDelay(10 * time.Millisecond).
+		Do()
+	if !errors.Is(err, reqctl.ErrStreamingParallelCall) {
+		t.Errorf("Expected reqctl.ErrStreamingParallelCall, got %v", err)
+	}
+}
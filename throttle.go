@@ -0,0 +1,145 @@
+package reqctl
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Throttle limits the bandwidth of the bodies read through it, as a token bucket of bytes. It is safe to share,
+// the controllers sharing it splitting the bandwidth among their transfers.
+type Throttle struct {
+	rate  float64
+	burst int64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewThrottle creates a throttle of bytesPerSecond, bursting up to a tenth of a second of transfer.
+// A non positive bytesPerSecond is unlimited, the controllers skipping the throttle altogether.
+func NewThrottle(bytesPerSecond int64) *Throttle {
+	burst := bytesPerSecond / 10
+	if burst < 1 {
+		burst = 1
+	}
+	return &Throttle{
+		rate:   float64(bytesPerSecond),
+		burst:  burst,
+		tokens: float64(burst),
+	}
+}
+
+// unlimited reports whether the throttle has no bandwidth limit
+func (t *Throttle) unlimited() bool {
+	return t.rate <= 0
+}
+
+// SetBurst sets the bytes transferred at once when the bandwidth is unused, which also bounds every read.
+// Non positive bursts are ignored.
+func (t *Throttle) SetBurst(n int64) *Throttle {
+	if n <= 0 {
+		return t
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.burst, t.tokens = n, float64(n)
+	return t
+}
+
+// reserve takes n bytes from the bucket at now, returning how long to wait for them to be within the bandwidth
+func (t *Throttle) reserve(now time.Time, n int) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// The bucket refills from its first use, as per the clock of the controller using it
+	if t.last.IsZero() {
+		t.last = now
+	}
+	if now.After(t.last) {
+		t.tokens += now.Sub(t.last).Seconds() * t.rate
+		if t.tokens > float64(t.burst) {
+			t.tokens = float64(t.burst)
+		}
+		t.last = now
+	}
+
+	t.tokens -= float64(n)
+	if t.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-t.tokens / t.rate * float64(time.Second))
+}
+
+// throttledBody waits after each read for the bytes read to be within the bandwidth of the throttles
+type throttledBody struct {
+	io.ReadCloser
+	ctx       context.Context
+	clock     Clock
+	throttles []*Throttle
+}
+
+// Read reads up to the smallest burst of the throttles, then waits for them
+func (b *throttledBody) Read(p []byte) (int, error) {
+	for _, t := range b.throttles {
+		if int64(len(p)) > t.burst {
+			p = p[:t.burst]
+		}
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if n == 0 {
+		return n, err
+	}
+	for _, t := range b.throttles {
+		if werr := b.clock.Sleep(b.ctx, t.reserve(b.clock.Now(), n)); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// SetThrottle limits the bandwidth of the request & response bodies of the request to the throttle, shared by all
+// its attempts & the controllers configured with the same throttle.
+func (c Controller) SetThrottle(throttle *Throttle) Controller {
+	c.config.throttle = throttle
+	return c
+}
+
+// SetAttemptBandwidth limits the bandwidth of the request & response bodies of each attempt alone to bytesPerSecond,
+// eg: so that parallel calls do not slow each other down. 0 disables the limit.
+func (c Controller) SetAttemptBandwidth(bytesPerSecond int64) Controller {
+	c.config.attemptBandwidth = bytesPerSecond
+	return c
+}
+
+// attemptThrottles returns the throttles limiting an attempt, the attempt throttle being created afresh
+func (c *Controller) attemptThrottles() []*Throttle {
+	var throttles []*Throttle
+	if t := c.config.throttle; t != nil && !t.unlimited() {
+		throttles = append(throttles, t)
+	}
+	if n := c.config.attemptBandwidth; n > 0 {
+		throttles = append(throttles, NewThrottle(n))
+	}
+	return throttles
+}
+
+// throttleRequest limits the bandwidth of the request body of the attempt
+func (c *Controller) throttleRequest(req *http.Request) {
+	if throttles := c.attemptThrottles(); len(throttles) > 0 && req.Body != nil && req.Body != http.NoBody {
+		req.Body = &throttledBody{ReadCloser: req.Body, ctx: req.Context(), clock: c.clock(), throttles: throttles}
+	}
+}
+
+// throttleResponse limits the bandwidth of the response body of the request. It applies once the attempts are
+// settled, so that the discarded responses are drained without delay.
+func (c *Controller) throttleResponse(resp *http.Response) {
+	if throttles := c.attemptThrottles(); len(throttles) > 0 && resp != nil && resp.Body != nil {
+		resp.Body = &throttledBody{ReadCloser: resp.Body, ctx: c.ctx, clock: c.clock(), throttles: throttles}
+	}
+}
@@ -0,0 +1,109 @@
+package reqctl_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestAttemptBandwidth(t *testing.T) {
+	body := bytes.Repeat([]byte("x"), 2000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	// The first 1000 bytes are within the burst, the next 1000 take 100ms at 10KB/s
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	start := time.Now()
+	data, err := reqctl.Request(context.Background(), req).SetAttemptBandwidth(10_000).DoResult().Bytes()
+	if err != nil || !bytes.Equal(data, body) {
+		t.Fatalf("Expected the throttled body in full, got %d bytes, %v", len(data), err)
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("Expected the body to be throttled, read in %s", elapsed)
+	}
+}
+
+func TestThrottleUpload(t *testing.T) {
+	var received int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		received = len(data)
+	}))
+	defer server.Close()
+
+	throttle := reqctl.NewThrottle(20_000).SetBurst(1000)
+	start := time.Now()
+	resp, err := reqctl.Post(context.Background(), server.URL).
+		WithBody("application/octet-stream", bytes.NewReader(make([]byte, 3000))).
+		Policy(reqctl.NewPolicy().WithThrottle(throttle)).
+		Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if received != 3000 {
+		t.Errorf("Expected the body to be uploaded in full, got %d bytes", received)
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("Expected the upload to be throttled, sent in %s", elapsed)
+	}
+}
+
+func TestThrottleContextDone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(make([]byte, 1000))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	_, err := reqctl.Request(ctx, req).SetAttemptBandwidth(100).DoResult().Bytes()
+	if err == nil {
+		t.Errorf("Expected the throttled read to stop with the context")
+	}
+}
+
+func TestThrottleClock(t *testing.T) {
+	body := bytes.Repeat([]byte("x"), 2000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	// The waits follow the clock of the controller, the 1000 bytes beyond the burst taking 100ms at 10KB/s
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	data, err := reqctl.Request(context.Background(), req).
+		SetAttemptBandwidth(10_000).
+		SetClock(clock).
+		DoResult().
+		Bytes()
+	if err != nil || !bytes.Equal(data, body) {
+		t.Fatalf("Expected the throttled body in full, got %d bytes, %v", len(data), err)
+	}
+
+	var waited time.Duration
+	for _, d := range clock.slept {
+		waited += d
+	}
+	if waited < 90*time.Millisecond || waited > 110*time.Millisecond {
+		t.Errorf("Expected 100ms of waits on the clock, got %s", waited)
+	}
+
+	// Unlimited throttles are skipped
+	req, _ = http.NewRequest("GET", server.URL, nil)
+	data, err = reqctl.Request(context.Background(), req).SetThrottle(reqctl.NewThrottle(0)).DoResult().Bytes()
+	if err != nil || !bytes.Equal(data, body) {
+		t.Errorf("Expected the body in full through an unlimited throttle, got %d bytes, %v", len(data), err)
+	}
+}
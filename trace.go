@@ -0,0 +1,108 @@
+package reqctl
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timings break down the time taken by an attempt, letting a slow server be told apart from a slow connection setup.
+// The phases skipped by the attempt, eg: on reused connections, are 0.
+type Timings struct {
+	// DNS is the time taken by the host lookup
+	DNS time.Duration
+	// Connect is the time taken to establish the TCP connection
+	Connect time.Duration
+	// TLS is the time taken by the TLS handshake
+	TLS time.Duration
+	// TTFB is the time from the start of the attempt until the first byte of the response
+	TTFB time.Duration
+	// ConnReused reports whether the attempt was sent on a pooled connection
+	ConnReused bool
+}
+
+// attemptTracer captures the timings of an attempt, its callbacks may be invoked from the dialing goroutines
+type attemptTracer struct {
+	start time.Time
+
+	mu                         sync.Mutex
+	timings                    Timings
+	dnsStart, connStart, tlsAt time.Time
+}
+
+// SetTraceTimings captures the DNS, connect, TLS & time to first byte timings of every attempt,
+// available via the attempt records & hooks
+func (c Controller) SetTraceTimings(enabled bool) Controller {
+	c.config.traceTimings = enabled
+	return c
+}
+
+// traceAttempt attaches a tracer to the request of the attempt, if enabled
+func (c *Controller) traceAttempt(req *http.Request) (*http.Request, *attemptTracer) {
+	if !c.config.traceTimings {
+		return req, nil
+	}
+
+	t := &attemptTracer{start: time.Now()}
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.timings.ConnReused = info.Reused
+			t.mu.Unlock()
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			t.dnsStart = time.Now()
+			t.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			t.timings.DNS = time.Since(t.dnsStart)
+			t.mu.Unlock()
+		},
+		ConnectStart: func(network, addr string) {
+			t.mu.Lock()
+			if t.connStart.IsZero() {
+				t.connStart = time.Now()
+			}
+			t.mu.Unlock()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.mu.Lock()
+			if err == nil && t.timings.Connect == 0 {
+				t.timings.Connect = time.Since(t.connStart)
+			}
+			t.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			t.tlsAt = time.Now()
+			t.mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			t.timings.TLS = time.Since(t.tlsAt)
+			t.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			t.timings.TTFB = time.Since(t.start)
+			t.mu.Unlock()
+		},
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), t
+}
+
+// result returns the timings captured, nil tracers capture nothing
+func (t *attemptTracer) result() *Timings {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	timings := t.timings
+	return &timings
+}
@@ -0,0 +1,48 @@
+package reqctl_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestTraceTimings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var timings []*reqctl.Timings
+	policy := reqctl.NewPolicy().
+		WithClient(server.Client()).
+		WithTraceTimings(true).
+		WithHooks(reqctl.Hooks{
+			OnAttemptDone: func(rec reqctl.AttemptRecord) {
+				timings = append(timings, rec.Timings)
+			},
+		})
+
+	for i := 0; i < 2; i++ {
+		request, _ := http.NewRequest("GET", server.URL, nil)
+		resp, err := policy.Do(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Obtained error: %v", err)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	if len(timings) != 2 || timings[0] == nil || timings[1] == nil {
+		t.Fatalf("Expected the timings of 2 attempts, got %v", timings)
+	}
+	if timings[0].ConnReused || timings[0].Connect <= 0 || timings[0].TTFB <= 0 {
+		t.Errorf("Expected the first attempt to dial a connection, got %+v", timings[0])
+	}
+	if !timings[1].ConnReused || timings[1].Connect != 0 {
+		t.Errorf("Expected the second attempt to reuse the connection, got %+v", timings[1])
+	}
+}
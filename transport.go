@@ -0,0 +1,82 @@
+package reqctl
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// policyCtxKey is the context key under which the per request policy override is stored
+type policyCtxKey struct{}
+
+// WithPolicyOverride attaches a policy to the context, which the transports created via NewTransport
+// apply to the request instead of their own policy.
+func WithPolicyOverride(ctx context.Context, policy Policy) context.Context {
+	return context.WithValue(ctx, policyCtxKey{}, policy)
+}
+
+// Transport is an http.RoundTripper applying a policy to every request, so that clients which cannot be
+// replaced, eg: third party SDKs, get retries & parallel calls transparently.
+type Transport struct {
+	policy Policy
+	client *http.Client
+}
+
+// NewTransport creates a transport sending the attempts via next as per the policy.
+// A nil next uses http.DefaultTransport. The client configured in the policy is ignored.
+func NewTransport(policy Policy, next http.RoundTripper) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &Transport{
+		policy: policy,
+		client: &http.Client{
+			Transport: next,
+			// Redirects are followed by the client wrapping the transport
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// RoundTrip executes the request as per the policy of the request context, or else the transport policy.
+// The request body is closed on failure, even if the policy rejected the request before sending it.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	policy := t.policy
+	if override, ok := req.Context().Value(policyCtxKey{}).(Policy); ok {
+		policy = override
+	}
+
+	var body *onceCloser
+	if req.Body != nil && req.Body != http.NoBody {
+		body = &onceCloser{ReadCloser: req.Body}
+		req = req.Clone(req.Context())
+		req.Body = body
+	}
+
+	resp, err := policy.Request(req.Context(), req).DoWithClient(t.client)
+	if err != nil && body != nil {
+		body.Close()
+	}
+	// The client wrapping the transport wraps the error in a *url.Error, as the inner client already did
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+	return resp, err
+}
+
+// onceCloser closes the body at most once, whether closed by the inner client or on failure
+type onceCloser struct {
+	io.ReadCloser
+	once sync.Once
+	err  error
+}
+
+func (b *onceCloser) Close() error {
+	b.once.Do(func() { b.err = b.ReadCloser.Close() })
+	return b.err
+}
@@ -0,0 +1,87 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestTransport(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	checker := func(resp *http.Response, err error) bool {
+		return err != nil || resp.StatusCode >= 500
+	}
+
+	policy := reqctl.NewPolicy().WithSimpleRetryWithChecker(time.Millisecond, 2, checker)
+	client := &http.Client{Transport: reqctl.NewTransport(policy, nil)}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+	resp.Body.Close()
+
+	if n := atomic.SwapInt32(&calls, 0); n != 3 {
+		t.Errorf("Expected 3 calls, got %d", n)
+	}
+
+	// The policy of the request context overrides the transport policy
+	ctx := reqctl.WithPolicyOverride(context.Background(), reqctl.NewPolicy())
+	request, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	resp, err = client.Do(request)
+	if err != nil {
+		t.Errorf("Obtained error: %v", err)
+		return
+	}
+	resp.Body.Close()
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Expected a single call with the override, got %d", n)
+	}
+}
+
+func TestTransportError(t *testing.T) {
+	refused := errors.New("connection refused")
+	next := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, refused
+	})
+	client := &http.Client{Transport: reqctl.NewTransport(reqctl.NewPolicy(), next)}
+
+	// The error is wrapped in a single *url.Error, as by any transport
+	_, err := client.Get("http://localhost")
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) || urlErr.Err != refused {
+		t.Errorf("Expected the transport error wrapped once, got %v", err)
+	}
+}
+
+func TestTransportClosesBody(t *testing.T) {
+	transport := reqctl.NewTransport(reqctl.NewPolicy().WithSimpleRetry(time.Millisecond, 2), nil)
+
+	// The policy rejects the body which cannot be replayed, without sending it
+	body := &trackedBody{Reader: strings.NewReader("payload")}
+	req, _ := http.NewRequest("POST", "http://localhost", nil)
+	req.Body = body
+	req.Header.Set("Idempotency-Key", "key")
+	if _, err := transport.RoundTrip(req); !errors.Is(err, reqctl.ErrBodyNotReplayable) {
+		t.Errorf("Expected reqctl.ErrBodyNotReplayable, got %v", err)
+	}
+	if atomic.LoadInt32(&body.closed) != 1 {
+		t.Errorf("Expected the request body to be closed on failure")
+	}
+}
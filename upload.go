@@ -0,0 +1,168 @@
+package reqctl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// TusVersion is the version of the tus resumable upload protocol spoken by the uploads
+const TusVersion = "1.0.0"
+
+// ErrUploadOffset is returned when the server reports an upload offset inconsistent with the content
+var ErrUploadOffset = errors.New("reqctl: invalid upload offset")
+
+// uploadController returns a copy of the controller sending a protocol request of the method to the upload URL,
+// with the headers of the original request but without its body
+func (c *Controller) uploadController(method string, upload *url.URL) *Controller {
+	up := *c
+	up.req = c.req.Clone(c.ctx)
+	up.req.Method, up.req.URL, up.req.Host = method, upload, ""
+	up.req.Body, up.req.GetBody, up.req.ContentLength = nil, nil, 0
+	up.req.Header.Del("Content-Type")
+	up.req.Header.Set("Tus-Resumable", TusVersion)
+	up.config.progress = nil
+	return &up
+}
+
+// uploadOffset parses the Upload-Offset header of the response
+func uploadOffset(resp *http.Response, size int64) (int64, error) {
+	offset, err := strconv.ParseInt(resp.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 || offset > size {
+		return 0, fmt.Errorf("%w: %q of %d bytes", ErrUploadOffset, resp.Header.Get("Upload-Offset"), size)
+	}
+	return offset, nil
+}
+
+// createUpload creates the upload of size bytes, returning its URL
+func createUpload(c *Controller, size int64) (*url.URL, error) {
+	up := c.uploadController(c.req.Method, c.req.URL)
+	up.req.Header.Set("Upload-Length", strconv.FormatInt(size, 10))
+
+	resp, err := up.Do()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStatus(resp, []int{http.StatusCreated}); err != nil {
+		return nil, err
+	}
+	closeBody(resp)
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return nil, errors.New("reqctl: upload created without a location")
+	}
+	return c.req.URL.Parse(location)
+}
+
+// probeUpload returns the offset of the upload as known by the server
+func probeUpload(c *Controller, upload *url.URL, size int64) (int64, error) {
+	resp, err := c.uploadController(http.MethodHead, upload).Do()
+	if err != nil {
+		return 0, err
+	}
+	if err := checkStatus(resp, []int{http.StatusOK, http.StatusNoContent}); err != nil {
+		return 0, err
+	}
+	closeBody(resp)
+	return uploadOffset(resp, size)
+}
+
+// sendChunk sends the content from the offset, up to chunkSize bytes, returning the offset reached.
+// A failed chunk is not retried as is, since the server may have stored part of it.
+func sendChunk(c *Controller, upload *url.URL, r io.ReaderAt, offset, size, chunkSize int64) (int64, error) {
+	n := size - offset
+	if chunkSize > 0 && n > chunkSize {
+		n = chunkSize
+	}
+
+	up := c.uploadController(http.MethodPatch, upload)
+	up.config.retryCfg = &retryConfig{RetryType: noRetry}
+	up.config.asyncCfg = nil
+	up.req.Header.Set("Content-Type", "application/offset+octet-stream")
+	up.req.Header.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	up.req.ContentLength = n
+	up.req.GetBody = func() (io.ReadCloser, error) {
+		var body io.ReadCloser = io.NopCloser(io.NewSectionReader(r, offset, n))
+		if c.config.progress != nil {
+			// The progress is reported across the whole content, rather than per chunk
+			body = &progressBody{ReadCloser: body, sent: offset, total: size, fn: c.config.progress}
+		}
+		return body, nil
+	}
+	up.req.Body, _ = up.req.GetBody()
+
+	resp, err := up.Do()
+	if err != nil {
+		return offset, err
+	}
+	if err := checkStatus(resp, []int{http.StatusNoContent}); err != nil {
+		return offset, err
+	}
+	closeBody(resp)
+
+	next, err := uploadOffset(resp, size)
+	if err == nil && next <= offset {
+		err = fmt.Errorf("%w: %d after sending from %d", ErrUploadOffset, next, offset)
+	}
+	if err != nil {
+		return offset, err
+	}
+	return next, nil
+}
+
+// UploadResumable uploads size bytes of r as per the tus resumable upload protocol, the request of the controller
+// creating the upload, eg: a POST to the creation endpoint of the server. It returns the URL of the upload, along
+// with the error should the upload fail once created, so that it can be continued by ResumeUpload. Refer
+// ResumeUpload for the sending of the content.
+func UploadResumable(c *Controller, r io.ReaderAt, size, chunkSize int64) (*url.URL, error) {
+	upload, err := createUpload(c, size)
+	if err != nil {
+		return nil, err
+	}
+	return upload, resumeUpload(c, upload, r, size, chunkSize, 0, false)
+}
+
+// ResumeUpload continues the tus upload of size bytes of r to the upload URL from the offset known by the server.
+// The content is sent in PATCH requests of up to chunkSize bytes, in a single one if not positive. A failed
+// request is resumed from the offset the server reached, up to the retry count of the controller & after its
+// backoff, rather than sending the content all over again. The headers of the controller request are sent along,
+// its upload progress being reported across the whole content.
+func ResumeUpload(c *Controller, upload *url.URL, r io.ReaderAt, size, chunkSize int64) error {
+	return resumeUpload(c, upload, r, size, chunkSize, 0, true)
+}
+
+// resumeUpload sends the content from the offset, probing the server offset first if required
+func resumeUpload(c *Controller, upload *url.URL, r io.ReaderAt, size, chunkSize, offset int64, probe bool) error {
+	var backoff time.Duration
+	for resume := 0; ; {
+		var err error
+		if probe {
+			offset, err = probeUpload(c, upload, size)
+		}
+		if err == nil && offset >= size {
+			return nil
+		}
+		if err == nil {
+			var next int64
+			if next, err = sendChunk(c, upload, r, offset, size, chunkSize); err == nil {
+				offset, probe = next, false
+				continue
+			}
+		}
+
+		if resume >= c.config.retryCfg.MaxCount || c.ctx.Err() != nil {
+			return err
+		}
+		backoff = c.backoff(resume, backoff)
+		resume++
+		if err := c.clock().Sleep(c.ctx, backoff); err != nil {
+			return err
+		}
+		probe = true
+	}
+}
@@ -0,0 +1,99 @@
+package reqctl_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+// tusServer stores a single upload, the first PATCH being cut once half of its body is stored
+type tusServer struct {
+	mu      sync.Mutex
+	data    []byte
+	patches int
+}
+
+func (s *tusServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Header.Get("Tus-Resumable") != reqctl.TusVersion {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	switch r.Method {
+	case http.MethodPost:
+		w.Header().Set("Location", "/files/1")
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodHead:
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(s.data)))
+	case http.MethodPatch:
+		if r.Header.Get("Upload-Offset") != strconv.Itoa(len(s.data)) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if s.patches++; s.patches == 1 {
+			s.data = append(s.data, body[:len(body)/2]...)
+			panic(http.ErrAbortHandler)
+		}
+		s.data = append(s.data, body...)
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(s.data)))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestUploadResumable(t *testing.T) {
+	tus := &tusServer{}
+	server := httptest.NewServer(tus)
+	defer server.Close()
+
+	content := bytes.Repeat([]byte("0123456789"), 10_000)
+	var last int64
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/files", nil)
+	c := reqctl.Request(context.Background(), req).
+		SetSimpleRetry(time.Millisecond, 2).
+		SetUploadProgress(func(sent, total int64) {
+			if total != int64(len(content)) {
+				t.Errorf("Expected the total to be the content length, got %d", total)
+			}
+			last = sent
+		})
+
+	upload, err := reqctl.UploadResumable(&c, bytes.NewReader(content), int64(len(content)), 30_000)
+	if err != nil {
+		t.Fatalf("Expected the upload to resume, got %v", err)
+	}
+	if upload.String() != server.URL+"/files/1" {
+		t.Errorf("Expected the upload URL to be resolved, got %s", upload)
+	}
+	if !bytes.Equal(tus.data, content) {
+		t.Errorf("Expected the content to be uploaded once, got %d bytes", len(tus.data))
+	}
+	if last != int64(len(content)) {
+		t.Errorf("Expected the progress to reach the total, got %d", last)
+	}
+}
+
+func TestResumeUploadInvalidOffset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Upload-Offset", "500")
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL, nil)
+	c := reqctl.Request(context.Background(), req)
+	upload, _ := req.URL.Parse("/files/1")
+	if err := reqctl.ResumeUpload(c, upload, bytes.NewReader(nil), 100, 0); !errors.Is(err, reqctl.ErrUploadOffset) {
+		t.Errorf("Expected the offset beyond the content to be rejected, got %v", err)
+	}
+}
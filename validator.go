@@ -0,0 +1,76 @@
+package reqctl
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+// ValidatorFunc rejects an unacceptable response with an error
+type ValidatorFunc func(resp *http.Response) error
+
+// ValidationError is returned when the response of the request is rejected by the validator
+type ValidationError struct {
+	StatusCode int
+	// Err returned by the validator
+	Err error
+}
+
+// Error describes the rejected response
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("reqctl: invalid response with status %d: %v", e.StatusCode, e.Err)
+}
+
+// Unwrap returns the error of the validator
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// SetValidator validates the response once the attempts are settled, unlike the retry checker it never leads to
+// a retry. A rejected response is closed & replaced by a *ValidationError.
+func (c Controller) SetValidator(validator ValidatorFunc) Controller {
+	c.config.validator = validator
+	return c
+}
+
+// validate applies the validator to the outcome of the request
+func (c *Controller) validate(resp *http.Response, err error) (*http.Response, error) {
+	if c.config.validator == nil || err != nil || resp == nil {
+		return resp, err
+	}
+
+	if verr := c.config.validator(resp); verr != nil {
+		closeBody(resp)
+		return nil, &ValidationError{StatusCode: resp.StatusCode, Err: verr}
+	}
+	return resp, nil
+}
+
+// ExpectStatus returns a validator accepting only the status codes
+func ExpectStatus(codes ...int) ValidatorFunc {
+	set := make(map[int]bool, len(codes))
+	for _, code := range codes {
+		set[code] = true
+	}
+
+	return func(resp *http.Response) error {
+		if !set[resp.StatusCode] {
+			return fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+// ExpectContentType returns a validator accepting only the media types, irrespective of their parameters
+func ExpectContentType(mediaTypes ...string) ValidatorFunc {
+	return func(resp *http.Response) error {
+		contentType := resp.Header.Get("Content-Type")
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		for _, expected := range mediaTypes {
+			if mediaType == expected {
+				return nil
+			}
+		}
+		return fmt.Errorf("unexpected content type %q", contentType)
+	}
+}
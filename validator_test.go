@@ -0,0 +1,47 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+	"github.com/RohanPoojary/reqctl/reqctltest"
+)
+
+func TestValidator(t *testing.T) {
+	server := reqctltest.NewServer(reqctltest.Step{
+		Status: http.StatusNotFound,
+		Header: http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+	})
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	_, err := reqctl.Request(context.Background(), req).
+		SetSimpleRetryWithChecker(time.Millisecond, 3, reqctl.RetryOnStatus(503)).
+		SetValidator(reqctl.ExpectStatus(http.StatusOK)).
+		Do()
+
+	var validationErr *reqctl.ValidationError
+	if !errors.As(err, &validationErr) || validationErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected a validation error, got %v", err)
+	}
+	server.AssertAttempts(t, 1)
+
+	resp, err := reqctl.Request(context.Background(), req).
+		SetValidator(reqctl.ExpectContentType("application/json")).
+		Do()
+	if err != nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected the content type to be accepted, got %v", err)
+	} else {
+		resp.Body.Close()
+	}
+
+	if _, err := reqctl.Request(context.Background(), req).
+		SetValidator(reqctl.ExpectContentType("text/xml")).
+		Do(); err == nil {
+		t.Errorf("Expected the content type to be rejected")
+	}
+}
@@ -0,0 +1,138 @@
+package reqctl
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrTruncatedBody is returned when the response body ends before its Content-Length
+	ErrTruncatedBody = errors.New("reqctl: truncated response body")
+	// ErrDigestMismatch is returned when the response body does not match its digest header
+	ErrDigestMismatch = errors.New("reqctl: response body digest mismatch")
+)
+
+// digestAlgorithms are the supported algorithms of the Digest & Content-Digest headers, by their lowercase name
+var digestAlgorithms = map[string]func() hash.Hash{
+	"md5":     md5.New,
+	"sha-256": sha256.New,
+	"sha-512": sha512.New,
+}
+
+// SetVerifyBody verifies that the response body matches its Content-Length & its digest, as per the Content-Digest,
+// Digest or Content-MD5 header if any. Mismatches fail the read completing the body with ErrTruncatedBody or
+// ErrDigestMismatch, which are re-run along with the other body failures, refer Controller.SetBodyRetry.
+func (c Controller) SetVerifyBody(verify bool) Controller {
+	c.config.verifyBody = verify
+	return c
+}
+
+// verifiedBody checks the length & the digest of the body once it is read until EOF
+type verifiedBody struct {
+	io.ReadCloser
+	expected  int64
+	read      int64
+	algorithm string
+	hash      hash.Hash
+	digest    []byte
+	err       error
+}
+
+// Read reads the body, failing the read reaching EOF if the body does not match its headers
+func (b *verifiedBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if b.hash != nil {
+		b.hash.Write(p[:n])
+	}
+
+	if err == io.EOF {
+		if b.expected >= 0 && b.read != b.expected {
+			b.err = fmt.Errorf("%w: read %d of %d bytes", ErrTruncatedBody, b.read, b.expected)
+		} else if b.hash != nil && !bytes.Equal(b.hash.Sum(nil), b.digest) {
+			b.err = fmt.Errorf("%w: %s", ErrDigestMismatch, b.algorithm)
+		}
+		if b.err != nil {
+			return n, b.err
+		}
+	}
+	return n, err
+}
+
+// verifyBody wraps the response body to verify it while it is read
+func (c *Controller) verifyBody(resp *http.Response) {
+	if !c.config.verifyBody || resp == nil || resp.Body == nil || resp.Body == http.NoBody {
+		return
+	}
+
+	body := &verifiedBody{ReadCloser: resp.Body, expected: resp.ContentLength}
+	if !resp.Uncompressed {
+		// The digest covers the encoded body, which is lost once transparently decompressed
+		body.algorithm, body.hash, body.digest = parseDigest(resp.Header)
+	}
+	resp.Body = body
+}
+
+// parseDigest returns the first supported digest of the headers, its algorithm & the hash to compute it
+func parseDigest(header http.Header) (string, hash.Hash, []byte) {
+	// Content-Digest values are byte sequences, eg: sha-256=:base64:
+	for _, value := range strings.Split(header.Get("Content-Digest"), ",") {
+		if algorithm, digest, ok := splitDigest(strings.Trim(value, " "), ":"); ok {
+			return algorithm, digestAlgorithms[algorithm](), digest
+		}
+	}
+
+	// Digest values are plain base64, eg: sha-256=base64
+	for _, value := range strings.Split(header.Get("Digest"), ",") {
+		if algorithm, digest, ok := splitDigest(strings.Trim(value, " "), ""); ok {
+			return algorithm, digestAlgorithms[algorithm](), digest
+		}
+	}
+
+	if value := header.Get("Content-MD5"); value != "" {
+		if digest, err := base64.StdEncoding.DecodeString(value); err == nil {
+			return "md5", md5.New(), digest
+		}
+	}
+	return "", nil, nil
+}
+
+// splitDigest parses an algorithm=value pair, whose value is base64 enclosed by the delimiter
+func splitDigest(pair, delimiter string) (string, []byte, bool) {
+	i := strings.IndexByte(pair, '=')
+	if i < 0 {
+		return "", nil, false
+	}
+
+	algorithm := strings.ToLower(pair[:i])
+	if _, ok := digestAlgorithms[algorithm]; !ok {
+		return "", nil, false
+	}
+
+	value := pair[i+1:]
+	if delimiter != "" {
+		if len(value) < 2 || !strings.HasPrefix(value, delimiter) || !strings.HasSuffix(value, delimiter) {
+			return "", nil, false
+		}
+		value = value[1 : len(value)-1]
+	}
+
+	digest, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", nil, false
+	}
+	return algorithm, digest, true
+}
@@ -0,0 +1,84 @@
+package reqctl_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestVerifyBodyDigest(t *testing.T) {
+	body := `{"id": 7}`
+	sum := sha256.Sum256([]byte(body))
+	digest := base64.StdEncoding.EncodeToString(sum[:])
+
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt64(&calls, 1) {
+		case 1:
+			w.Header().Set("Content-Digest", "sha-256=:"+digest+":")
+			_, _ = w.Write([]byte(`{"id": 8}`))
+		case 2:
+			w.Header().Set("Digest", "unknown=abc, SHA-256="+digest)
+			_, _ = w.Write([]byte(body))
+		}
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	_, err := reqctl.Request(context.Background(), req).SetVerifyBody(true).DoResult().Bytes()
+	if !errors.Is(err, reqctl.ErrDigestMismatch) {
+		t.Errorf("Expected a digest mismatch, got %v", err)
+	}
+
+	data, err := reqctl.Request(context.Background(), req).SetVerifyBody(true).DoResult().String()
+	if err != nil || data != body {
+		t.Errorf("Expected the matching body to be read, got %q, %v", data, err)
+	}
+
+	atomic.StoreInt64(&calls, 0)
+	var v struct {
+		ID int `json:"id"`
+	}
+	err = reqctl.Request(context.Background(), req).SetVerifyBody(true).SetBodyRetry(1).DoResult().DecodeJSON(&v)
+	if err != nil || v.ID != 7 {
+		t.Errorf("Expected the mismatching body to be re-run, got %+v, %v", v, err)
+	}
+}
+
+func TestVerifyBodyLength(t *testing.T) {
+	truncate := func(next reqctl.Handler) reqctl.Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			if err == nil {
+				// Simulates a transport losing the end of the body
+				resp.Body = io.NopCloser(io.LimitReader(resp.Body, 4))
+			}
+			return resp, err
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", 16)))
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	_, err := reqctl.Request(context.Background(), req).Use(truncate).SetVerifyBody(true).DoResult().Bytes()
+	if !errors.Is(err, reqctl.ErrTruncatedBody) {
+		t.Errorf("Expected a truncated body, got %v", err)
+	}
+
+	data, err := reqctl.Request(context.Background(), req).Use(truncate).DoResult().Bytes()
+	if err != nil || len(data) != 4 {
+		t.Errorf("Expected the truncation to go unnoticed without verification, got %d bytes, %v", len(data), err)
+	}
+}
@@ -0,0 +1,166 @@
+package reqctl
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Headers of the webhooks, as per the Standard Webhooks specification
+const (
+	WebhookIDHeader        = "Webhook-Id"
+	WebhookTimestampHeader = "Webhook-Timestamp"
+	WebhookSignatureHeader = "Webhook-Signature"
+)
+
+// ErrInvalidSignature is returned when verifying a webhook whose signature or timestamp is invalid
+var ErrInvalidSignature = errors.New("reqctl: invalid webhook signature")
+
+// SignWebhook returns the signature of the webhook, ie: v1, followed by the base64 HMAC-SHA256 of its ID, timestamp &
+// body joined by dots
+func SignWebhook(secret []byte, id string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(id + "." + strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "v1," + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook verifies the signature of a received webhook against the secret, rejecting the webhooks whose
+// timestamp is off by more than tolerance, if positive, to prevent replays. The signature header may list several
+// signatures separated by spaces, eg: while rotating the secret, any of them matching.
+func VerifyWebhook(secret []byte, header http.Header, body []byte, tolerance time.Duration) error {
+	timestamp, err := strconv.ParseInt(header.Get(WebhookTimestampHeader), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 {
+		skew := time.Since(time.Unix(timestamp, 0))
+		if skew > tolerance || skew < -tolerance {
+			return ErrInvalidSignature
+		}
+	}
+
+	expected := SignWebhook(secret, header.Get(WebhookIDHeader), timestamp, body)
+	for _, signature := range strings.Fields(header.Get(WebhookSignatureHeader)) {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// WebhookReceipt is the record of an attempt of a webhook delivery
+type WebhookReceipt struct {
+	Attempt int
+	// Timestamp & Signature sent by the attempt
+	Timestamp int64
+	Signature string
+	// StatusCode of the response, 0 if no response was obtained
+	StatusCode int
+	Err        error
+	Start      time.Time
+	Duration   time.Duration
+}
+
+// WebhookDelivery is the outcome of a webhook
+type WebhookDelivery struct {
+	// ID of the webhook, sent by all its attempts so that the receivers can deduplicate them
+	ID string
+	// Delivered reports whether the webhook obtained a 2xx response
+	Delivered  bool
+	StatusCode int
+	// Receipts of the attempts ordered by their sequence number
+	Receipts []WebhookReceipt
+}
+
+// WebhookSender delivers webhooks with at-least-once semantics, signing every attempt afresh with the secret.
+// It is safe for concurrent use.
+type WebhookSender struct {
+	secret []byte
+	policy Policy
+}
+
+// NewWebhookSender creates a sender signing with the secret, retrying up to 5 times with an exponential backoff
+// from 1s until a 2xx response is obtained
+func NewWebhookSender(secret []byte) *WebhookSender {
+	return &WebhookSender{
+		secret: secret,
+		policy: NewPolicy().WithExponentialRetryWithChecker(time.Second, 5, webhookFailed),
+	}
+}
+
+// webhookFailed deems failed the webhook attempts not obtaining a 2xx response
+func webhookFailed(resp *http.Response, err error) bool {
+	return err != nil || resp.StatusCode/100 != 2
+}
+
+// SetPolicy sets the policy as per which the webhooks are sent. As webhooks are POSTs, their retries are allowed
+// regardless of the policy, yet its retry checker decides which outcomes are retried.
+func (s *WebhookSender) SetPolicy(policy Policy) *WebhookSender {
+	s.policy = policy
+	return s
+}
+
+// Send posts the JSON payload to the URL as a webhook of a new ID. Every attempt carries the ID, its own timestamp
+// & the signature over them & the payload, refer SignWebhook. The error is returned only if the webhook could not be
+// sent at all, the outcome of its attempts being reported by the delivery.
+func (s *WebhookSender) Send(ctx context.Context, url string, payload []byte) (WebhookDelivery, error) {
+	delivery := WebhookDelivery{ID: newUUID()}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return delivery, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, delivery.ID)
+
+	var mu sync.Mutex
+	signed := map[int]WebhookReceipt{}
+	c := s.policy.Request(ctx, req)
+	c.config.retryNonIdempotent = true
+	mutator := c.config.mutator
+	c.config.mutator = func(attempt int, req *http.Request) error {
+		if mutator != nil {
+			if err := mutator(attempt, req); err != nil {
+				return err
+			}
+		}
+
+		timestamp := time.Now().Unix()
+		signature := SignWebhook(s.secret, delivery.ID, timestamp, payload)
+		req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(WebhookSignatureHeader, signature)
+
+		mu.Lock()
+		defer mu.Unlock()
+		signed[attempt] = WebhookReceipt{Attempt: attempt, Timestamp: timestamp, Signature: signature}
+		return nil
+	}
+
+	res := c.DoResult()
+	closeBody(res.Response)
+	if res.Response != nil {
+		delivery.StatusCode = res.Response.StatusCode
+		delivery.Delivered = res.Err == nil && res.Response.StatusCode/100 == 2
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, rec := range res.records {
+		receipt := signed[rec.Seq]
+		receipt.Attempt, receipt.StatusCode, receipt.Err = rec.Seq, rec.StatusCode, rec.Err
+		receipt.Start, receipt.Duration = rec.Start, rec.Duration
+		delivery.Receipts = append(delivery.Receipts, receipt)
+	}
+	if len(delivery.Receipts) == 0 && res.Err != nil {
+		return delivery, res.Err
+	}
+	return delivery, nil
+}
@@ -0,0 +1,82 @@
+package reqctl_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestWebhookSender(t *testing.T) {
+	secret := []byte("secret")
+	var mu sync.Mutex
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := reqctl.VerifyWebhook(secret, r.Header, body, time.Minute); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if ids = append(ids, r.Header.Get(reqctl.WebhookIDHeader)); len(ids) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	sender := reqctl.NewWebhookSender(secret).
+		SetPolicy(reqctl.NewPolicy().WithSimpleRetryWithChecker(time.Millisecond, 2, reqctl.RetryOnStatus(500)))
+	delivery, err := sender.Send(context.Background(), server.URL, []byte(`{"event":"created"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !delivery.Delivered || delivery.StatusCode != http.StatusOK {
+		t.Errorf("Expected the webhook to be delivered, got %+v", delivery)
+	}
+	if len(ids) != 2 || ids[0] != delivery.ID || ids[1] != delivery.ID {
+		t.Errorf("Expected every attempt to carry the ID %s, got %v", delivery.ID, ids)
+	}
+	if len(delivery.Receipts) != 2 {
+		t.Fatalf("Expected a receipt per attempt, got %+v", delivery.Receipts)
+	}
+	first, second := delivery.Receipts[0], delivery.Receipts[1]
+	if first.StatusCode != http.StatusInternalServerError || second.StatusCode != http.StatusOK || second.Attempt != 2 {
+		t.Errorf("Expected the receipts to record the outcome of the attempts, got %+v", delivery.Receipts)
+	}
+	if first.Signature == "" || second.Timestamp == 0 {
+		t.Errorf("Expected the receipts to record the signatures, got %+v", delivery.Receipts)
+	}
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	secret := []byte("secret")
+	body := []byte("payload")
+	now := time.Now().Unix()
+
+	header := http.Header{}
+	header.Set(reqctl.WebhookIDHeader, "msg_1")
+	header.Set(reqctl.WebhookTimestampHeader, "1")
+	header.Set(reqctl.WebhookSignatureHeader, reqctl.SignWebhook(secret, "msg_1", 1, body))
+	if err := reqctl.VerifyWebhook(secret, header, body, time.Minute); !errors.Is(err, reqctl.ErrInvalidSignature) {
+		t.Errorf("Expected the stale webhook to be rejected, got %v", err)
+	}
+
+	header.Set(reqctl.WebhookTimestampHeader, strconv.FormatInt(now, 10))
+	header.Set(reqctl.WebhookSignatureHeader, "v1,invalid "+reqctl.SignWebhook(secret, "msg_1", now, body))
+	if err := reqctl.VerifyWebhook(secret, header, body, time.Minute); err != nil {
+		t.Errorf("Expected any of the signatures to match, got %v", err)
+	}
+	if err := reqctl.VerifyWebhook([]byte("other"), header, body, time.Minute); !errors.Is(err, reqctl.ErrInvalidSignature) {
+		t.Errorf("Expected the signature of another secret to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package reqctl
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// WeightedRetryCheckFunc returns the probability within [0, 1] of retrying an attempt, eg: 0.5 to retry half the 503s.
+// It lets operators dial the retry aggressiveness continuously during incidents.
+type WeightedRetryCheckFunc func(*http.Response, error) float64
+
+// SetSimpleRetryWithWeightedChecker configures simple retry with a probabilistic checker
+func (c Controller) SetSimpleRetryWithWeightedChecker(interval time.Duration, times int, checker WeightedRetryCheckFunc) Controller {
+	return c.setRetryWithWeightedChecker(simpleRetry, interval, times, checker)
+}
+
+// SetExponentialRetryWithWeightedChecker configures exponential retry with a probabilistic checker
+func (c Controller) SetExponentialRetryWithWeightedChecker(interval time.Duration, times int, checker WeightedRetryCheckFunc) Controller {
+	return c.setRetryWithWeightedChecker(exponentialRetry, interval, times, checker)
+}
+
+// setRetryWithWeightedChecker sets the retry configuration, where any attempt with a non zero retry probability
+// is considered failed, eg: by the circuit breaker, while only a fraction of them is retried.
+func (c Controller) setRetryWithWeightedChecker(rt retryType, interval time.Duration, times int, checker WeightedRetryCheckFunc) Controller {
+	c = c.setRetryWithChecker(rt, interval, times, func(resp *http.Response, err error) bool {
+		return checker(resp, err) > 0
+	})
+
+	c.config.retryCfg.RetryWeight = checker
+	return c
+}
+
+// shouldRetry decides whether the attempt outcome shall be retried
+func (c *Controller) shouldRetry(resp *http.Response, err error) bool {
+	retryCfg := c.config.retryCfg
+	if retryCfg.RetryWeight == nil {
+		return retryCfg.RetryCheckFunc(resp, err)
+	}
+
+	weight := retryCfg.RetryWeight(resp, err)
+	return weight >= 1 || (weight > 0 && rand.Float64() < weight)
+}
@@ -0,0 +1,50 @@
+package reqctl_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/RohanPoojary/reqctl"
+)
+
+func TestWeightedChecker(t *testing.T) {
+	calls := 0
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			return &http.Response{StatusCode: 503, Body: http.NoBody, Request: r}, nil
+		}),
+	}
+
+	request, _ := http.NewRequest("GET", "http://localhost", nil)
+	do := func(weight float64) int {
+		calls = 0
+		checker := func(resp *http.Response, err error) float64 {
+			return weight
+		}
+
+		_, _ = reqctl.Request(context.Background(), request).
+			SetSimpleRetryWithWeightedChecker(0, 100, checker).
+			SetClient(client).
+			Do()
+		return calls
+	}
+
+	if n := do(0); n != 1 {
+		t.Errorf("Expected no retries with weight 0, got %d calls", n)
+	}
+
+	if n := do(1); n != 101 {
+		t.Errorf("Expected every retry with weight 1, got %d calls", n)
+	}
+
+	// Retries stop at the first attempt failing the coin toss, hence a weight of 0.5 rarely exceeds 20 calls
+	total := 0
+	for i := 0; i < 20; i++ {
+		total += do(0.5)
+	}
+	if total < 20 || total > 100 {
+		t.Errorf("Expected about 40 calls over 20 requests with weight 0.5, got %d", total)
+	}
+}